@@ -23,6 +23,9 @@ import (
 	"github.com/programmerjide/ecommerce/internal/config"
 	"github.com/programmerjide/ecommerce/internal/database"
 	"github.com/programmerjide/ecommerce/internal/logger"
+	"github.com/programmerjide/ecommerce/internal/mailer"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
 )
 
 func main() {
@@ -33,6 +36,16 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to load configuration")
 	}
 
+	keys, err := utils.LoadKeySet(&cfg.JWT)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load JWT signing keys")
+	}
+
+	passwordPolicy, err := service.NewPasswordPolicy(&cfg.Password)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load password policy")
+	}
+
 	db, err := database.NewDatabase(&cfg.Database)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to connect to database")
@@ -52,10 +65,23 @@ func main() {
 
 	gin.SetMode(cfg.Server.GinMode)
 
-	srv := server.NewServer(cfg, db, &log)
+	templates, err := mailer.NewTemplates(cfg.Mail.DefaultLocale)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load email templates")
+	}
+	outbox := service.NewEmailOutbox(db, mailer.New(&cfg.Mail, log), &cfg.Mail, log)
+
+	srv := server.NewServer(cfg, db, keys, passwordPolicy, outbox, templates, &log)
 
 	router := srv.SetupRoutes()
 
+	// Scheduled sales are started and ended in the background until shutdown
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	go service.NewSaleScheduler(db, log).Run(schedulerCtx, cfg.Catalog.SaleCheckInterval)
+	// Emails that failed to send are retried in the background too
+	go outbox.Run(schedulerCtx, cfg.Mail.RetryInterval)
+
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%s", cfg.Server.Port),
 		Handler:      router,
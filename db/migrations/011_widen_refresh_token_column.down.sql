@@ -0,0 +1 @@
+ALTER TABLE refresh_tokens ALTER COLUMN token TYPE VARCHAR(500);
@@ -0,0 +1,2 @@
+-- Asymmetric signatures (RS256) produce tokens longer than 500 characters
+ALTER TABLE refresh_tokens ALTER COLUMN token TYPE TEXT;
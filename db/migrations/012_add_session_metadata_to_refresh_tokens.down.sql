@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_refresh_tokens_expires_at;
+
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS last_used_at,
+    DROP COLUMN IF EXISTS ip_address,
+    DROP COLUMN IF EXISTS user_agent;
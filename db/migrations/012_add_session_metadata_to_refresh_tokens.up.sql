@@ -0,0 +1,8 @@
+ALTER TABLE refresh_tokens
+    ADD COLUMN user_agent VARCHAR(512),
+    ADD COLUMN ip_address VARCHAR(45),
+    ADD COLUMN last_used_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE refresh_tokens SET last_used_at = created_at WHERE last_used_at IS NULL;
+
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
@@ -0,0 +1,13 @@
+CREATE TABLE login_throttles (
+    id SERIAL PRIMARY KEY,
+    scope VARCHAR(20) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP WITH TIME ZONE,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(scope, key)
+);
+
+CREATE INDEX idx_login_throttles_locked_until ON login_throttles(locked_until);
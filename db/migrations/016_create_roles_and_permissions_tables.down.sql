@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_users_role;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_role;
+
+-- Staff roles cannot be represented by the original enum
+UPDATE users SET role = 'customer' WHERE role NOT IN ('customer', 'admin');
+
+CREATE TYPE user_role AS ENUM ('customer', 'admin');
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE user_role USING role::user_role;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'customer';
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,62 @@
+CREATE TABLE roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) UNIQUE NOT NULL,
+    description TEXT,
+    is_system BOOLEAN DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE permissions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) UNIQUE NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE role_permissions (
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id INTEGER NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE INDEX idx_role_permissions_permission_id ON role_permissions(permission_id);
+
+INSERT INTO roles (name, description, is_system) VALUES
+    ('customer', 'Shopper with access to their own account only', true),
+    ('admin', 'Full access to every resource', true),
+    ('support', 'Customer support staff', true),
+    ('warehouse', 'Fulfilment staff', true);
+
+INSERT INTO permissions (name, description) VALUES
+    ('products:write', 'Create, update and delete products'),
+    ('categories:write', 'Create, update and delete categories'),
+    ('orders:read', 'View any customer order'),
+    ('orders:write', 'Update any customer order'),
+    ('orders:refund', 'Refund orders'),
+    ('orders:fulfil', 'Ship and fulfil orders'),
+    ('users:read', 'View customer accounts and sessions'),
+    ('users:write', 'Manage customer accounts and sessions'),
+    ('roles:manage', 'Manage roles, permissions and role assignments');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+    ON p.name IN ('users:read', 'orders:read', 'orders:refund')
+WHERE r.name = 'support';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+    ON p.name IN ('orders:read', 'orders:fulfil')
+WHERE r.name = 'warehouse';
+
+-- Roles are now rows instead of a fixed enum
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(50) USING role::text;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'customer';
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+DROP TYPE IF EXISTS user_role;
+
+CREATE INDEX idx_users_role ON users(role);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS impersonation_request_logs;
+DROP TABLE IF EXISTS impersonations;
+
+DROP INDEX IF EXISTS idx_refresh_tokens_impersonator_id;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS impersonator_id;
+
+DELETE FROM permissions WHERE name = 'users:impersonate';
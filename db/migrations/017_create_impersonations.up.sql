@@ -0,0 +1,41 @@
+INSERT INTO permissions (name, description) VALUES
+    ('users:impersonate', 'Sign in as a customer to see what they see');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+    ON p.name = 'users:impersonate'
+WHERE r.name IN ('admin', 'support');
+
+-- Sessions opened by staff on behalf of a customer
+ALTER TABLE refresh_tokens ADD COLUMN impersonator_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_refresh_tokens_impersonator_id ON refresh_tokens(impersonator_id);
+
+CREATE TABLE impersonations (
+    id SERIAL PRIMARY KEY,
+    actor_id INTEGER NOT NULL REFERENCES users(id),
+    subject_id INTEGER NOT NULL REFERENCES users(id),
+    session_id INTEGER NOT NULL REFERENCES refresh_tokens(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(512),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_impersonations_actor_id ON impersonations(actor_id);
+CREATE INDEX idx_impersonations_subject_id ON impersonations(subject_id);
+CREATE UNIQUE INDEX idx_impersonations_session_id ON impersonations(session_id);
+
+CREATE TABLE impersonation_request_logs (
+    id SERIAL PRIMARY KEY,
+    impersonation_id INTEGER NOT NULL REFERENCES impersonations(id) ON DELETE CASCADE,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    status_code INTEGER NOT NULL,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_impersonation_request_logs_impersonation_id ON impersonation_request_logs(impersonation_id);
@@ -0,0 +1,21 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS shipping_name,
+    DROP COLUMN IF EXISTS shipping_company,
+    DROP COLUMN IF EXISTS shipping_line1,
+    DROP COLUMN IF EXISTS shipping_line2,
+    DROP COLUMN IF EXISTS shipping_city,
+    DROP COLUMN IF EXISTS shipping_region,
+    DROP COLUMN IF EXISTS shipping_postal_code,
+    DROP COLUMN IF EXISTS shipping_country_code,
+    DROP COLUMN IF EXISTS shipping_phone,
+    DROP COLUMN IF EXISTS billing_name,
+    DROP COLUMN IF EXISTS billing_company,
+    DROP COLUMN IF EXISTS billing_line1,
+    DROP COLUMN IF EXISTS billing_line2,
+    DROP COLUMN IF EXISTS billing_city,
+    DROP COLUMN IF EXISTS billing_region,
+    DROP COLUMN IF EXISTS billing_postal_code,
+    DROP COLUMN IF EXISTS billing_country_code,
+    DROP COLUMN IF EXISTS billing_phone;
+
+DROP TABLE IF EXISTS addresses;
@@ -0,0 +1,50 @@
+CREATE TABLE addresses (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    label VARCHAR(50),
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    company VARCHAR(255),
+    line1 VARCHAR(255) NOT NULL,
+    line2 VARCHAR(255),
+    city VARCHAR(100) NOT NULL,
+    region VARCHAR(100),
+    postal_code VARCHAR(20),
+    country_code CHAR(2) NOT NULL,
+    phone VARCHAR(20),
+    is_default_shipping BOOLEAN DEFAULT false,
+    is_default_billing BOOLEAN DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_addresses_user_id ON addresses(user_id);
+CREATE INDEX idx_addresses_deleted_at ON addresses(deleted_at);
+
+-- At most one default shipping and one default billing address per user
+CREATE UNIQUE INDEX idx_addresses_default_shipping ON addresses(user_id)
+    WHERE is_default_shipping AND deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_addresses_default_billing ON addresses(user_id)
+    WHERE is_default_billing AND deleted_at IS NULL;
+
+-- Address snapshots copied onto orders at checkout
+ALTER TABLE orders
+    ADD COLUMN shipping_name VARCHAR(255),
+    ADD COLUMN shipping_company VARCHAR(255),
+    ADD COLUMN shipping_line1 VARCHAR(255),
+    ADD COLUMN shipping_line2 VARCHAR(255),
+    ADD COLUMN shipping_city VARCHAR(100),
+    ADD COLUMN shipping_region VARCHAR(100),
+    ADD COLUMN shipping_postal_code VARCHAR(20),
+    ADD COLUMN shipping_country_code CHAR(2),
+    ADD COLUMN shipping_phone VARCHAR(20),
+    ADD COLUMN billing_name VARCHAR(255),
+    ADD COLUMN billing_company VARCHAR(255),
+    ADD COLUMN billing_line1 VARCHAR(255),
+    ADD COLUMN billing_line2 VARCHAR(255),
+    ADD COLUMN billing_city VARCHAR(100),
+    ADD COLUMN billing_region VARCHAR(100),
+    ADD COLUMN billing_postal_code VARCHAR(20),
+    ADD COLUMN billing_country_code CHAR(2),
+    ADD COLUMN billing_phone VARCHAR(20);
@@ -0,0 +1,18 @@
+CREATE TABLE erasure_requests (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reason TEXT,
+    reviewer_id INTEGER REFERENCES users(id),
+    review_note TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_erasure_requests_user_id ON erasure_requests(user_id);
+CREATE INDEX idx_erasure_requests_status ON erasure_requests(status);
+
+-- A customer can only have one request under review at a time
+CREATE UNIQUE INDEX idx_erasure_requests_pending ON erasure_requests(user_id) WHERE status = 'pending';
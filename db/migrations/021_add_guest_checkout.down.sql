@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_orders_email;
+DROP INDEX IF EXISTS idx_orders_order_number;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS email,
+    DROP COLUMN IF EXISTS order_number;
+
+-- Guest carts and orders cannot be represented without a user
+DELETE FROM carts WHERE user_id IS NULL;
+DELETE FROM orders WHERE user_id IS NULL;
+
+ALTER TABLE carts ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN user_id SET NOT NULL;
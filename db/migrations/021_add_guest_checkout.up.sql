@@ -0,0 +1,18 @@
+-- Guests shop without an account, so carts and orders no longer require a user
+ALTER TABLE carts ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE orders ALTER COLUMN user_id DROP NOT NULL;
+
+-- Guests find their orders again by email and order number
+ALTER TABLE orders
+    ADD COLUMN order_number VARCHAR(20),
+    ADD COLUMN email VARCHAR(255);
+
+UPDATE orders SET order_number = 'ORD-' || LPAD(id::text, 10, '0');
+UPDATE orders SET email = LOWER(users.email) FROM users WHERE users.id = orders.user_id;
+
+ALTER TABLE orders
+    ALTER COLUMN order_number SET NOT NULL,
+    ALTER COLUMN email SET NOT NULL;
+
+CREATE UNIQUE INDEX idx_orders_order_number ON orders(order_number);
+CREATE INDEX idx_orders_email ON orders(email);
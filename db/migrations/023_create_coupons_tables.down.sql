@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS order_discounts;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS discount_total;
+ALTER TABLE carts DROP COLUMN IF EXISTS coupon_id;
+
+DROP TABLE IF EXISTS coupon_categories;
+DROP TABLE IF EXISTS coupon_products;
+DROP TABLE IF EXISTS coupons;
+
+DELETE FROM permissions WHERE name = 'promotions:manage';
@@ -0,0 +1,61 @@
+INSERT INTO permissions (name, description) VALUES
+    ('promotions:manage', 'Create and manage coupons and promotions');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+    ON p.name = 'promotions:manage'
+WHERE r.name = 'admin';
+
+CREATE TABLE coupons (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    description TEXT,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('percentage', 'fixed', 'free_shipping')),
+    value DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (value >= 0),
+    minimum_spend DECIMAL(10,2) NOT NULL DEFAULT 0,
+    max_uses INTEGER CHECK (max_uses > 0),
+    max_uses_per_user INTEGER CHECK (max_uses_per_user > 0),
+    used_count INTEGER NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP WITH TIME ZONE,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    -- Last line of defence for the global limit, which checkout enforces with a conditional update
+    CHECK (max_uses IS NULL OR used_count <= max_uses)
+);
+
+-- Codes can be reused once the coupon carrying them is deleted
+CREATE UNIQUE INDEX idx_coupons_code ON coupons(code) WHERE deleted_at IS NULL;
+CREATE INDEX idx_coupons_deleted_at ON coupons(deleted_at);
+
+CREATE TABLE coupon_products (
+    coupon_id INTEGER NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    PRIMARY KEY (coupon_id, product_id)
+);
+
+CREATE TABLE coupon_categories (
+    coupon_id INTEGER NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    PRIMARY KEY (coupon_id, category_id)
+);
+
+ALTER TABLE carts ADD COLUMN coupon_id INTEGER REFERENCES coupons(id) ON DELETE SET NULL;
+
+ALTER TABLE orders ADD COLUMN discount_total DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE order_discounts (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    coupon_id INTEGER REFERENCES coupons(id) ON DELETE SET NULL,
+    code VARCHAR(50),
+    type VARCHAR(20) NOT NULL,
+    description TEXT,
+    amount DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_order_discounts_order_id ON order_discounts(order_id);
+CREATE INDEX idx_order_discounts_coupon_id ON order_discounts(coupon_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_order_discounts_promotion_id;
+ALTER TABLE order_discounts DROP COLUMN IF EXISTS promotion_id;
+ALTER TABLE order_discounts DROP COLUMN IF EXISTS source;
+
+DROP TABLE IF EXISTS promotion_categories;
+DROP TABLE IF EXISTS promotion_products;
+DROP TABLE IF EXISTS promotion_tiers;
+DROP TABLE IF EXISTS promotions;
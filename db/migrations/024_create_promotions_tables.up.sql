@@ -0,0 +1,48 @@
+CREATE TABLE promotions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    type VARCHAR(30) NOT NULL CHECK (type IN ('buy_x_get_y', 'quantity_tier', 'category_percentage', 'bundle')),
+    priority INTEGER NOT NULL DEFAULT 0,
+    stackable BOOLEAN NOT NULL DEFAULT true,
+    is_active BOOLEAN DEFAULT true,
+    starts_at TIMESTAMP WITH TIME ZONE,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    buy_quantity INTEGER NOT NULL DEFAULT 0,
+    get_quantity INTEGER NOT NULL DEFAULT 0,
+    percentage DECIMAL(5,2) NOT NULL DEFAULT 0 CHECK (percentage >= 0 AND percentage <= 100),
+    bundle_price DECIMAL(10,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_promotions_deleted_at ON promotions(deleted_at);
+CREATE INDEX idx_promotions_active ON promotions(is_active, priority DESC) WHERE deleted_at IS NULL;
+
+CREATE TABLE promotion_tiers (
+    id SERIAL PRIMARY KEY,
+    promotion_id INTEGER NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    min_quantity INTEGER NOT NULL CHECK (min_quantity > 0),
+    percentage DECIMAL(5,2) NOT NULL CHECK (percentage > 0 AND percentage <= 100),
+    UNIQUE (promotion_id, min_quantity)
+);
+
+CREATE TABLE promotion_products (
+    promotion_id INTEGER NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    PRIMARY KEY (promotion_id, product_id)
+);
+
+CREATE TABLE promotion_categories (
+    promotion_id INTEGER NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    PRIMARY KEY (promotion_id, category_id)
+);
+
+-- Discount lines now also come from automatic promotions
+ALTER TABLE order_discounts
+    ADD COLUMN source VARCHAR(20) NOT NULL DEFAULT 'coupon' CHECK (source IN ('coupon', 'promotion')),
+    ADD COLUMN promotion_id INTEGER REFERENCES promotions(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_order_discounts_promotion_id ON order_discounts(promotion_id);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_products_sale_window;
+DROP INDEX IF EXISTS idx_products_is_on_sale;
+
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS chk_products_sale_window,
+    DROP COLUMN IF EXISTS sale_ends_at,
+    DROP COLUMN IF EXISTS sale_starts_at,
+    DROP COLUMN IF EXISTS sale_price,
+    DROP COLUMN IF EXISTS compare_at_price,
+    DROP COLUMN IF EXISTS rating,
+    DROP COLUMN IF EXISTS is_featured,
+    DROP COLUMN IF EXISTS is_on_sale;
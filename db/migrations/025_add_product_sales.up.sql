@@ -0,0 +1,15 @@
+-- is_on_sale, is_featured and rating were on the model without ever being migrated
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS is_on_sale BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS is_featured BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS rating DECIMAL(3,2) NOT NULL DEFAULT 0,
+    ADD COLUMN compare_at_price DECIMAL(10,2) CHECK (compare_at_price > 0),
+    ADD COLUMN sale_price DECIMAL(10,2) CHECK (sale_price > 0),
+    ADD COLUMN sale_starts_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN sale_ends_at TIMESTAMP WITH TIME ZONE,
+    ADD CONSTRAINT chk_products_sale_window
+        CHECK (sale_starts_at IS NULL OR sale_ends_at IS NULL OR sale_ends_at > sale_starts_at);
+
+CREATE INDEX idx_products_is_on_sale ON products(is_on_sale) WHERE deleted_at IS NULL;
+-- Lets the sale scheduler find products whose sale starts or ends
+CREATE INDEX idx_products_sale_window ON products(sale_starts_at, sale_ends_at) WHERE sale_price IS NOT NULL;
@@ -0,0 +1,21 @@
+CREATE TABLE product_price_histories (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    price DECIMAL(10,2) NOT NULL,
+    sale_price DECIMAL(10,2),
+    current_price DECIMAL(10,2) NOT NULL,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('created', 'updated', 'sale_started', 'sale_ended')),
+    changed_by_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_price_histories_product_id ON product_price_histories(product_id, created_at);
+CREATE INDEX idx_product_price_histories_created_at ON product_price_histories(created_at);
+
+-- Start every existing product's history with its pricing as of now
+INSERT INTO product_price_histories (product_id, price, sale_price, current_price, reason)
+SELECT id, price, sale_price,
+       CASE WHEN is_on_sale AND sale_price IS NOT NULL THEN sale_price ELSE price END,
+       'created'
+FROM products
+WHERE deleted_at IS NULL;
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS order_tax_lines;
+
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE orders DROP COLUMN IF EXISTS prices_include_tax;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_total;
+
+DROP TABLE IF EXISTS tax_rates;
+
+ALTER TABLE products DROP COLUMN IF EXISTS tax_class;
+
+DELETE FROM permissions WHERE name = 'tax:manage';
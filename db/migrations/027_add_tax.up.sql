@@ -0,0 +1,43 @@
+INSERT INTO permissions (name, description) VALUES
+    ('tax:manage', 'Create and manage tax rates');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+    ON p.name = 'tax:manage'
+WHERE r.name = 'admin';
+
+ALTER TABLE products ADD COLUMN tax_class VARCHAR(30) NOT NULL DEFAULT 'standard';
+
+CREATE TABLE tax_rates (
+    id SERIAL PRIMARY KEY,
+    country_code CHAR(2) NOT NULL,
+    -- Empty for a rate charged across the whole country
+    region VARCHAR(100) NOT NULL DEFAULT '',
+    tax_class VARCHAR(30) NOT NULL DEFAULT 'standard',
+    name VARCHAR(100) NOT NULL,
+    rate DECIMAL(7,4) NOT NULL CHECK (rate >= 0 AND rate <= 100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_tax_rates_unique ON tax_rates(country_code, LOWER(region), tax_class, name);
+CREATE INDEX idx_tax_rates_country ON tax_rates(country_code);
+
+ALTER TABLE orders ADD COLUMN tax_total DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN prices_include_tax BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE order_items ADD COLUMN tax_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE order_tax_lines (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    country_code CHAR(2) NOT NULL,
+    region VARCHAR(100) NOT NULL DEFAULT '',
+    rate DECIMAL(7,4) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_order_tax_lines_order_id ON order_tax_lines(order_id);
+CREATE INDEX idx_order_tax_lines_order_item_id ON order_tax_lines(order_item_id);
@@ -0,0 +1,14 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_cost;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_method_name;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_method_id;
+
+DROP TABLE IF EXISTS shipping_methods;
+DROP TABLE IF EXISTS shipping_zone_locations;
+DROP TABLE IF EXISTS shipping_zones;
+
+ALTER TABLE products DROP COLUMN IF EXISTS height;
+ALTER TABLE products DROP COLUMN IF EXISTS width;
+ALTER TABLE products DROP COLUMN IF EXISTS length;
+ALTER TABLE products DROP COLUMN IF EXISTS weight;
+
+DELETE FROM permissions WHERE name = 'shipping:manage';
@@ -0,0 +1,57 @@
+INSERT INTO permissions (name, description) VALUES
+    ('shipping:manage', 'Create and manage shipping zones and methods');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+    ON p.name = 'shipping:manage'
+WHERE r.name = 'admin';
+
+-- Weight in kg, dimensions in cm
+ALTER TABLE products ADD COLUMN weight DECIMAL(10,3) NOT NULL DEFAULT 0 CHECK (weight >= 0);
+ALTER TABLE products ADD COLUMN length DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (length >= 0);
+ALTER TABLE products ADD COLUMN width DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (width >= 0);
+ALTER TABLE products ADD COLUMN height DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (height >= 0);
+
+CREATE TABLE shipping_zones (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_shipping_zones_deleted_at ON shipping_zones(deleted_at);
+
+CREATE TABLE shipping_zone_locations (
+    id SERIAL PRIMARY KEY,
+    zone_id INTEGER NOT NULL REFERENCES shipping_zones(id) ON DELETE CASCADE,
+    country_code CHAR(2) NOT NULL,
+    -- Empty for the whole country
+    region VARCHAR(100) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_shipping_zone_locations_zone_id ON shipping_zone_locations(zone_id);
+CREATE INDEX idx_shipping_zone_locations_country ON shipping_zone_locations(country_code);
+
+CREATE TABLE shipping_methods (
+    id SERIAL PRIMARY KEY,
+    zone_id INTEGER NOT NULL REFERENCES shipping_zones(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('flat', 'weight_based', 'free_over_total')),
+    rate DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (rate >= 0),
+    rate_per_kg DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (rate_per_kg >= 0),
+    free_threshold DECIMAL(10,2) CHECK (free_threshold >= 0),
+    max_weight DECIMAL(10,3) CHECK (max_weight > 0),
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    CHECK (type <> 'free_over_total' OR free_threshold IS NOT NULL)
+);
+
+CREATE INDEX idx_shipping_methods_zone_id ON shipping_methods(zone_id);
+CREATE INDEX idx_shipping_methods_deleted_at ON shipping_methods(deleted_at);
+
+ALTER TABLE orders ADD COLUMN shipping_method_id INTEGER REFERENCES shipping_methods(id) ON DELETE SET NULL;
+ALTER TABLE orders ADD COLUMN shipping_method_name VARCHAR(100);
+ALTER TABLE orders ADD COLUMN shipping_cost DECIMAL(10,2) NOT NULL DEFAULT 0;
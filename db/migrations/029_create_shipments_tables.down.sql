@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS shipment_items;
+DROP TABLE IF EXISTS shipments;
+
+-- Enum values cannot be dropped; move orders back to a status that exists before this migration
+UPDATE orders SET status = 'shipped' WHERE status = 'partially_shipped';
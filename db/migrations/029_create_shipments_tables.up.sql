@@ -0,0 +1,28 @@
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'partially_shipped';
+
+CREATE TABLE shipments (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    carrier VARCHAR(50) NOT NULL,
+    tracking_number VARCHAR(100),
+    tracking_url TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'shipped' CHECK (status IN ('shipped', 'delivered')),
+    shipped_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_by_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_shipments_order_id ON shipments(order_id);
+CREATE INDEX idx_shipments_tracking_number ON shipments(tracking_number);
+
+CREATE TABLE shipment_items (
+    id SERIAL PRIMARY KEY,
+    shipment_id INTEGER NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX idx_shipment_items_shipment_id ON shipment_items(shipment_id);
+CREATE INDEX idx_shipment_items_order_item_id ON shipment_items(order_item_id);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS return_events;
+DROP TABLE IF EXISTS return_items;
+DROP TABLE IF EXISTS stock_movements;
+DROP TABLE IF EXISTS return_requests;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS refunded_total;
+
+-- Enum values cannot be dropped; move orders back to a status that exists before this migration
+UPDATE orders SET status = 'refunded' WHERE status = 'partially_refunded';
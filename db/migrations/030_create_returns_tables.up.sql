@@ -0,0 +1,65 @@
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'refunded';
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'partially_refunded';
+
+ALTER TABLE orders ADD COLUMN refunded_total DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE stock_movements (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity <> 0),
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('sale', 'return')),
+    order_id INTEGER REFERENCES orders(id) ON DELETE SET NULL,
+    return_request_id INTEGER,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_stock_movements_product_id ON stock_movements(product_id, created_at);
+CREATE INDEX idx_stock_movements_order_id ON stock_movements(order_id);
+
+CREATE TABLE return_requests (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'requested'
+        CHECK (status IN ('requested', 'approved', 'rejected', 'received', 'refunded')),
+    customer_note TEXT,
+    restocked BOOLEAN NOT NULL DEFAULT false,
+    refund_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    refunded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_return_requests_order_id ON return_requests(order_id);
+CREATE INDEX idx_return_requests_user_id ON return_requests(user_id);
+CREATE INDEX idx_return_requests_status ON return_requests(status);
+
+ALTER TABLE stock_movements ADD CONSTRAINT fk_stock_movements_return_request
+    FOREIGN KEY (return_request_id) REFERENCES return_requests(id) ON DELETE SET NULL;
+CREATE INDEX idx_stock_movements_return_request_id ON stock_movements(return_request_id);
+
+CREATE TABLE return_items (
+    id SERIAL PRIMARY KEY,
+    return_request_id INTEGER NOT NULL REFERENCES return_requests(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    reason VARCHAR(30) NOT NULL
+        CHECK (reason IN ('damaged', 'defective', 'wrong_item', 'not_as_described', 'no_longer_needed', 'other')),
+    note TEXT
+);
+
+CREATE INDEX idx_return_items_return_request_id ON return_items(return_request_id);
+CREATE INDEX idx_return_items_order_item_id ON return_items(order_item_id);
+
+-- Audit trail: every status change with who made it
+CREATE TABLE return_events (
+    id SERIAL PRIMARY KEY,
+    return_request_id INTEGER NOT NULL REFERENCES return_requests(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_return_events_return_request_id ON return_events(return_request_id);
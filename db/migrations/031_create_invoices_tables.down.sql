@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS invoices_immutable_trigger ON invoices;
+
+DROP FUNCTION IF EXISTS invoices_prevent_change();
+
+DROP TABLE IF EXISTS invoices;
+DROP TABLE IF EXISTS document_sequences;
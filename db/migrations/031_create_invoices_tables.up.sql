@@ -0,0 +1,37 @@
+CREATE TABLE document_sequences (
+    name VARCHAR(20) PRIMARY KEY,
+    last_value INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO document_sequences (name) VALUES ('invoice'), ('credit_note');
+
+-- Issued documents must be kept, so orders with invoices cannot be removed
+CREATE TABLE invoices (
+    id SERIAL PRIMARY KEY,
+    number VARCHAR(30) NOT NULL UNIQUE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('invoice', 'credit_note')),
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE RESTRICT,
+    return_request_id INTEGER UNIQUE REFERENCES return_requests(id) ON DELETE RESTRICT,
+    total DECIMAL(10,2) NOT NULL,
+    tax_total DECIMAL(10,2) NOT NULL DEFAULT 0,
+    storage_key TEXT NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    issued_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_invoices_order_id ON invoices(order_id);
+-- An order is invoiced once
+CREATE UNIQUE INDEX idx_invoices_order_invoice ON invoices(order_id) WHERE type = 'invoice';
+
+-- Issued invoices and credit notes are immutable
+CREATE OR REPLACE FUNCTION invoices_prevent_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'invoices are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER invoices_immutable_trigger
+    BEFORE UPDATE OR DELETE ON invoices
+    FOR EACH ROW
+EXECUTE FUNCTION invoices_prevent_change();
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS email_outbox;
+DROP TABLE IF EXISTS notification_preferences;
+
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
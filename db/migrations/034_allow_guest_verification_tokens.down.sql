@@ -0,0 +1,2 @@
+DELETE FROM verification_tokens WHERE user_id IS NULL;
+ALTER TABLE verification_tokens ALTER COLUMN user_id SET NOT NULL;
@@ -0,0 +1,2 @@
+-- Guest conversion tokens are issued before the account they create exists
+ALTER TABLE verification_tokens ALTER COLUMN user_id DROP NOT NULL;
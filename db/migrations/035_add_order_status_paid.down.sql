@@ -0,0 +1,2 @@
+-- Enum values cannot be dropped; move orders back to a status that exists before this migration
+UPDATE orders SET status = 'confirmed' WHERE status = 'paid';
@@ -0,0 +1,2 @@
+UPDATE orders SET status = 'partially_refunded'
+WHERE refunded_total > 0 AND status IN ('partially_shipped', 'shipped', 'delivered');
@@ -0,0 +1,12 @@
+-- Partial refunds are kept in refunded_total; give partially refunded orders back the
+-- fulfilment status they had so their remaining units can ship and be delivered
+UPDATE orders o SET status = CASE
+    WHEN EXISTS (
+        SELECT 1 FROM order_items oi
+        WHERE oi.order_id = o.id AND oi.deleted_at IS NULL
+          AND oi.quantity > (SELECT COALESCE(SUM(si.quantity), 0) FROM shipment_items si WHERE si.order_item_id = oi.id)
+    ) THEN 'partially_shipped'::order_status
+    WHEN EXISTS (SELECT 1 FROM shipments s WHERE s.order_id = o.id AND s.status <> 'delivered') THEN 'shipped'::order_status
+    ELSE 'delivered'::order_status
+END
+WHERE o.status = 'partially_refunded';
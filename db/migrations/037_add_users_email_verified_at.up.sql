@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE;
+
+-- Accounts already linked to a sign-in provider had their email verified by it
+UPDATE users SET email_verified_at = CURRENT_TIMESTAMP
+WHERE id IN (SELECT user_id FROM linked_identities);
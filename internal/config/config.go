@@ -4,6 +4,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -16,12 +18,23 @@ type Config struct {
 	JWT      JWTConfig
 	AWS      AWSConfig
 	Upload   UploadConfig
+	Login    LoginConfig
+	Password PasswordConfig
+	OIDC     OIDCConfig
+	Cart     CartConfig
+	Catalog  CatalogConfig
+	Tax      TaxConfig
+	Storage  StorageConfig
+	Invoice  InvoiceConfig
+	Mail     MailConfig
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
 	Port    string `default:"8080"`
 	GinMode string `default:"debug"`
+	// PublicURL is the storefront address used to build links in emails
+	PublicURL string `default:"http://localhost:3000"`
 }
 
 // DatabaseConfig holds database-related configuration
@@ -39,6 +52,17 @@ type JWTConfig struct {
 	Secret              string `default:"your_secret_key"`
 	ExpiresIn           time.Duration
 	RefreshTokenExpires time.Duration
+	// ImpersonationExpires is the lifetime of tokens issued to staff acting as a customer
+	ImpersonationExpires time.Duration
+	// Algorithm is the signing algorithm: HS256 (shared secret), RS256 or EdDSA
+	Algorithm string `default:"HS256"`
+	// SigningKeyID is the kid header written into newly issued tokens
+	SigningKeyID string
+	// PrivateKeyPath points to the PEM encoded private key used for RS256/EdDSA
+	PrivateKeyPath string
+	// VerificationKeys lists additional public keys still accepted during
+	// rotation, formatted as "kid=/path/to/key.pem,kid2=/path/to/other.pem"
+	VerificationKeys string
 }
 
 // AWSConfig holds AWS-related configuration
@@ -56,13 +80,138 @@ type UploadConfig struct {
 	MaxFileSize int64  `default:"1048576"`
 }
 
+// LoginConfig holds brute-force protection settings for login
+type LoginConfig struct {
+	// MaxAccountAttempts is the number of failed logins before an account is locked
+	MaxAccountAttempts int `default:"5"`
+	// MaxIPAttempts is the number of failed logins before an IP address is locked
+	MaxIPAttempts int `default:"20"`
+	// BackoffAfter is the number of failures allowed before back-off delays apply
+	BackoffAfter int `default:"3"`
+	// BackoffBase is the first back-off delay; it doubles with every further failure
+	BackoffBase time.Duration
+	// LockoutDuration is how long a locked account or IP address stays locked
+	LockoutDuration time.Duration
+	// AttemptWindow is how long failures are remembered after the last one
+	AttemptWindow time.Duration
+}
+
+// PasswordConfig holds the password policy
+type PasswordConfig struct {
+	MinLength        int  `default:"8"`
+	MaxLength        int  `default:"72"`
+	RequireUppercase bool `default:"true"`
+	RequireLowercase bool `default:"true"`
+	RequireDigit     bool `default:"true"`
+	RequireSymbol    bool `default:"false"`
+	// BreachedListPath points to a file of SHA-1 hashes of known breached passwords
+	BreachedListPath string
+	// ResetTokenExpires is how long a password reset link stays valid
+	ResetTokenExpires time.Duration
+	// EmailChangeExpires is how long links confirming an email address stay valid: a new
+	// address, or a guest's address when they create an account
+	EmailChangeExpires time.Duration
+}
+
+// OIDCConfig holds the external identity providers customers can sign in with
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+	// StateExpires is how long a started sign-in may take to complete
+	StateExpires time.Duration
+}
+
+// CartConfig holds shopping cart settings
+type CartConfig struct {
+	// GuestTokenExpires is how long an anonymous cart survives without being used
+	GuestTokenExpires time.Duration
+	// MergeStrategy decides the quantity when a guest cart merged at sign-in holds a
+	// product the user's cart already has: sum, max, keep_user or keep_guest
+	MergeStrategy string `default:"sum"`
+}
+
+// CatalogConfig holds product catalog settings
+type CatalogConfig struct {
+	// SaleCheckInterval is how often scheduled sales are started and ended
+	SaleCheckInterval time.Duration
+}
+
+// TaxConfig holds sales tax settings
+type TaxConfig struct {
+	// PricesIncludeTax means catalog prices are entered and shown with tax included
+	PricesIncludeTax bool `default:"false"`
+	// DefaultCountry is used to estimate tax before the customer has an address
+	DefaultCountry string
+	// DefaultRegion narrows DefaultCountry to a state or province
+	DefaultRegion string
+}
+
+// StorageConfig holds where generated files such as invoices are kept
+type StorageConfig struct {
+	Path string `default:"./storage"`
+}
+
+// InvoiceConfig holds the seller details printed on invoices and credit notes
+type InvoiceConfig struct {
+	SellerName string
+	// SellerAddress lines are given separated by "|"
+	SellerAddress []string
+	// SellerTaxID is the VAT or sales tax registration number
+	SellerTaxID string
+}
+
+// MailConfig holds how emails are delivered
+type MailConfig struct {
+	// Driver is smtp, file (each email saved as an .eml file) or log
+	Driver   string `default:"log"`
+	From     string
+	SMTPHost string
+	SMTPPort string `default:"587"`
+	// SMTPUsername enables authentication; leave empty for relays that need none
+	SMTPUsername string
+	SMTPPassword string
+	// DropPath is where the file driver saves emails
+	DropPath string `default:"./tmp/mail"`
+	// DefaultLocale is the language of emails to guests and users who have not chosen one
+	DefaultLocale string `default:"en"`
+	// MaxAttempts is how many times an email is tried before it is marked failed
+	MaxAttempts int `default:"5"`
+	// RetryInterval is how often emails that failed to send are retried
+	RetryInterval time.Duration
+}
+
+// Mail drivers
+const (
+	MailDriverSMTP = "smtp"
+	MailDriverFile = "file"
+	MailDriverLog  = "log"
+)
+
+// Cart merge strategies
+const (
+	CartMergeSum       = "sum"
+	CartMergeMax       = "max"
+	CartMergeKeepUser  = "keep_user"
+	CartMergeKeepGuest = "keep_guest"
+)
+
+// OIDCProviderConfig holds the client registration for one OpenID Connect provider
+type OIDCProviderConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
 // LoadConfig loads configuration from environment variables and .env file
 func LoadConfig() (*Config, error) {
 	_ = godotenv.Load()
 	cfg := &Config{
 		Server: ServerConfig{
-			Port:    getEnv("SERVER_PORT", "8080"),
-			GinMode: getEnv("GIN_MODE", "debug"),
+			Port:      getEnv("SERVER_PORT", "8080"),
+			GinMode:   getEnv("GIN_MODE", "debug"),
+			PublicURL: getEnv("APP_PUBLIC_URL", "http://localhost:3000"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -73,9 +222,14 @@ func LoadConfig() (*Config, error) {
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
 		JWT: JWTConfig{
-			Secret:              getEnv("JWT_SECRET", "your_secret_key"),
-			ExpiresIn:           time.Duration(getEnvAsInt("JWT_EXPIRES_IN", 15)) * time.Minute,
-			RefreshTokenExpires: time.Duration(getEnvAsInt("JWT_REFRESH_EXPIRES_IN", 7)) * 24 * time.Hour,
+			Secret:               getEnv("JWT_SECRET", "your_secret_key"),
+			ExpiresIn:            time.Duration(getEnvAsInt("JWT_EXPIRES_IN", 15)) * time.Minute,
+			RefreshTokenExpires:  time.Duration(getEnvAsInt("JWT_REFRESH_EXPIRES_IN", 7)) * 24 * time.Hour,
+			ImpersonationExpires: time.Duration(getEnvAsInt("JWT_IMPERSONATION_EXPIRES_IN", 15)) * time.Minute,
+			Algorithm:            getEnv("JWT_ALGORITHM", "HS256"),
+			SigningKeyID:         getEnv("JWT_SIGNING_KEY_ID", ""),
+			PrivateKeyPath:       getEnv("JWT_PRIVATE_KEY_PATH", ""),
+			VerificationKeys:     getEnv("JWT_VERIFICATION_KEYS", ""),
 		},
 		AWS: AWSConfig{
 			Region:          getEnv("AWS_REGION", "us-east-1"),
@@ -88,10 +242,109 @@ func LoadConfig() (*Config, error) {
 			Path:        getEnv("UPLOAD_PATH", "./uploads"),
 			MaxFileSize: int64(getEnvAsInt("UPLOAD_MAX_FILE_SIZE", 1048576)),
 		},
+		Login: LoginConfig{
+			MaxAccountAttempts: getEnvAsInt("LOGIN_MAX_ACCOUNT_ATTEMPTS", 5),
+			MaxIPAttempts:      getEnvAsInt("LOGIN_MAX_IP_ATTEMPTS", 20),
+			BackoffAfter:       getEnvAsInt("LOGIN_BACKOFF_AFTER", 3),
+			BackoffBase:        time.Duration(getEnvAsInt("LOGIN_BACKOFF_BASE_SECONDS", 1)) * time.Second,
+			LockoutDuration:    time.Duration(getEnvAsInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+			AttemptWindow:      time.Duration(getEnvAsInt("LOGIN_ATTEMPT_WINDOW_MINUTES", 15)) * time.Minute,
+		},
+		Password: PasswordConfig{
+			MinLength:          getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:          getEnvAsInt("PASSWORD_MAX_LENGTH", 72),
+			RequireUppercase:   getEnvAsBool("PASSWORD_REQUIRE_UPPERCASE", true),
+			RequireLowercase:   getEnvAsBool("PASSWORD_REQUIRE_LOWERCASE", true),
+			RequireDigit:       getEnvAsBool("PASSWORD_REQUIRE_DIGIT", true),
+			RequireSymbol:      getEnvAsBool("PASSWORD_REQUIRE_SYMBOL", false),
+			BreachedListPath:   getEnv("PASSWORD_BREACHED_LIST_PATH", ""),
+			ResetTokenExpires:  time.Duration(getEnvAsInt("PASSWORD_RESET_EXPIRES_IN", 60)) * time.Minute,
+			EmailChangeExpires: time.Duration(getEnvAsInt("EMAIL_CHANGE_EXPIRES_IN", 24)) * time.Hour,
+		},
+		OIDC: OIDCConfig{
+			Providers:    loadOIDCProviders(),
+			StateExpires: time.Duration(getEnvAsInt("OIDC_STATE_EXPIRES_IN", 10)) * time.Minute,
+		},
+		Cart: CartConfig{
+			GuestTokenExpires: time.Duration(getEnvAsInt("CART_TOKEN_EXPIRES_IN", 30)) * 24 * time.Hour,
+			MergeStrategy:     strings.ToLower(getEnv("CART_MERGE_STRATEGY", CartMergeSum)),
+		},
+		Catalog: CatalogConfig{
+			SaleCheckInterval: time.Duration(getEnvAsInt("SALE_CHECK_INTERVAL_SECONDS", 60)) * time.Second,
+		},
+		Tax: TaxConfig{
+			PricesIncludeTax: getEnvAsBool("TAX_PRICES_INCLUDE_TAX", false),
+			DefaultCountry:   strings.ToUpper(getEnv("TAX_DEFAULT_COUNTRY", "")),
+			DefaultRegion:    getEnv("TAX_DEFAULT_REGION", ""),
+		},
+		Storage: StorageConfig{
+			Path: getEnv("STORAGE_PATH", "./storage"),
+		},
+		Invoice: InvoiceConfig{
+			SellerName:    getEnv("INVOICE_SELLER_NAME", "E-commerce Store"),
+			SellerAddress: getEnvAsList("INVOICE_SELLER_ADDRESS", "|"),
+			SellerTaxID:   getEnv("INVOICE_SELLER_TAX_ID", ""),
+		},
+		Mail: MailConfig{
+			Driver:        strings.ToLower(getEnv("MAIL_DRIVER", MailDriverLog)),
+			From:          getEnv("FROM_EMAIL", "noreply@localhost"),
+			SMTPHost:      getEnv("SMTP_HOST", ""),
+			SMTPPort:      getEnv("SMTP_PORT", "587"),
+			SMTPUsername:  getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:  getEnv("SMTP_PASSWORD", ""),
+			DropPath:      getEnv("MAIL_DROP_PATH", "./tmp/mail"),
+			DefaultLocale: strings.ToLower(getEnv("MAIL_DEFAULT_LOCALE", "en")),
+			MaxAttempts:   getEnvAsInt("MAIL_MAX_ATTEMPTS", 5),
+			RetryInterval: time.Duration(getEnvAsInt("MAIL_RETRY_INTERVAL_SECONDS", 60)) * time.Second,
+		},
+	}
+
+	switch cfg.Cart.MergeStrategy {
+	case CartMergeSum, CartMergeMax, CartMergeKeepUser, CartMergeKeepGuest:
+	default:
+		return nil, fmt.Errorf("unsupported CART_MERGE_STRATEGY %q", cfg.Cart.MergeStrategy)
+	}
+	if cfg.Catalog.SaleCheckInterval <= 0 {
+		return nil, fmt.Errorf("SALE_CHECK_INTERVAL_SECONDS must be positive")
+	}
+	switch cfg.Mail.Driver {
+	case MailDriverSMTP:
+		if cfg.Mail.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required when MAIL_DRIVER is smtp")
+		}
+	case MailDriverFile, MailDriverLog:
+	default:
+		return nil, fmt.Errorf("unsupported MAIL_DRIVER %q", cfg.Mail.Driver)
+	}
+	if cfg.Mail.RetryInterval <= 0 || cfg.Mail.MaxAttempts < 1 {
+		return nil, fmt.Errorf("MAIL_RETRY_INTERVAL_SECONDS and MAIL_MAX_ATTEMPTS must be positive")
 	}
 	return cfg, nil
 }
 
+// loadOIDCProviders reads OIDC_PROVIDERS (e.g. "google,microsoft") and the
+// OIDC_<NAME>_* variables of each listed provider
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			IssuerURL:    getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", ""),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		})
+	}
+	return providers
+}
+
 func getEnvAsInt(s string, i int) int {
 	if value := os.Getenv(s); value != "" {
 		var intValue int
@@ -103,6 +356,26 @@ func getEnvAsInt(s string, i int) int {
 	return i
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList splits a variable into its non-empty, trimmed parts
+func getEnvAsList(key, sep string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), sep) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
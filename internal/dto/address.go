@@ -0,0 +1,52 @@
+package dto
+
+import "time"
+
+// AddressRequest is used to create and to replace an address
+type AddressRequest struct {
+	Label             string `json:"label" binding:"max=50"`
+	FirstName         string `json:"first_name" binding:"required,max=100"`
+	LastName          string `json:"last_name" binding:"required,max=100"`
+	Company           string `json:"company" binding:"max=255"`
+	Line1             string `json:"line1" binding:"required,max=255"`
+	Line2             string `json:"line2" binding:"max=255"`
+	City              string `json:"city" binding:"required,max=100"`
+	Region            string `json:"region" binding:"max=100"`
+	PostalCode        string `json:"postal_code" binding:"max=20"`
+	CountryCode       string `json:"country_code" binding:"required,iso3166_1_alpha2"`
+	Phone             string `json:"phone" binding:"max=20"`
+	IsDefaultShipping bool   `json:"is_default_shipping"`
+	IsDefaultBilling  bool   `json:"is_default_billing"`
+}
+
+type AddressResponse struct {
+	ID                uint      `json:"id"`
+	Label             string    `json:"label"`
+	FirstName         string    `json:"first_name"`
+	LastName          string    `json:"last_name"`
+	Company           string    `json:"company"`
+	Line1             string    `json:"line1"`
+	Line2             string    `json:"line2"`
+	City              string    `json:"city"`
+	Region            string    `json:"region"`
+	PostalCode        string    `json:"postal_code"`
+	CountryCode       string    `json:"country_code"`
+	Phone             string    `json:"phone"`
+	IsDefaultShipping bool      `json:"is_default_shipping"`
+	IsDefaultBilling  bool      `json:"is_default_billing"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// AddressSnapshotResponse is the copy of an address stored on an order
+type AddressSnapshotResponse struct {
+	Name        string `json:"name"`
+	Company     string `json:"company,omitempty"`
+	Line1       string `json:"line1"`
+	Line2       string `json:"line2,omitempty"`
+	City        string `json:"city"`
+	Region      string `json:"region,omitempty"`
+	PostalCode  string `json:"postal_code,omitempty"`
+	CountryCode string `json:"country_code"`
+	Phone       string `json:"phone,omitempty"`
+}
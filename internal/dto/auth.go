@@ -1,16 +1,31 @@
 package dto
 
+import "time"
+
 type RegisterRequest struct {
 	Email     string `json:"email" binding:"required,email"`
-	Password  string `json:"password" binding:"required,min=8"`
+	Password  string `json:"password" binding:"required"`
 	FirstName string `json:"first_name" binding:"required,min=2,max=32"`
 	LastName  string `json:"last_name" binding:"required,min=2,max=32"`
 	Phone     string `json:"phone"`
+	// CartToken is the guest cart to merge into the new account
+	CartToken string `json:"cart_token"`
 }
 
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	// CartToken is the guest cart to merge into the user's cart
+	CartToken string `json:"cart_token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
 }
 
 type RefreshTokenRequest struct {
@@ -21,6 +36,8 @@ type AuthResponse struct {
 	User         UserResponse `json:"user"`
 	AccessToken  string       `json:"access_token"`
 	RefreshToken string       `json:"refresh_token"`
+	// Cart is the user's cart after a guest cart was merged into it
+	Cart *CartResponse `json:"cart,omitempty"`
 }
 
 type UserResponse struct {
@@ -33,8 +50,119 @@ type UserResponse struct {
 	IsActive  bool   `json:"is_active"`
 }
 
+// AdminUserResponse is the staff view of an account, including soft-deleted ones
+type AdminUserResponse struct {
+	UserResponse
+	IsServiceAccount bool       `json:"is_service_account"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+}
+
+type ListUsersRequest struct {
+	Page        int        `form:"page"`
+	Limit       int        `form:"limit"`
+	Role        string     `form:"role"`
+	IsActive    *bool      `form:"is_active"`
+	Email       string     `form:"email"`
+	CreatedFrom *time.Time `form:"created_from" time_format:"2006-01-02"`
+	CreatedTo   *time.Time `form:"created_to" time_format:"2006-01-02"`
+	// Deleted selects soft-deleted accounts only ("only") or both ("include")
+	Deleted string `form:"deleted" binding:"omitempty,oneof=only include"`
+}
+
 type UpdateProfileRequest struct {
 	FirstName string `json:"first_name" binding:"omitempty,min=2,max=32"`
 	LastName  string `json:"last_name" binding:"omitempty,min=2,max=32"`
 	Phone     string `json:"phone" binding:"omitempty"`
 }
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" binding:"required,email"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ClientInfo describes the device a session was started from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+type SessionResponse struct {
+	ID           uint      `json:"id"`
+	UserAgent    string    `json:"user_agent"`
+	IPAddress    string    `json:"ip_address"`
+	Current      bool      `json:"current"`
+	Impersonated bool      `json:"impersonated"` // opened by staff on the user's behalf
+	CreatedAt    time.Time `json:"created_at"`
+	LastUsedAt   time.Time `json:"last_used_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// ImpersonationTokenResponse carries a short-lived access token; impersonation sessions cannot be refreshed
+type ImpersonationTokenResponse struct {
+	ImpersonationID uint         `json:"impersonation_id"`
+	AccessToken     string       `json:"access_token"`
+	ExpiresAt       time.Time    `json:"expires_at"`
+	User            UserResponse `json:"user"`
+}
+
+type ListImpersonationsRequest struct {
+	Page      int  `form:"page"`
+	Limit     int  `form:"limit"`
+	ActorID   uint `form:"actor_id"`
+	SubjectID uint `form:"subject_id"`
+}
+
+type ImpersonationResponse struct {
+	ID           uint                           `json:"id"`
+	ActorID      uint                           `json:"actor_id"`
+	ActorEmail   string                         `json:"actor_email"`
+	SubjectID    uint                           `json:"subject_id"`
+	SubjectEmail string                         `json:"subject_email"`
+	Reason       string                         `json:"reason"`
+	IPAddress    string                         `json:"ip_address"`
+	CreatedAt    time.Time                      `json:"created_at"`
+	ExpiresAt    time.Time                      `json:"expires_at"`
+	EndedAt      *time.Time                     `json:"ended_at,omitempty"`
+	Requests     []ImpersonationRequestResponse `json:"requests,omitempty"`
+}
+
+type ImpersonationRequestResponse struct {
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	StatusCode int       `json:"status_code"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expires_at"` // the sign-in must be completed by then
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `form:"code" binding:"required"`
+	State string `form:"state" binding:"required"`
+}
+
+type LinkedIdentityResponse struct {
+	ID        uint      `json:"id"`
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
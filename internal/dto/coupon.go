@@ -0,0 +1,67 @@
+package dto
+
+import "time"
+
+// CouponRequest is used to create and to replace a coupon
+type CouponRequest struct {
+	Code           string     `json:"code" binding:"required,max=50"`
+	Description    string     `json:"description" binding:"max=500"`
+	Type           string     `json:"type" binding:"required,oneof=percentage fixed free_shipping"`
+	Value          float64    `json:"value" binding:"min=0"`
+	MinimumSpend   float64    `json:"minimum_spend" binding:"min=0"`
+	MaxUses        *int       `json:"max_uses" binding:"omitempty,min=1"`
+	MaxUsesPerUser *int       `json:"max_uses_per_user" binding:"omitempty,min=1"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	IsActive       *bool      `json:"is_active"`
+	ProductIDs     []uint     `json:"product_ids"`
+	CategoryIDs    []uint     `json:"category_ids"`
+}
+
+type CouponResponse struct {
+	ID             uint       `json:"id"`
+	Code           string     `json:"code"`
+	Description    string     `json:"description"`
+	Type           string     `json:"type"`
+	Value          float64    `json:"value"`
+	MinimumSpend   float64    `json:"minimum_spend"`
+	MaxUses        *int       `json:"max_uses"`
+	MaxUsesPerUser *int       `json:"max_uses_per_user"`
+	UsedCount      int        `json:"used_count"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	IsActive       bool       `json:"is_active"`
+	ProductIDs     []uint     `json:"product_ids"`
+	CategoryIDs    []uint     `json:"category_ids"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type ListCouponsRequest struct {
+	Page     int    `form:"page"`
+	Limit    int    `form:"limit"`
+	Code     string `form:"code"`
+	IsActive *bool  `form:"is_active"`
+}
+
+type ApplyCouponRequest struct {
+	Code string `json:"code" binding:"required,max=50"`
+}
+
+// AppliedCouponResponse describes the coupon on a cart. Error explains why it currently
+// takes nothing off, for example after the cart dropped below the minimum spend.
+type AppliedCouponResponse struct {
+	Code        string  `json:"code"`
+	Type        string  `json:"type"`
+	Description string  `json:"description"`
+	Discount    float64 `json:"discount"`
+	Error       string  `json:"error,omitempty"`
+}
+
+type OrderDiscountResponse struct {
+	Source      string  `json:"source"`
+	Code        string  `json:"code"`
+	Type        string  `json:"type"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
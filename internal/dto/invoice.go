@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type ListInvoicesRequest struct {
+	Page    int    `form:"page"`
+	Limit   int    `form:"limit"`
+	Type    string `form:"type" binding:"omitempty,oneof=invoice credit_note"`
+	OrderID uint   `form:"order_id"`
+}
+
+// InvoiceResponse describes an issued invoice or credit note; the PDF is downloaded separately
+type InvoiceResponse struct {
+	ID          uint      `json:"id"`
+	Number      string    `json:"number"`
+	Type        string    `json:"type"`
+	OrderID     uint      `json:"order_id"`
+	OrderNumber string    `json:"order_number"`
+	ReturnID    *uint     `json:"return_id,omitempty"`
+	Total       float64   `json:"total"`
+	TaxTotal    float64   `json:"tax_total"`
+	Checksum    string    `json:"checksum"`
+	IssuedAt    time.Time `json:"issued_at"`
+}
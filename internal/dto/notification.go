@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// UpdateNotificationPreferencesRequest changes the email language and turns
+// notifications on or off. Omitted settings are left as they are.
+type UpdateNotificationPreferencesRequest struct {
+	Locale        *string         `json:"locale" binding:"omitempty,max=10"`
+	Notifications map[string]bool `json:"notifications"`
+}
+
+type NotificationPreferencesResponse struct {
+	Locale        string                           `json:"locale"` // empty means the shop's default
+	Locales       []string                         `json:"available_locales"`
+	Notifications []NotificationPreferenceResponse `json:"notifications"`
+}
+
+type NotificationPreferenceResponse struct {
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+	// Optional is false for notifications that are always sent
+	Optional bool `json:"optional"`
+}
+
+type ListOutboxEmailsRequest struct {
+	Page      int    `form:"page"`
+	Limit     int    `form:"limit"`
+	Status    string `form:"status" binding:"omitempty,oneof=pending sent failed"`
+	Recipient string `form:"recipient"`
+}
+
+type OutboxEmailResponse struct {
+	ID            uint       `json:"id"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
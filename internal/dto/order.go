@@ -13,11 +13,24 @@ type UpdateCartItemRequest struct {
 
 type CartResponse struct {
 	ID        uint               `json:"id"`
-	UserID    uint               `json:"user_id"`
+	UserID    *uint              `json:"user_id"`
 	CartItems []CartItemResponse `json:"cart_items"`
+	Subtotal  float64            `json:"subtotal"`
+	Discount  float64            `json:"discount"`
+	Tax       float64            `json:"tax"`
 	Total     float64            `json:"total"`
 	CreatedAt time.Time          `json:"created_at"`
 	UpdatedAt time.Time          `json:"updated_at"`
+	// TaxLines sums the estimated tax by rate. With PricesIncludeTax the tax is part of
+	// the prices and not added to the total.
+	TaxLines         []TaxLineResponse `json:"tax_lines"`
+	PricesIncludeTax bool              `json:"prices_include_tax"`
+	// Promotions lists the automatic promotions that fired, in the order they were applied
+	Promotions []AppliedPromotionResponse `json:"promotions"`
+	// Coupon is the coupon applied to the cart, if any
+	Coupon *AppliedCouponResponse `json:"coupon,omitempty"`
+	// CartToken is returned for guest carts and must be sent back in the X-Cart-Token header
+	CartToken string `json:"cart_token,omitempty"`
 }
 
 type CartItemResponse struct {
@@ -25,18 +38,74 @@ type CartItemResponse struct {
 	Product   ProductResponse `json:"product"`
 	Quantity  int             `json:"quantity"`
 	Subtotal  float64         `json:"subtotal"`
+	Tax       float64         `json:"tax"`
 	CreatedAt time.Time       `json:"created_at"`
 	UpdatedAt time.Time       `json:"updated_at"`
 }
 
+// CheckoutRequest places an order for the current cart. Signed-in users may pick saved
+// addresses by ID; guests must give an email and a shipping address.
+type CheckoutRequest struct {
+	Email             string          `json:"email" binding:"omitempty,email"`
+	ShippingAddressID uint            `json:"shipping_address_id"`
+	BillingAddressID  uint            `json:"billing_address_id"`
+	ShippingAddress   *AddressRequest `json:"shipping_address"`
+	BillingAddress    *AddressRequest `json:"billing_address"`
+	// ShippingMethodID is one of the methods quoted for the cart and shipping address
+	ShippingMethodID uint `json:"shipping_method_id"`
+}
+
+// OrderLookupRequest lets a guest find an order without signing in
+type OrderLookupRequest struct {
+	Email       string `json:"email" binding:"required,email"`
+	OrderNumber string `json:"order_number" binding:"required"`
+}
+
+// GuestConversionRequest asks for a link to turn a guest into a customer. The link is
+// sent to the email one of their orders was placed with.
+type GuestConversionRequest struct {
+	Email       string `json:"email" binding:"required,email"`
+	OrderNumber string `json:"order_number" binding:"required"`
+}
+
+// ConvertGuestRequest registers an account with the emailed link; the guest orders
+// placed with that email become the account's
+type ConvertGuestRequest struct {
+	Token     string `json:"token" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+	FirstName string `json:"first_name" binding:"required,min=2,max=32"`
+	LastName  string `json:"last_name" binding:"required,min=2,max=32"`
+	Phone     string `json:"phone"`
+}
+
 type OrderResponse struct {
-	ID          uint                `json:"id"`
-	UserID      uint                `json:"user_id"`
-	Status      string              `json:"status"`
-	TotalAmount float64             `json:"total_amount"`
-	OrderItems  []OrderItemResponse `json:"order_items"`
-	CreatedAt   time.Time           `json:"created_at"`
-	UpdatedAt   time.Time           `json:"updated_at"`
+	ID              uint                    `json:"id"`
+	UserID          *uint                   `json:"user_id"`
+	OrderNumber     string                  `json:"order_number"`
+	Email           string                  `json:"email"`
+	Status          string                  `json:"status"`
+	Subtotal        float64                 `json:"subtotal"`
+	DiscountTotal   float64                 `json:"discount_total"`
+	TotalAmount     float64                 `json:"total_amount"`
+	OrderItems      []OrderItemResponse     `json:"order_items"`
+	Discounts       []OrderDiscountResponse `json:"discounts"`
+	ShippingAddress AddressSnapshotResponse `json:"shipping_address"`
+	BillingAddress  AddressSnapshotResponse `json:"billing_address"`
+	CreatedAt       time.Time               `json:"created_at"`
+	UpdatedAt       time.Time               `json:"updated_at"`
+	// TaxLines sums the tax by rate. With PricesIncludeTax the tax is part of the item
+	// prices; otherwise TaxTotal was added to TotalAmount.
+	TaxTotal         float64           `json:"tax_total"`
+	TaxLines         []TaxLineResponse `json:"tax_lines"`
+	PricesIncludeTax bool              `json:"prices_include_tax"`
+	// ShippingCost is included in TotalAmount; a free shipping coupon offsets it with a discount
+	ShippingMethodID *uint   `json:"shipping_method_id"`
+	ShippingMethod   string  `json:"shipping_method"`
+	ShippingCost     float64 `json:"shipping_cost"`
+	// Shipments lists the parcels sent so far, oldest first
+	Shipments []ShipmentResponse `json:"shipments"`
+	// RefundedTotal is what has been paid back for returned items
+	RefundedTotal float64 `json:"refunded_total"`
 }
 
 type OrderItemResponse struct {
@@ -44,5 +113,7 @@ type OrderItemResponse struct {
 	Product   ProductResponse `json:"product"`
 	Quantity  int             `json:"quantity"`
 	Price     float64         `json:"price"`
+	Tax       float64         `json:"tax"`
+	Shipped   int             `json:"shipped_quantity"`
 	CreatedAt time.Time       `json:"created_at"`
 }
@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+// UserDataExport is everything we hold about a customer, returned for data-subject access requests
+type UserDataExport struct {
+	GeneratedAt      time.Time                `json:"generated_at"`
+	Profile          AdminUserResponse        `json:"profile"`
+	Addresses        []AddressResponse        `json:"addresses"`
+	Orders           []OrderResponse          `json:"orders"`
+	Cart             *CartResponse            `json:"cart"`
+	Wishlists        []WishlistResponse       `json:"wishlists"`
+	Sessions         []SessionResponse        `json:"sessions"`
+	LinkedIdentities []LinkedIdentityResponse `json:"linked_identities"`
+	ErasureRequests  []ErasureRequestResponse `json:"erasure_requests"`
+}
+
+type CreateErasureRequest struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+type ReviewErasureRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+type ListErasureRequestsRequest struct {
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+	Status string `form:"status" binding:"omitempty,oneof=pending rejected cancelled completed"`
+}
+
+type ErasureRequestResponse struct {
+	ID          uint       `json:"id"`
+	UserID      uint       `json:"user_id"`
+	UserEmail   string     `json:"user_email,omitempty"`
+	Status      string     `json:"status"`
+	Reason      string     `json:"reason"`
+	ReviewerID  *uint      `json:"reviewer_id,omitempty"`
+	ReviewNote  string     `json:"review_note,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -29,6 +29,10 @@ type CreateProductRequest struct {
 	Price       float64 `json:"price" binding:"required,gt=0"`
 	Stock       int     `json:"stock" binding:"min=0"`
 	SKU         string  `json:"sku" binding:"required"`
+	TaxClass    string  `json:"tax_class" binding:"max=30"` // defaults to standard
+	// Optional sale, see ProductSale
+	ProductSale
+	ProductShipping
 }
 
 type UpdateProductRequest struct {
@@ -38,21 +42,54 @@ type UpdateProductRequest struct {
 	Price       float64 `json:"price" binding:"required,gt=0"`
 	Stock       int     `json:"stock" binding:"min=0"`
 	IsActive    *bool   `json:"is_active"`
+	TaxClass    string  `json:"tax_class" binding:"max=30"` // unchanged when empty
+	// Optional sale, see ProductSale
+	ProductSale
+	ProductShipping
+}
+
+// ProductSale schedules a sale price. Without sale_price the product is not on sale; open
+// start or end times make the sale start now or run until it is changed.
+type ProductSale struct {
+	CompareAtPrice *float64   `json:"compare_at_price" binding:"omitempty,gt=0"`
+	SalePrice      *float64   `json:"sale_price" binding:"omitempty,gt=0"`
+	SaleStartsAt   *time.Time `json:"sale_starts_at"`
+	SaleEndsAt     *time.Time `json:"sale_ends_at"`
+}
+
+// ProductShipping holds the shipping weight in kg and package dimensions in cm
+type ProductShipping struct {
+	Weight float64 `json:"weight" binding:"min=0"`
+	Length float64 `json:"length" binding:"min=0"`
+	Width  float64 `json:"width" binding:"min=0"`
+	Height float64 `json:"height" binding:"min=0"`
 }
 
 type ProductResponse struct {
-	ID          uint                   `json:"id"`
-	CategoryID  uint                   `json:"category_id"`
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
-	Price       float64                `json:"price"`
-	Stock       int                    `json:"stock"`
-	SKU         string                 `json:"sku"`
-	IsActive    bool                   `json:"is_active"`
-	Category    CategoryResponse       `json:"category"`
-	Images      []ProductImageResponse `json:"images"`
-	CreatedAt   time.Time              `json:"created_at"`
-	UpdatedAt   time.Time              `json:"updated_at"`
+	ID                uint                   `json:"id"`
+	CategoryID        uint                   `json:"category_id"`
+	Name              string                 `json:"name"`
+	Description       string                 `json:"description"`
+	Price             float64                `json:"price"`
+	Stock             int                    `json:"stock"`
+	SKU               string                 `json:"sku"`
+	IsActive          bool                   `json:"is_active"`
+	TaxClass          string                 `json:"tax_class"`
+	CurrentPrice      float64                `json:"current_price"` // sale price while on sale, otherwise price
+	IsOnSale          bool                   `json:"is_on_sale"`
+	CompareAtPrice    *float64               `json:"compare_at_price"`
+	SalePrice         *float64               `json:"sale_price"`
+	SaleStartsAt      *time.Time             `json:"sale_starts_at"`
+	SaleEndsAt        *time.Time             `json:"sale_ends_at"`
+	LowestPrice30Days *float64               `json:"lowest_price_30_days,omitempty"` // lowest price customers paid over the last 30 days
+	Weight            float64                `json:"weight"`
+	Length            float64                `json:"length"`
+	Width             float64                `json:"width"`
+	Height            float64                `json:"height"`
+	Category          CategoryResponse       `json:"category"`
+	Images            []ProductImageResponse `json:"images"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
 }
 
 type ProductImageResponse struct {
@@ -70,9 +107,29 @@ type SearchProductsRequest struct {
 	CategoryID *uint    `form:"category_id"`
 	MinPrice   *float64 `form:"min_price"`
 	MaxPrice   *float64 `form:"max_price"`
+	OnSale     *bool    `form:"on_sale"`
+}
+
+// ListProductsRequest filters the product listing
+type ListProductsRequest struct {
+	Page   int   `form:"page"`
+	Limit  int   `form:"limit"`
+	OnSale *bool `form:"on_sale"`
 }
 
 type ProductSearchResult struct {
 	ProductResponse
 	Rank float32 `json:"rank"`
 }
+
+// PriceHistoryResponse is one change in a product's pricing
+type PriceHistoryResponse struct {
+	ID             uint      `json:"id"`
+	Price          float64   `json:"price"`
+	SalePrice      *float64  `json:"sale_price"`
+	CurrentPrice   float64   `json:"current_price"`
+	Reason         string    `json:"reason"`
+	ChangedByID    *uint     `json:"changed_by_id"`
+	ChangedByEmail string    `json:"changed_by_email,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,69 @@
+package dto
+
+import "time"
+
+// PromotionRequest is used to create and to replace an automatic promotion
+type PromotionRequest struct {
+	Name        string                 `json:"name" binding:"required,max=100"`
+	Description string                 `json:"description" binding:"max=500"`
+	Type        string                 `json:"type" binding:"required,oneof=buy_x_get_y quantity_tier category_percentage bundle"`
+	Priority    int                    `json:"priority"`
+	Stackable   *bool                  `json:"stackable"`
+	IsActive    *bool                  `json:"is_active"`
+	StartsAt    *time.Time             `json:"starts_at"`
+	EndsAt      *time.Time             `json:"ends_at"`
+	BuyQuantity int                    `json:"buy_quantity" binding:"min=0"`
+	GetQuantity int                    `json:"get_quantity" binding:"min=0"`
+	Percentage  float64                `json:"percentage" binding:"min=0,max=100"`
+	BundlePrice float64                `json:"bundle_price" binding:"min=0"`
+	Tiers       []PromotionTierRequest `json:"tiers" binding:"dive"`
+	ProductIDs  []uint                 `json:"product_ids"`
+	CategoryIDs []uint                 `json:"category_ids"`
+}
+
+type PromotionTierRequest struct {
+	MinQuantity int     `json:"min_quantity" binding:"required,min=1"`
+	Percentage  float64 `json:"percentage" binding:"required,gt=0,max=100"`
+}
+
+type PromotionResponse struct {
+	ID          uint                    `json:"id"`
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Type        string                  `json:"type"`
+	Priority    int                     `json:"priority"`
+	Stackable   bool                    `json:"stackable"`
+	IsActive    bool                    `json:"is_active"`
+	StartsAt    *time.Time              `json:"starts_at"`
+	EndsAt      *time.Time              `json:"ends_at"`
+	BuyQuantity int                     `json:"buy_quantity"`
+	GetQuantity int                     `json:"get_quantity"`
+	Percentage  float64                 `json:"percentage"`
+	BundlePrice float64                 `json:"bundle_price"`
+	Tiers       []PromotionTierResponse `json:"tiers"`
+	ProductIDs  []uint                  `json:"product_ids"`
+	CategoryIDs []uint                  `json:"category_ids"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+}
+
+type PromotionTierResponse struct {
+	MinQuantity int     `json:"min_quantity"`
+	Percentage  float64 `json:"percentage"`
+}
+
+type ListPromotionsRequest struct {
+	Page     int    `form:"page"`
+	Limit    int    `form:"limit"`
+	Type     string `form:"type"`
+	IsActive *bool  `form:"is_active"`
+}
+
+// AppliedPromotionResponse explains a promotion that fired on a cart
+type AppliedPromotionResponse struct {
+	ID          uint    `json:"id"`
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Amount      float64 `json:"amount"`
+	Explanation string  `json:"explanation"`
+}
@@ -0,0 +1,68 @@
+package dto
+
+import "time"
+
+// CreateReturnRequest asks to send back some of the shipped items of an order
+type CreateReturnRequest struct {
+	Items []ReturnItemRequest `json:"items" binding:"required,min=1,dive"`
+	Note  string              `json:"note" binding:"max=1000"`
+}
+
+type ReturnItemRequest struct {
+	OrderItemID uint   `json:"order_item_id" binding:"required"`
+	Quantity    int    `json:"quantity" binding:"required,min=1"`
+	Reason      string `json:"reason" binding:"required,oneof=damaged defective wrong_item not_as_described no_longer_needed other"`
+	Note        string `json:"note" binding:"max=500"`
+}
+
+// ReturnActionRequest moves a return along; the note is kept in its audit trail
+type ReturnActionRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// ReceiveReturnRequest records that the goods came back. Restock puts the units back on sale.
+type ReceiveReturnRequest struct {
+	Restock bool   `json:"restock"`
+	Note    string `json:"note" binding:"max=1000"`
+}
+
+type ListReturnsRequest struct {
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+	Status string `form:"status"`
+}
+
+// ReturnResponse describes a return. RefundAmount is the estimated refund until the
+// return is refunded, then the amount paid back.
+type ReturnResponse struct {
+	ID           uint                  `json:"id"`
+	OrderID      uint                  `json:"order_id"`
+	OrderNumber  string                `json:"order_number"`
+	Status       string                `json:"status"`
+	CustomerNote string                `json:"customer_note"`
+	Restocked    bool                  `json:"restocked"`
+	RefundAmount float64               `json:"refund_amount"`
+	RefundedAt   *time.Time            `json:"refunded_at"`
+	Items        []ReturnItemResponse  `json:"items"`
+	Events       []ReturnEventResponse `json:"events"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+}
+
+type ReturnItemResponse struct {
+	OrderItemID uint    `json:"order_item_id"`
+	ProductID   uint    `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Quantity    int     `json:"quantity"`
+	Reason      string  `json:"reason"`
+	Note        string  `json:"note"`
+	UnitRefund  float64 `json:"unit_refund"`
+}
+
+// ReturnEventResponse is an entry of a return's audit trail
+type ReturnEventResponse struct {
+	Status    string    `json:"status"`
+	ActorID   *uint     `json:"actor_id"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
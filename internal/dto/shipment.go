@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+// CreateShipmentRequest records a parcel sent for an order. Without items it ships
+// everything not shipped yet.
+type CreateShipmentRequest struct {
+	Carrier        string                `json:"carrier" binding:"required,max=50"`
+	TrackingNumber string                `json:"tracking_number" binding:"max=100"`
+	TrackingURL    string                `json:"tracking_url" binding:"omitempty,url"`
+	Items          []ShipmentItemRequest `json:"items" binding:"dive"`
+}
+
+type ShipmentItemRequest struct {
+	OrderItemID uint `json:"order_item_id" binding:"required"`
+	Quantity    int  `json:"quantity" binding:"required,min=1"`
+}
+
+// UpdateShipmentRequest corrects the carrier details of a shipment
+type UpdateShipmentRequest struct {
+	Carrier        string `json:"carrier" binding:"required,max=50"`
+	TrackingNumber string `json:"tracking_number" binding:"max=100"`
+	TrackingURL    string `json:"tracking_url" binding:"omitempty,url"`
+}
+
+type ShipmentResponse struct {
+	ID             uint                   `json:"id"`
+	OrderID        uint                   `json:"order_id"`
+	Carrier        string                 `json:"carrier"`
+	TrackingNumber string                 `json:"tracking_number"`
+	TrackingURL    string                 `json:"tracking_url"`
+	Status         string                 `json:"status"`
+	ShippedAt      time.Time              `json:"shipped_at"`
+	DeliveredAt    *time.Time             `json:"delivered_at"`
+	Items          []ShipmentItemResponse `json:"items"`
+}
+
+type ShipmentItemResponse struct {
+	OrderItemID uint   `json:"order_item_id"`
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+}
+
+// OrderTrackingResponse shows customers where each part of their order is
+type OrderTrackingResponse struct {
+	OrderNumber string                 `json:"order_number"`
+	Status      string                 `json:"status"`
+	Shipments   []ShipmentResponse     `json:"shipments"`
+	Items       []TrackingItemResponse `json:"items"`
+}
+
+// TrackingItemResponse is an ordered item and how much of it has shipped
+type TrackingItemResponse struct {
+	OrderItemID     uint   `json:"order_item_id"`
+	ProductID       uint   `json:"product_id"`
+	ProductName     string `json:"product_name"`
+	Quantity        int    `json:"quantity"`
+	ShippedQuantity int    `json:"shipped_quantity"`
+}
@@ -0,0 +1,76 @@
+package dto
+
+import "time"
+
+// ShippingZoneRequest is used to create and to replace a shipping zone
+type ShippingZoneRequest struct {
+	Name      string                        `json:"name" binding:"required,max=100"`
+	Locations []ShippingZoneLocationRequest `json:"locations" binding:"required,min=1,dive"`
+}
+
+// ShippingZoneLocationRequest adds a country, or one region of it, to a zone
+type ShippingZoneLocationRequest struct {
+	CountryCode string `json:"country_code" binding:"required,len=2"`
+	Region      string `json:"region" binding:"max=100"`
+}
+
+type ShippingZoneResponse struct {
+	ID        uint                           `json:"id"`
+	Name      string                         `json:"name"`
+	Locations []ShippingZoneLocationResponse `json:"locations"`
+	Methods   []ShippingMethodResponse       `json:"methods"`
+	CreatedAt time.Time                      `json:"created_at"`
+	UpdatedAt time.Time                      `json:"updated_at"`
+}
+
+type ShippingZoneLocationResponse struct {
+	CountryCode string `json:"country_code"`
+	Region      string `json:"region"`
+}
+
+// ShippingMethodRequest is used to create and to replace a shipping method. Weight-based
+// methods charge rate plus rate_per_kg; free_over_total methods charge rate until the
+// order reaches free_threshold.
+type ShippingMethodRequest struct {
+	ZoneID        uint     `json:"zone_id" binding:"required"`
+	Name          string   `json:"name" binding:"required,max=100"`
+	Type          string   `json:"type" binding:"required,oneof=flat weight_based free_over_total"`
+	Rate          float64  `json:"rate" binding:"min=0"`
+	RatePerKg     float64  `json:"rate_per_kg" binding:"min=0"`
+	FreeThreshold *float64 `json:"free_threshold" binding:"omitempty,min=0"`
+	MaxWeight     *float64 `json:"max_weight" binding:"omitempty,gt=0"`
+	IsActive      *bool    `json:"is_active"`
+}
+
+type ShippingMethodResponse struct {
+	ID            uint      `json:"id"`
+	ZoneID        uint      `json:"zone_id"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	Rate          float64   `json:"rate"`
+	RatePerKg     float64   `json:"rate_per_kg"`
+	FreeThreshold *float64  `json:"free_threshold"`
+	MaxWeight     *float64  `json:"max_weight"`
+	IsActive      bool      `json:"is_active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ShippingRatesRequest picks the address to quote for: a saved address, a country and
+// optional region, or else the signed-in user's default shipping address
+type ShippingRatesRequest struct {
+	AddressID   uint   `form:"address_id"`
+	CountryCode string `form:"country_code" binding:"omitempty,len=2"`
+	Region      string `form:"region"`
+}
+
+// ShippingRateResponse is what a shipping method would charge for the cart. Waived is
+// set when a free shipping coupon on the cart brings the cost down to zero.
+type ShippingRateResponse struct {
+	MethodID uint    `json:"method_id"`
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Zone     string  `json:"zone"`
+	Cost     float64 `json:"cost"`
+	Waived   bool    `json:"waived"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+import "time"
+
+// TaxRateRequest is used to create and to replace a tax rate. An empty region applies
+// the rate across the whole country.
+type TaxRateRequest struct {
+	CountryCode string  `json:"country_code" binding:"required,len=2"`
+	Region      string  `json:"region" binding:"max=100"`
+	TaxClass    string  `json:"tax_class" binding:"max=30"`
+	Name        string  `json:"name" binding:"required,max=100"`
+	Rate        float64 `json:"rate" binding:"min=0,max=100"`
+}
+
+type TaxRateResponse struct {
+	ID          uint      `json:"id"`
+	CountryCode string    `json:"country_code"`
+	Region      string    `json:"region"`
+	TaxClass    string    `json:"tax_class"`
+	Name        string    `json:"name"`
+	Rate        float64   `json:"rate"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ListTaxRatesRequest struct {
+	Page        int    `form:"page"`
+	Limit       int    `form:"limit"`
+	CountryCode string `form:"country_code"`
+	TaxClass    string `form:"tax_class"`
+}
+
+// TaxLineResponse is tax charged at one rate
+type TaxLineResponse struct {
+	Name        string  `json:"name"`
+	CountryCode string  `json:"country_code"`
+	Region      string  `json:"region"`
+	Rate        float64 `json:"rate"`
+	Amount      float64 `json:"amount"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import "time"
+
+type WishlistRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type AddWishlistItemRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+}
+
+// MoveToCartRequest moves a wishlist item into the cart; the quantity defaults to one
+type MoveToCartRequest struct {
+	Quantity int `json:"quantity" binding:"omitempty,min=1"`
+}
+
+type WishlistResponse struct {
+	ID        uint                   `json:"id"`
+	Name      string                 `json:"name"`
+	Shared    bool                   `json:"shared"`
+	ShareURL  string                 `json:"share_url,omitempty"`
+	Items     []WishlistItemResponse `json:"items"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+type WishlistItemResponse struct {
+	ID      uint            `json:"id"`
+	Product ProductResponse `json:"product"`
+	InStock bool            `json:"in_stock"`
+	AddedAt time.Time       `json:"added_at"`
+}
+
+// SharedWishlistResponse is the public view of a shared wishlist
+type SharedWishlistResponse struct {
+	Name      string                 `json:"name"`
+	OwnerName string                 `json:"owner_name"`
+	Items     []WishlistItemResponse `json:"items"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type AddressHandler struct {
+	addressService *service.AddressService
+	logger         zerolog.Logger
+}
+
+func NewAddressHandler(addressService *service.AddressService, logger zerolog.Logger) *AddressHandler {
+	return &AddressHandler{
+		addressService: addressService,
+		logger:         logger,
+	}
+}
+
+func (h *AddressHandler) ListAddresses(c *gin.Context) {
+	addresses, err := h.addressService.ListAddresses(c.GetUint("user_id"))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list addresses")
+		utils.InternalServerErrorResponse(c, "Failed to list addresses", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Addresses retrieved successfully", addresses)
+}
+
+func (h *AddressHandler) GetAddress(c *gin.Context) {
+	addressID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid address ID", err)
+		return
+	}
+
+	address, err := h.addressService.GetAddress(c.GetUint("user_id"), uint(addressID))
+	if err != nil {
+		utils.NotFoundResponse(c, "Address not found")
+		return
+	}
+
+	utils.SuccessResponse(c, "Address retrieved successfully", address)
+}
+
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	var req dto.AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	address, err := h.addressService.CreateAddress(c.GetUint("user_id"), &req)
+	switch {
+	case errors.Is(err, service.ErrInvalidPostalCode):
+		utils.BadRequestResponse(c, "Invalid postal code", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to create address")
+		utils.InternalServerErrorResponse(c, "Failed to create address", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Address created successfully", address)
+}
+
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	addressID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid address ID", err)
+		return
+	}
+
+	var req dto.AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	address, err := h.addressService.UpdateAddress(c.GetUint("user_id"), uint(addressID), &req)
+	switch {
+	case errors.Is(err, service.ErrAddressNotFound):
+		utils.NotFoundResponse(c, "Address not found")
+		return
+	case errors.Is(err, service.ErrInvalidPostalCode):
+		utils.BadRequestResponse(c, "Invalid postal code", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to update address")
+		utils.InternalServerErrorResponse(c, "Failed to update address", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Address updated successfully", address)
+}
+
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	addressID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid address ID", err)
+		return
+	}
+
+	err = h.addressService.DeleteAddress(c.GetUint("user_id"), uint(addressID))
+	switch {
+	case errors.Is(err, service.ErrAddressNotFound):
+		utils.NotFoundResponse(c, "Address not found")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to delete address")
+		utils.InternalServerErrorResponse(c, "Failed to delete address", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Address deleted successfully", nil)
+}
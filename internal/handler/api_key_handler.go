@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+	logger        zerolog.Logger
+}
+
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService, logger zerolog.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		logger:        logger,
+	}
+}
+
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	h.listAPIKeys(c, c.GetUint("user_id"))
+}
+
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	h.createAPIKey(c, c.GetUint("user_id"))
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	h.revokeAPIKey(c, c.GetUint("user_id"))
+}
+
+func (h *APIKeyHandler) CreateServiceAccount(c *gin.Context) {
+	var req dto.CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	account, err := h.apiKeyService.CreateServiceAccount(&req)
+	switch {
+	case errors.Is(err, service.ErrRoleNotFound):
+		utils.BadRequestResponse(c, "Role not found", err)
+		return
+	case errors.Is(err, service.ErrDuplicateEmail):
+		utils.BadRequestResponse(c, "Email already in use", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to create service account")
+		utils.InternalServerErrorResponse(c, "Failed to create service account", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Service account created successfully", account)
+}
+
+func (h *APIKeyHandler) ListServiceAccounts(c *gin.Context) {
+	accounts, err := h.apiKeyService.ListServiceAccounts()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list service accounts")
+		utils.InternalServerErrorResponse(c, "Failed to list service accounts", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Service accounts retrieved successfully", accounts)
+}
+
+func (h *APIKeyHandler) ListServiceAccountKeys(c *gin.Context) {
+	if accountID, ok := h.serviceAccountID(c); ok {
+		h.listAPIKeys(c, accountID)
+	}
+}
+
+func (h *APIKeyHandler) CreateServiceAccountKey(c *gin.Context) {
+	if accountID, ok := h.serviceAccountID(c); ok {
+		h.createAPIKey(c, accountID)
+	}
+}
+
+func (h *APIKeyHandler) RevokeServiceAccountKey(c *gin.Context) {
+	if accountID, ok := h.serviceAccountID(c); ok {
+		h.revokeAPIKey(c, accountID)
+	}
+}
+
+// serviceAccountID reads the :id parameter and writes an error response unless it names a service account
+func (h *APIKeyHandler) serviceAccountID(c *gin.Context) (uint, bool) {
+	accountID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid service account ID", err)
+		return 0, false
+	}
+
+	if err := h.apiKeyService.RequireServiceAccount(uint(accountID)); err != nil {
+		utils.NotFoundResponse(c, "Service account not found")
+		return 0, false
+	}
+	return uint(accountID), true
+}
+
+func (h *APIKeyHandler) listAPIKeys(c *gin.Context, ownerID uint) {
+	keys, err := h.apiKeyService.ListAPIKeys(ownerID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list api keys")
+		utils.InternalServerErrorResponse(c, "Failed to list API keys", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "API keys retrieved successfully", keys)
+}
+
+func (h *APIKeyHandler) createAPIKey(c *gin.Context, ownerID uint) {
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(ownerID, &req)
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		utils.NotFoundResponse(c, "User not found")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to create api key")
+		utils.BadRequestResponse(c, "Failed to create API key", err)
+		return
+	}
+
+	h.logger.Info().Uint("actor_id", c.GetUint("user_id")).Uint("owner_id", ownerID).Uint("api_key_id", key.ID).Msg("API key created")
+	utils.CreatedResponse(c, "API key created. Store it now, it will not be shown again", key)
+}
+
+func (h *APIKeyHandler) revokeAPIKey(c *gin.Context, ownerID uint) {
+	keyID, err := strconv.ParseUint(c.Param("key_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid API key ID", err)
+		return
+	}
+
+	err = h.apiKeyService.RevokeAPIKey(ownerID, uint(keyID))
+	switch {
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		utils.NotFoundResponse(c, "API key not found")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to revoke api key")
+		utils.InternalServerErrorResponse(c, "Failed to revoke API key", err)
+		return
+	}
+
+	h.logger.Info().Uint("actor_id", c.GetUint("user_id")).Uint("owner_id", ownerID).Uint64("api_key_id", keyID).Msg("API key revoked")
+	utils.SuccessResponse(c, "API key revoked successfully", nil)
+}
@@ -1,6 +1,10 @@
 package handler
 
 import (
+	"errors"
+	"net/http"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"github.com/programmerjide/ecommerce/internal/dto"
 	"github.com/programmerjide/ecommerce/internal/service"
@@ -27,7 +31,17 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authService.Register(&req)
+	// Shoppers may send the guest cart token the same way the cart routes take it
+	if req.CartToken == "" {
+		req.CartToken = c.GetHeader("X-Cart-Token")
+	}
+
+	response, err := h.authService.Register(&req, clientInfo(c))
+	var policyErr *service.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		utils.ValidationErrorResponse(c, "Password does not meet the password policy", policyErr.Violations)
+		return
+	}
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Registration failed")
 		utils.BadRequestResponse(c, "Registration failed: ", err)
@@ -46,10 +60,27 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	h.logger.Info().Str("email", req.Email).Msg("Login attempt") // ✅ Add logging
 
-	response, err := h.authService.Login(&req)
+	// Shoppers may send the guest cart token the same way the cart routes take it
+	if req.CartToken == "" {
+		req.CartToken = c.GetHeader("X-Cart-Token")
+	}
+
+	response, err := h.authService.Login(&req, clientInfo(c))
+	var lockedErr *service.LockedError
+	if errors.As(err, &lockedErr) {
+		h.logger.Warn().Str("email", req.Email).Str("ip", c.ClientIP()).Msg("Login blocked by brute-force protection")
+		utils.TooManyRequestsResponse(c, "Too many failed login attempts, please try again later", lockedErr.RetryAfter)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		h.logger.Warn().Str("email", req.Email).Str("ip", c.ClientIP()).Msg("Login failed")
+		utils.UnauthorizedResponse(c, "Invalid email or password")
+		return
+	}
 	if err != nil {
-		h.logger.Error().Err(err).Str("email", req.Email).Msg("Login failed") // ✅ Log actual error
-		utils.UnauthorizedResponse(c, err.Error())                            // ✅ Return actual error temporarily
+		// The cause is logged only; it may reveal internal details
+		h.logger.Error().Err(err).Str("email", req.Email).Msg("Login failed")
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Login failed", nil)
 		return
 	}
 
@@ -63,7 +94,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authService.RefreshToken(&req)
+	response, err := h.authService.RefreshToken(&req, clientInfo(c))
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Token refresh failed")
 		utils.UnauthorizedResponse(c, "Token refresh failed")
@@ -89,3 +120,203 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 
 	utils.SuccessResponse(c, "Logout successful", nil)
 }
+
+// JWKS serves the public verification keys in the standard JWK Set format
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.authService.JWKS())
+}
+
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.authService.ForgotPassword(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to start password reset")
+		utils.InternalServerErrorResponse(c, "Failed to start password reset", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "If the email is registered, a password reset link has been sent", nil)
+}
+
+// RequestGuestConversion emails a guest the link that turns them into a customer
+func (h *AuthHandler) RequestGuestConversion(c *gin.Context) {
+	var req dto.GuestConversionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.authService.RequestGuestConversion(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to start guest conversion")
+		utils.InternalServerErrorResponse(c, "Failed to start guest conversion", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "If the order was placed as a guest, a link to create an account has been sent to its email", nil)
+}
+
+// ConvertGuest turns a guest into a registered customer who owns their past orders
+func (h *AuthHandler) ConvertGuest(c *gin.Context) {
+	var req dto.ConvertGuestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	response, err := h.authService.ConvertGuest(&req, clientInfo(c))
+	var policyErr *service.PasswordPolicyError
+	switch {
+	case errors.As(err, &policyErr):
+		utils.ValidationErrorResponse(c, "Password does not meet the password policy", policyErr.Violations)
+		return
+	case errors.Is(err, service.ErrDuplicateEmail):
+		utils.ErrorResponse(c, http.StatusConflict, "An account already exists for this email", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Guest conversion failed")
+		utils.BadRequestResponse(c, "Registration failed", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Account created successfully", response)
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	err := h.authService.ResetPassword(&req)
+	var policyErr *service.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		utils.ValidationErrorResponse(c, "Password does not meet the password policy", policyErr.Violations)
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Password reset failed")
+		utils.BadRequestResponse(c, "Password reset failed", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Password reset successfully", nil)
+}
+
+// UnlockAccount lets an admin clear the lockout of an account. IP address throttling
+// is not cleared.
+func (h *AuthHandler) UnlockAccount(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	if err := h.authService.UnlockAccount(uint(userID)); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
+		h.logger.Error().Err(err).Msg("Failed to unlock account")
+		utils.InternalServerErrorResponse(c, "Failed to unlock account", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint64("user_id", userID).Msg("Admin unlocked account")
+	utils.SuccessResponse(c, "Account unlocked successfully", nil)
+}
+
+// clientInfo captures the device details recorded on a session
+func (h *AuthHandler) Impersonate(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	var req dto.ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	actorID := c.GetUint("user_id")
+	response, err := h.authService.Impersonate(actorID, uint(userID), &req, clientInfo(c))
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		utils.NotFoundResponse(c, "User not found")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to start impersonation")
+		utils.BadRequestResponse(c, "Failed to start impersonation", err)
+		return
+	}
+
+	h.logger.Info().
+		Uint("impersonator_id", actorID).
+		Uint64("user_id", userID).
+		Uint("impersonation_id", response.ImpersonationID).
+		Str("reason", req.Reason).
+		Msg("Impersonation started")
+	utils.CreatedResponse(c, "Impersonation started", response)
+}
+
+func (h *AuthHandler) EndImpersonation(c *gin.Context) {
+	err := h.authService.EndImpersonation(c.GetUint("session_id"), c.GetUint("impersonator_id"))
+	switch {
+	case errors.Is(err, service.ErrImpersonationNotFound):
+		utils.BadRequestResponse(c, "You are not impersonating anyone", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to end impersonation")
+		utils.InternalServerErrorResponse(c, "Failed to end impersonation", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Impersonation ended", nil)
+}
+
+func (h *AuthHandler) ListImpersonations(c *gin.Context) {
+	var req dto.ListImpersonationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	impersonations, meta, err := h.authService.ListImpersonations(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list impersonations")
+		utils.InternalServerErrorResponse(c, "Failed to list impersonations", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Impersonations retrieved successfully", impersonations, meta)
+}
+
+func (h *AuthHandler) GetImpersonation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid impersonation ID", err)
+		return
+	}
+
+	impersonation, err := h.authService.GetImpersonation(uint(id))
+	if err != nil {
+		utils.NotFoundResponse(c, "Impersonation not found")
+		return
+	}
+
+	utils.SuccessResponse(c, "Impersonation retrieved successfully", impersonation)
+}
+
+func clientInfo(c *gin.Context) dto.ClientInfo {
+	return dto.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
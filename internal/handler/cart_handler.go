@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type CartHandler struct {
+	cartService *service.CartService
+	logger      zerolog.Logger
+}
+
+func NewCartHandler(cartService *service.CartService, logger zerolog.Logger) *CartHandler {
+	return &CartHandler{
+		cartService: cartService,
+		logger:      logger,
+	}
+}
+
+func (h *CartHandler) GetCart(c *gin.Context) {
+	cart, err := h.cartService.GetCart(cartOwner(c))
+	if err != nil {
+		h.cartErrorResponse(c, err, "Failed to get cart")
+		return
+	}
+
+	utils.SuccessResponse(c, "Cart retrieved successfully", cart)
+}
+
+func (h *CartHandler) AddToCart(c *gin.Context) {
+	var req dto.AddToCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	cart, err := h.cartService.AddToCart(cartOwner(c), &req)
+	if err != nil {
+		h.cartErrorResponse(c, err, "Failed to add item to cart")
+		return
+	}
+
+	utils.SuccessResponse(c, "Item added to cart", cart)
+}
+
+func (h *CartHandler) UpdateCartItem(c *gin.Context) {
+	itemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid cart item ID", err)
+		return
+	}
+
+	var req dto.UpdateCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	cart, err := h.cartService.UpdateCartItem(cartOwner(c), uint(itemID), &req)
+	if err != nil {
+		h.cartErrorResponse(c, err, "Failed to update cart item")
+		return
+	}
+
+	utils.SuccessResponse(c, "Cart item updated successfully", cart)
+}
+
+func (h *CartHandler) RemoveCartItem(c *gin.Context) {
+	itemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid cart item ID", err)
+		return
+	}
+
+	cart, err := h.cartService.RemoveCartItem(cartOwner(c), uint(itemID))
+	if err != nil {
+		h.cartErrorResponse(c, err, "Failed to remove cart item")
+		return
+	}
+
+	utils.SuccessResponse(c, "Cart item removed successfully", cart)
+}
+
+func (h *CartHandler) ClearCart(c *gin.Context) {
+	if err := h.cartService.ClearCart(cartOwner(c)); err != nil {
+		h.cartErrorResponse(c, err, "Failed to clear cart")
+		return
+	}
+
+	utils.SuccessResponse(c, "Cart cleared successfully", nil)
+}
+
+func (h *CartHandler) ApplyCoupon(c *gin.Context) {
+	var req dto.ApplyCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	cart, err := h.cartService.ApplyCoupon(cartOwner(c), &req)
+	if err != nil {
+		h.cartErrorResponse(c, err, "Failed to apply coupon")
+		return
+	}
+
+	utils.SuccessResponse(c, "Coupon applied successfully", cart)
+}
+
+func (h *CartHandler) RemoveCoupon(c *gin.Context) {
+	cart, err := h.cartService.RemoveCoupon(cartOwner(c))
+	if err != nil {
+		h.cartErrorResponse(c, err, "Failed to remove coupon")
+		return
+	}
+
+	utils.SuccessResponse(c, "Coupon removed successfully", cart)
+}
+
+func (h *CartHandler) cartErrorResponse(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCartToken):
+		utils.BadRequestResponse(c, "Invalid or expired cart token", err)
+	case errors.Is(err, service.ErrCartItemNotFound):
+		utils.NotFoundResponse(c, "Cart item not found")
+	case errors.Is(err, service.ErrProductUnavailable):
+		utils.BadRequestResponse(c, "Product is not available", err)
+	case errors.Is(err, service.ErrInsufficientStock):
+		utils.ErrorResponse(c, http.StatusConflict, "Not enough stock", err)
+	case errors.Is(err, service.ErrEmptyCart):
+		utils.BadRequestResponse(c, "Cart is empty", err)
+	case errors.Is(err, service.ErrCouponNotFound):
+		utils.NotFoundResponse(c, "Coupon not found")
+	case errors.Is(err, service.ErrCouponNotApplicable):
+		utils.BadRequestResponse(c, "Coupon cannot be applied to this cart", err)
+	case errors.Is(err, service.ErrCouponUsageLimit):
+		utils.BadRequestResponse(c, "Coupon usage limit reached", err)
+	default:
+		h.logger.Error().Err(err).Msg(message)
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
+
+// cartOwner identifies the cart of the request: the signed-in user's, or the guest
+// cart whose token is sent in the X-Cart-Token header
+func cartOwner(c *gin.Context) service.CartOwner {
+	return service.CartOwner{
+		UserID:    c.GetUint("user_id"),
+		CartToken: c.GetHeader("X-Cart-Token"),
+	}
+}
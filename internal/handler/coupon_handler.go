@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type CouponHandler struct {
+	couponService *service.CouponService
+	logger        zerolog.Logger
+}
+
+func NewCouponHandler(couponService *service.CouponService, logger zerolog.Logger) *CouponHandler {
+	return &CouponHandler{
+		couponService: couponService,
+		logger:        logger,
+	}
+}
+
+func (h *CouponHandler) ListCoupons(c *gin.Context) {
+	var req dto.ListCouponsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	coupons, meta, err := h.couponService.ListCoupons(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list coupons")
+		utils.InternalServerErrorResponse(c, "Failed to list coupons", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Coupons retrieved successfully", coupons, meta)
+}
+
+func (h *CouponHandler) GetCoupon(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid coupon ID", err)
+		return
+	}
+
+	coupon, err := h.couponService.GetCoupon(uint(id))
+	if err != nil {
+		utils.NotFoundResponse(c, "Coupon not found")
+		return
+	}
+
+	utils.SuccessResponse(c, "Coupon retrieved successfully", coupon)
+}
+
+func (h *CouponHandler) CreateCoupon(c *gin.Context) {
+	var req dto.CouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	coupon, err := h.couponService.CreateCoupon(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to create coupon")
+		utils.BadRequestResponse(c, "Failed to create coupon", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Str("code", coupon.Code).Msg("Coupon created")
+	utils.CreatedResponse(c, "Coupon created successfully", coupon)
+}
+
+func (h *CouponHandler) UpdateCoupon(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid coupon ID", err)
+		return
+	}
+
+	var req dto.CouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	coupon, err := h.couponService.UpdateCoupon(uint(id), &req)
+	if errors.Is(err, service.ErrCouponNotFound) {
+		utils.NotFoundResponse(c, "Coupon not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to update coupon")
+		utils.BadRequestResponse(c, "Failed to update coupon", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Str("code", coupon.Code).Msg("Coupon updated")
+	utils.SuccessResponse(c, "Coupon updated successfully", coupon)
+}
+
+func (h *CouponHandler) DeleteCoupon(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid coupon ID", err)
+		return
+	}
+
+	err = h.couponService.DeleteCoupon(uint(id))
+	if errors.Is(err, service.ErrCouponNotFound) {
+		utils.NotFoundResponse(c, "Coupon not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to delete coupon")
+		utils.InternalServerErrorResponse(c, "Failed to delete coupon", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Coupon deleted successfully", nil)
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type InvoiceHandler struct {
+	invoiceService *service.InvoiceService
+	logger         zerolog.Logger
+}
+
+func NewInvoiceHandler(invoiceService *service.InvoiceService, logger zerolog.Logger) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+		logger:         logger,
+	}
+}
+
+// ListOrderInvoices lists the invoice and credit notes of one of the user's orders
+func (h *InvoiceHandler) ListOrderInvoices(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid order ID", err)
+		return
+	}
+
+	invoices, err := h.invoiceService.ListOrderInvoices(c.GetUint("user_id"), uint(orderID))
+	if errors.Is(err, service.ErrOrderNotFound) {
+		utils.NotFoundResponse(c, "Order not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list invoices")
+		utils.InternalServerErrorResponse(c, "Failed to list invoices", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Invoices retrieved successfully", invoices)
+}
+
+// DownloadMyInvoice sends the PDF of an invoice or credit note of the user's own order
+func (h *InvoiceHandler) DownloadMyInvoice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid invoice ID", err)
+		return
+	}
+
+	invoice, data, err := h.invoiceService.GetUserInvoicePDF(c.GetUint("user_id"), uint(id))
+	h.sendInvoice(c, invoice, data, err)
+}
+
+func (h *InvoiceHandler) ListInvoices(c *gin.Context) {
+	var req dto.ListInvoicesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	invoices, meta, err := h.invoiceService.ListInvoices(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list invoices")
+		utils.InternalServerErrorResponse(c, "Failed to list invoices", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Invoices retrieved successfully", invoices, meta)
+}
+
+func (h *InvoiceHandler) DownloadInvoice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid invoice ID", err)
+		return
+	}
+
+	invoice, data, err := h.invoiceService.GetInvoicePDF(uint(id))
+	h.sendInvoice(c, invoice, data, err)
+}
+
+// IssueInvoice invoices a paid order now rather than when its invoice is first requested
+func (h *InvoiceHandler) IssueInvoice(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid order ID", err)
+		return
+	}
+
+	invoice, err := h.invoiceService.IssueInvoice(uint(orderID))
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound):
+		utils.NotFoundResponse(c, "Order not found")
+		return
+	case errors.Is(err, service.ErrOrderNotInvoiceable):
+		utils.ErrorResponse(c, http.StatusConflict, "Order cannot be invoiced", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to issue invoice")
+		utils.InternalServerErrorResponse(c, "Failed to issue invoice", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("order_id", uint(orderID)).
+		Str("invoice_number", invoice.Number).Msg("Invoice issued")
+	utils.SuccessResponse(c, "Invoice issued successfully", invoice)
+}
+
+// PackingSlip sends the warehouse packing slip of an order
+func (h *InvoiceHandler) PackingSlip(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid order ID", err)
+		return
+	}
+
+	orderNumber, data, err := h.invoiceService.PackingSlip(uint(orderID))
+	if errors.Is(err, service.ErrOrderNotFound) {
+		utils.NotFoundResponse(c, "Order not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to create packing slip")
+		utils.InternalServerErrorResponse(c, "Failed to create packing slip", err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="packing-slip-%s.pdf"`, orderNumber))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+func (h *InvoiceHandler) sendInvoice(c *gin.Context, invoice *dto.InvoiceResponse, data []byte, err error) {
+	if errors.Is(err, service.ErrInvoiceNotFound) {
+		utils.NotFoundResponse(c, "Invoice not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to read invoice")
+		utils.InternalServerErrorResponse(c, "Failed to read invoice", err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, invoice.Number))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+	outbox              *service.EmailOutbox
+	logger              zerolog.Logger
+}
+
+func NewNotificationHandler(notificationService *service.NotificationService, outbox *service.EmailOutbox, logger zerolog.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		outbox:              outbox,
+		logger:              logger,
+	}
+}
+
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	preferences, err := h.notificationService.GetPreferences(c.GetUint("user_id"))
+	if errors.Is(err, service.ErrUserNotFound) {
+		utils.NotFoundResponse(c, "User not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to get notification preferences")
+		utils.InternalServerErrorResponse(c, "Failed to get notification preferences", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Notification preferences retrieved successfully", preferences)
+}
+
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var req dto.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(c.GetUint("user_id"), &req)
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		utils.NotFoundResponse(c, "User not found")
+		return
+	case errors.Is(err, service.ErrUnsupportedLocale),
+		errors.Is(err, service.ErrUnknownNotification),
+		errors.Is(err, service.ErrNotificationRequired):
+		utils.BadRequestResponse(c, "Invalid notification preferences", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to update notification preferences")
+		utils.InternalServerErrorResponse(c, "Failed to update notification preferences", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Notification preferences updated successfully", preferences)
+}
+
+// ListOutboxEmails shows support which emails were sent and which are failing
+func (h *NotificationHandler) ListOutboxEmails(c *gin.Context) {
+	var req dto.ListOutboxEmailsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	emails, meta, err := h.outbox.ListEmails(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list emails")
+		utils.InternalServerErrorResponse(c, "Failed to list emails", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Emails retrieved successfully", emails, meta)
+}
+
+// RetryOutboxEmail sends an email that ran out of attempts again, e.g. once the mail
+// server is fixed
+func (h *NotificationHandler) RetryOutboxEmail(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid email ID", err)
+		return
+	}
+
+	email, err := h.outbox.RetryEmail(uint(id))
+	switch {
+	case errors.Is(err, service.ErrOutboxEmailNotFound):
+		utils.NotFoundResponse(c, "Email not found")
+		return
+	case errors.Is(err, service.ErrEmailNotRetryable):
+		utils.ErrorResponse(c, http.StatusConflict, "Email cannot be retried", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to retry email")
+		utils.InternalServerErrorResponse(c, "Failed to retry email", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("email_id", email.ID).
+		Str("status", email.Status).Msg("Email retried")
+	utils.SuccessResponse(c, "Email retried", email)
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// oidcStateCookie binds a started sign-in to the browser that started it
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/v1/auth/oidc"
+)
+
+type OIDCHandler struct {
+	oidcService *service.OIDCService
+	logger      zerolog.Logger
+}
+
+func NewOIDCHandler(oidcService *service.OIDCService, logger zerolog.Logger) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+		logger:      logger,
+	}
+}
+
+// Authorize returns the provider URL the client should send the user to
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	provider := c.Param("provider")
+	response, err := h.oidcService.StartLogin(c.Request.Context(), provider)
+	if errors.Is(err, service.ErrUnknownProvider) {
+		utils.NotFoundResponse(c, "Identity provider not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("provider", provider).Msg("Failed to start OIDC login")
+		utils.InternalServerErrorResponse(c, "Failed to start login", err)
+		return
+	}
+
+	// Ties the sign-in to this browser; the callback must present it
+	maxAge := int(time.Until(response.ExpiresAt).Seconds())
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, service.StateBinding(response.State), maxAge, oidcCookiePath, "", gin.Mode() == gin.ReleaseMode, true)
+
+	utils.SuccessResponse(c, "Authorization URL created", response)
+}
+
+// Callback completes the sign-in after the provider redirects back with a code
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	provider := c.Param("provider")
+	if providerErr := c.Query("error"); providerErr != "" {
+		utils.UnauthorizedResponse(c, "Sign-in was cancelled or denied: "+providerErr)
+		return
+	}
+
+	var req dto.OIDCCallbackRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid callback parameters", err)
+		return
+	}
+
+	stateBinding, _ := c.Cookie(oidcStateCookie)
+	// The state is single use, so the cookie is no longer needed either way
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", gin.Mode() == gin.ReleaseMode, true)
+
+	response, err := h.oidcService.CompleteLogin(c.Request.Context(), provider, &req, stateBinding, clientInfo(c))
+	if errors.Is(err, service.ErrUnknownProvider) {
+		utils.NotFoundResponse(c, "Identity provider not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("provider", provider).Msg("OIDC login failed")
+		utils.UnauthorizedResponse(c, "Sign-in failed")
+		return
+	}
+
+	utils.SuccessResponse(c, "Login successful", response)
+}
+
+func (h *OIDCHandler) ListIdentities(c *gin.Context) {
+	identities, err := h.oidcService.ListIdentities(c.GetUint("user_id"))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list linked identities")
+		utils.InternalServerErrorResponse(c, "Failed to list linked identities", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Linked identities retrieved successfully", identities)
+}
+
+func (h *OIDCHandler) UnlinkIdentity(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid identity ID", err)
+		return
+	}
+
+	err = h.oidcService.UnlinkIdentity(c.GetUint("user_id"), uint(id))
+	switch {
+	case errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrIdentityNotFound):
+		utils.NotFoundResponse(c, "Linked identity not found")
+		return
+	case errors.Is(err, service.ErrLastSignInMethod):
+		utils.ErrorResponse(c, http.StatusConflict, "Identity cannot be unlinked", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to unlink identity")
+		utils.InternalServerErrorResponse(c, "Failed to unlink identity", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Identity unlinked successfully", nil)
+}
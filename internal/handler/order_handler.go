@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type OrderHandler struct {
+	orderService *service.OrderService
+	logger       zerolog.Logger
+}
+
+func NewOrderHandler(orderService *service.OrderService, logger zerolog.Logger) *OrderHandler {
+	return &OrderHandler{
+		orderService: orderService,
+		logger:       logger,
+	}
+}
+
+func (h *OrderHandler) Checkout(c *gin.Context) {
+	var req dto.CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	order, err := h.orderService.Checkout(cartOwner(c), &req)
+	switch {
+	case errors.Is(err, service.ErrInvalidCartToken):
+		utils.BadRequestResponse(c, "Invalid or expired cart token", err)
+		return
+	case errors.Is(err, service.ErrEmptyCart):
+		utils.BadRequestResponse(c, "Cart is empty", err)
+		return
+	case errors.Is(err, service.ErrGuestDetailsRequired):
+		utils.BadRequestResponse(c, "Email and shipping address are required", err)
+		return
+	case errors.Is(err, service.ErrAddressNotFound):
+		utils.NotFoundResponse(c, "Address not found")
+		return
+	case errors.Is(err, service.ErrInvalidPostalCode):
+		utils.BadRequestResponse(c, "Invalid postal code", err)
+		return
+	case errors.Is(err, service.ErrProductUnavailable):
+		utils.ErrorResponse(c, http.StatusConflict, "A product in the cart is no longer available", err)
+		return
+	case errors.Is(err, service.ErrInsufficientStock):
+		utils.ErrorResponse(c, http.StatusConflict, "Not enough stock", err)
+		return
+	case errors.Is(err, service.ErrCouponNotFound),
+		errors.Is(err, service.ErrCouponNotApplicable),
+		errors.Is(err, service.ErrCouponUsageLimit):
+		utils.ErrorResponse(c, http.StatusConflict, "The coupon on the cart can no longer be used", err)
+		return
+	case errors.Is(err, service.ErrShippingMethodRequired):
+		utils.BadRequestResponse(c, "A shipping method is required", err)
+		return
+	case errors.Is(err, service.ErrShippingUnavailable):
+		utils.BadRequestResponse(c, "The shipping method is not available for this address", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Checkout failed")
+		utils.InternalServerErrorResponse(c, "Checkout failed", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Order placed successfully", order)
+}
+
+func (h *OrderHandler) ListOrders(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	orders, meta, err := h.orderService.ListOrders(c.GetUint("user_id"), page, limit)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list orders")
+		utils.InternalServerErrorResponse(c, "Failed to list orders", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Orders retrieved successfully", orders, meta)
+}
+
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid order ID", err)
+		return
+	}
+
+	order, err := h.orderService.GetOrder(c.GetUint("user_id"), uint(orderID))
+	if err != nil {
+		utils.NotFoundResponse(c, "Order not found")
+		return
+	}
+
+	utils.SuccessResponse(c, "Order retrieved successfully", order)
+}
+
+// ConfirmOrder marks a pending order as paid once the payment has been received
+func (h *OrderHandler) ConfirmOrder(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid order ID", err)
+		return
+	}
+
+	order, err := h.orderService.ConfirmOrder(uint(orderID))
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound):
+		utils.NotFoundResponse(c, "Order not found")
+		return
+	case errors.Is(err, service.ErrOrderNotPending):
+		utils.ErrorResponse(c, http.StatusConflict, "Order cannot be confirmed", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to confirm order")
+		utils.InternalServerErrorResponse(c, "Failed to confirm order", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("order_id", order.ID).Msg("Order payment confirmed")
+	utils.SuccessResponse(c, "Order confirmed", order)
+}
+
+// LookupOrder lets guests check an order with its number and email address
+func (h *OrderHandler) LookupOrder(c *gin.Context) {
+	var req dto.OrderLookupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	order, err := h.orderService.LookupOrder(&req)
+	if err != nil {
+		utils.NotFoundResponse(c, "Order not found")
+		return
+	}
+
+	utils.SuccessResponse(c, "Order retrieved successfully", order)
+}
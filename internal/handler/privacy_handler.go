@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type PrivacyHandler struct {
+	privacyService *service.PrivacyService
+	logger         zerolog.Logger
+}
+
+func NewPrivacyHandler(privacyService *service.PrivacyService, logger zerolog.Logger) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+		logger:         logger,
+	}
+}
+
+// ExportData returns everything we hold about the caller, as JSON or as a ZIP download with ?format=zip
+func (h *PrivacyHandler) ExportData(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	if c.Query("format") == "zip" {
+		archive, err := h.privacyService.ExportUserDataArchive(userID)
+		if err != nil {
+			h.logger.Error().Err(err).Msg("Failed to export user data")
+			utils.InternalServerErrorResponse(c, "Failed to export data", err)
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%d-data.zip"`, userID))
+		c.Data(http.StatusOK, "application/zip", archive)
+		return
+	}
+
+	export, err := h.privacyService.ExportUserData(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to export user data")
+		utils.InternalServerErrorResponse(c, "Failed to export data", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Data exported successfully", export)
+}
+
+func (h *PrivacyHandler) RequestErasure(c *gin.Context) {
+	var req dto.CreateErasureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	request, err := h.privacyService.RequestErasure(c.GetUint("user_id"), &req)
+	switch {
+	case errors.Is(err, service.ErrErasureRequestPending):
+		utils.ErrorResponse(c, http.StatusConflict, "An erasure request is already being reviewed", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to create erasure request")
+		utils.InternalServerErrorResponse(c, "Failed to create erasure request", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Erasure request submitted for review", request)
+}
+
+func (h *PrivacyHandler) ListMyErasureRequests(c *gin.Context) {
+	requests, err := h.privacyService.ListMyErasureRequests(c.GetUint("user_id"))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list erasure requests")
+		utils.InternalServerErrorResponse(c, "Failed to list erasure requests", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Erasure requests retrieved successfully", requests)
+}
+
+func (h *PrivacyHandler) CancelErasure(c *gin.Context) {
+	err := h.privacyService.CancelErasure(c.GetUint("user_id"))
+	switch {
+	case errors.Is(err, service.ErrErasureRequestNotFound):
+		utils.NotFoundResponse(c, "No pending erasure request")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to cancel erasure request")
+		utils.InternalServerErrorResponse(c, "Failed to cancel erasure request", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Erasure request cancelled", nil)
+}
+
+func (h *PrivacyHandler) ListErasureRequests(c *gin.Context) {
+	var req dto.ListErasureRequestsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	requests, meta, err := h.privacyService.ListErasureRequests(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list erasure requests")
+		utils.InternalServerErrorResponse(c, "Failed to list erasure requests", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Erasure requests retrieved successfully", requests, meta)
+}
+
+func (h *PrivacyHandler) ApproveErasure(c *gin.Context) {
+	h.reviewErasure(c, true)
+}
+
+func (h *PrivacyHandler) RejectErasure(c *gin.Context) {
+	h.reviewErasure(c, false)
+}
+
+func (h *PrivacyHandler) reviewErasure(c *gin.Context, approve bool) {
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid erasure request ID", err)
+		return
+	}
+
+	// The review note is optional, so an empty body is accepted
+	var req dto.ReviewErasureRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	reviewerID := c.GetUint("user_id")
+	var request *dto.ErasureRequestResponse
+	if approve {
+		request, err = h.privacyService.ApproveErasure(reviewerID, uint(requestID), &req)
+	} else {
+		request, err = h.privacyService.RejectErasure(reviewerID, uint(requestID), &req)
+	}
+
+	switch {
+	case errors.Is(err, service.ErrErasureRequestNotFound):
+		utils.NotFoundResponse(c, "Pending erasure request not found")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to review erasure request")
+		utils.BadRequestResponse(c, "Failed to review erasure request", err)
+		return
+	}
+
+	h.logger.Info().
+		Uint("reviewer_id", reviewerID).
+		Uint64("erasure_request_id", requestID).
+		Str("status", request.Status).
+		Msg("Erasure request reviewed")
+	utils.SuccessResponse(c, "Erasure request reviewed", request)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/programmerjide/ecommerce/internal/dto"
 	"github.com/programmerjide/ecommerce/internal/service"
@@ -102,7 +103,11 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		return
 	}
 
-	response, err := h.productService.CreateProduct(&req)
+	response, err := h.productService.CreateProduct(&req, c.GetUint("user_id"))
+	if errors.Is(err, service.ErrInvalidSale) {
+		utils.BadRequestResponse(c, "Invalid sale", err)
+		return
+	}
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Failed to create product")
 		utils.InternalServerErrorResponse(c, "Failed to create product", err)
@@ -113,9 +118,13 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 }
 
 func (h *ProductHandler) GetProducts(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	products, meta, err := h.productService.GetProducts(page, limit)
+	var req dto.ListProductsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	products, meta, err := h.productService.GetProducts(&req)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Failed to get products")
 		utils.InternalServerErrorResponse(c, "Failed to get products", err)
@@ -157,7 +166,11 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	response, err := h.productService.UpdateProduct(uint(id), &req)
+	response, err := h.productService.UpdateProduct(uint(id), &req, c.GetUint("user_id"))
+	if errors.Is(err, service.ErrInvalidSale) {
+		utils.BadRequestResponse(c, "Invalid sale", err)
+		return
+	}
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Failed to update product")
 		utils.InternalServerErrorResponse(c, "Failed to update product", err)
@@ -167,6 +180,31 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	utils.SuccessResponse(c, "Product updated successfully", response)
 }
 
+// GetPriceHistory lists the price changes of a product with who made them
+func (h *ProductHandler) GetPriceHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid product ID", err)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	history, meta, err := h.productService.GetPriceHistory(uint(id), page, limit)
+	if errors.Is(err, service.ErrProductNotFound) {
+		utils.NotFoundResponse(c, "Product not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to get price history")
+		utils.InternalServerErrorResponse(c, "Failed to get price history", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Price history retrieved successfully", history, meta)
+}
+
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
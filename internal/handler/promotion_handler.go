@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type PromotionHandler struct {
+	promotionService *service.PromotionService
+	logger           zerolog.Logger
+}
+
+func NewPromotionHandler(promotionService *service.PromotionService, logger zerolog.Logger) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+		logger:           logger,
+	}
+}
+
+func (h *PromotionHandler) ListPromotions(c *gin.Context) {
+	var req dto.ListPromotionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	promotions, meta, err := h.promotionService.ListPromotions(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list promotions")
+		utils.InternalServerErrorResponse(c, "Failed to list promotions", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Promotions retrieved successfully", promotions, meta)
+}
+
+func (h *PromotionHandler) GetPromotion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid promotion ID", err)
+		return
+	}
+
+	promotion, err := h.promotionService.GetPromotion(uint(id))
+	if err != nil {
+		utils.NotFoundResponse(c, "Promotion not found")
+		return
+	}
+
+	utils.SuccessResponse(c, "Promotion retrieved successfully", promotion)
+}
+
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var req dto.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	promotion, err := h.promotionService.CreatePromotion(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to create promotion")
+		utils.BadRequestResponse(c, "Failed to create promotion", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("promotion_id", promotion.ID).Msg("Promotion created")
+	utils.CreatedResponse(c, "Promotion created successfully", promotion)
+}
+
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid promotion ID", err)
+		return
+	}
+
+	var req dto.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	promotion, err := h.promotionService.UpdatePromotion(uint(id), &req)
+	if errors.Is(err, service.ErrPromotionNotFound) {
+		utils.NotFoundResponse(c, "Promotion not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to update promotion")
+		utils.BadRequestResponse(c, "Failed to update promotion", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("promotion_id", promotion.ID).Msg("Promotion updated")
+	utils.SuccessResponse(c, "Promotion updated successfully", promotion)
+}
+
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid promotion ID", err)
+		return
+	}
+
+	err = h.promotionService.DeletePromotion(uint(id))
+	if errors.Is(err, service.ErrPromotionNotFound) {
+		utils.NotFoundResponse(c, "Promotion not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to delete promotion")
+		utils.InternalServerErrorResponse(c, "Failed to delete promotion", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Promotion deleted successfully", nil)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type RBACHandler struct {
+	rbacService *service.RBACService
+	logger      zerolog.Logger
+}
+
+func NewRBACHandler(rbacService *service.RBACService, logger zerolog.Logger) *RBACHandler {
+	return &RBACHandler{
+		rbacService: rbacService,
+		logger:      logger,
+	}
+}
+
+func (h *RBACHandler) ListRoles(c *gin.Context) {
+	roles, err := h.rbacService.ListRoles()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list roles")
+		utils.InternalServerErrorResponse(c, "Failed to list roles", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Roles retrieved successfully", roles)
+}
+
+func (h *RBACHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.rbacService.ListPermissions()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list permissions")
+		utils.InternalServerErrorResponse(c, "Failed to list permissions", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Permissions retrieved successfully", permissions)
+}
+
+func (h *RBACHandler) CreateRole(c *gin.Context) {
+	var req dto.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	role, err := h.rbacService.CreateRole(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to create role")
+		utils.BadRequestResponse(c, "Failed to create role", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Role created successfully", role)
+}
+
+func (h *RBACHandler) UpdateRolePermissions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid role ID", err)
+		return
+	}
+
+	var req dto.UpdateRolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	role, err := h.rbacService.UpdateRolePermissions(uint(id), &req)
+	if errors.Is(err, service.ErrRoleNotFound) {
+		utils.NotFoundResponse(c, "Role not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to update role permissions")
+		utils.BadRequestResponse(c, "Failed to update role permissions", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Str("role", role.Name).Strs("permissions", role.Permissions).Msg("Role permissions updated")
+	utils.SuccessResponse(c, "Role permissions updated successfully", role)
+}
+
+func (h *RBACHandler) DeleteRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid role ID", err)
+		return
+	}
+
+	err = h.rbacService.DeleteRole(uint(id))
+	if errors.Is(err, service.ErrRoleNotFound) {
+		utils.NotFoundResponse(c, "Role not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to delete role")
+		utils.BadRequestResponse(c, "Failed to delete role", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Role deleted successfully", nil)
+}
+
+func (h *RBACHandler) AssignRole(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	actorID := c.GetUint("user_id")
+	user, err := h.rbacService.AssignRole(actorID, uint(userID), &req)
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		utils.NotFoundResponse(c, "User not found")
+		return
+	case errors.Is(err, service.ErrRoleNotFound):
+		utils.NotFoundResponse(c, "Role not found")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to assign role")
+		utils.BadRequestResponse(c, "Failed to assign role", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", actorID).Uint64("user_id", userID).Str("role", req.Role).Msg("Role assigned")
+	utils.SuccessResponse(c, "Role assigned successfully", user)
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type ReturnHandler struct {
+	returnService *service.ReturnService
+	logger        zerolog.Logger
+}
+
+func NewReturnHandler(returnService *service.ReturnService, logger zerolog.Logger) *ReturnHandler {
+	return &ReturnHandler{
+		returnService: returnService,
+		logger:        logger,
+	}
+}
+
+// CreateReturn requests the return of shipped items of one of the user's orders
+func (h *ReturnHandler) CreateReturn(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid order ID", err)
+		return
+	}
+
+	var req dto.CreateReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	returnRequest, err := h.returnService.CreateReturn(c.GetUint("user_id"), uint(orderID), &req)
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound):
+		utils.NotFoundResponse(c, "Order not found")
+		return
+	case errors.Is(err, service.ErrOrderNotReturnable):
+		utils.ErrorResponse(c, http.StatusConflict, "Order cannot be returned", err)
+		return
+	case errors.Is(err, service.ErrInvalidReturnItem):
+		utils.BadRequestResponse(c, "Invalid return items", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to create return")
+		utils.InternalServerErrorResponse(c, "Failed to create return", err)
+		return
+	}
+
+	h.logger.Info().Uint("user_id", c.GetUint("user_id")).Uint("order_id", uint(orderID)).
+		Uint("return_id", returnRequest.ID).Msg("Return requested")
+	utils.CreatedResponse(c, "Return requested successfully", returnRequest)
+}
+
+func (h *ReturnHandler) ListMyReturns(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	returns, meta, err := h.returnService.ListUserReturns(c.GetUint("user_id"), page, limit)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list returns")
+		utils.InternalServerErrorResponse(c, "Failed to list returns", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Returns retrieved successfully", returns, meta)
+}
+
+func (h *ReturnHandler) GetMyReturn(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid return ID", err)
+		return
+	}
+
+	returnRequest, err := h.returnService.GetUserReturn(c.GetUint("user_id"), uint(id))
+	if err != nil {
+		utils.NotFoundResponse(c, "Return not found")
+		return
+	}
+
+	utils.SuccessResponse(c, "Return retrieved successfully", returnRequest)
+}
+
+func (h *ReturnHandler) ListReturns(c *gin.Context) {
+	var req dto.ListReturnsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	returns, meta, err := h.returnService.ListReturns(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list returns")
+		utils.InternalServerErrorResponse(c, "Failed to list returns", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Returns retrieved successfully", returns, meta)
+}
+
+func (h *ReturnHandler) GetReturn(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid return ID", err)
+		return
+	}
+
+	returnRequest, err := h.returnService.GetReturn(uint(id))
+	if err != nil {
+		utils.NotFoundResponse(c, "Return not found")
+		return
+	}
+
+	utils.SuccessResponse(c, "Return retrieved successfully", returnRequest)
+}
+
+func (h *ReturnHandler) ApproveReturn(c *gin.Context) {
+	h.reviewReturn(c, "approved", h.returnService.ApproveReturn)
+}
+
+func (h *ReturnHandler) RejectReturn(c *gin.Context) {
+	h.reviewReturn(c, "rejected", h.returnService.RejectReturn)
+}
+
+func (h *ReturnHandler) RefundReturn(c *gin.Context) {
+	h.reviewReturn(c, "refunded", h.returnService.RefundReturn)
+}
+
+// ReceiveReturn records the goods arriving back, restocking them if asked to
+func (h *ReturnHandler) ReceiveReturn(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid return ID", err)
+		return
+	}
+
+	var req dto.ReceiveReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	returnRequest, err := h.returnService.ReceiveReturn(uint(id), c.GetUint("user_id"), &req)
+	if !h.handleTransitionError(c, err, "Failed to receive return") {
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("return_id", returnRequest.ID).
+		Bool("restocked", returnRequest.Restocked).Msg("Return received")
+	utils.SuccessResponse(c, "Return received successfully", returnRequest)
+}
+
+func (h *ReturnHandler) reviewReturn(c *gin.Context, outcome string,
+	action func(returnID, actorID uint, note string) (*dto.ReturnResponse, error)) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid return ID", err)
+		return
+	}
+
+	// The note is optional, so an empty body is accepted
+	var req dto.ReturnActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	returnRequest, err := action(uint(id), c.GetUint("user_id"), req.Note)
+	if !h.handleTransitionError(c, err, "Failed to update return") {
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("return_id", returnRequest.ID).Msg("Return " + outcome)
+	utils.SuccessResponse(c, "Return "+outcome+" successfully", returnRequest)
+}
+
+// handleTransitionError writes the response for a failed status change and reports
+// whether the change went through
+func (h *ReturnHandler) handleTransitionError(c *gin.Context, err error, message string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrReturnNotFound):
+		utils.NotFoundResponse(c, "Return not found")
+	case errors.Is(err, service.ErrInvalidReturnTransition):
+		utils.ErrorResponse(c, http.StatusConflict, "Return cannot be updated", err)
+	default:
+		h.logger.Error().Err(err).Msg(message)
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type SessionHandler struct {
+	sessionService *service.SessionService
+	logger         zerolog.Logger
+}
+
+func NewSessionHandler(sessionService *service.SessionService, logger zerolog.Logger) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+		logger:         logger,
+	}
+}
+
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	sessions, err := h.sessionService.ListSessions(userID, c.GetUint("session_id"))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list sessions")
+		utils.InternalServerErrorResponse(c, "Failed to list sessions", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Sessions retrieved successfully", sessions)
+}
+
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid session ID", err)
+		return
+	}
+
+	h.revokeSession(c, c.GetUint("user_id"), uint(sessionID))
+}
+
+// RevokeOtherSessions signs the caller out everywhere else
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	revoked, err := h.sessionService.RevokeOtherSessions(userID, c.GetUint("session_id"))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to revoke sessions")
+		utils.InternalServerErrorResponse(c, "Failed to revoke sessions", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Signed out of all other sessions", gin.H{"revoked": revoked})
+}
+
+func (h *SessionHandler) ListUserSessions(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(uint(userID), 0)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list user sessions")
+		utils.InternalServerErrorResponse(c, "Failed to list sessions", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Sessions retrieved successfully", sessions)
+}
+
+func (h *SessionHandler) RevokeUserSession(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(c.Param("session_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid session ID", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint64("user_id", userID).Uint64("session_id", sessionID).Msg("Admin revoking user session")
+	h.revokeSession(c, uint(userID), uint(sessionID))
+}
+
+func (h *SessionHandler) RevokeAllUserSessions(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	revoked, err := h.sessionService.RevokeAllSessions(uint(userID))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to revoke user sessions")
+		utils.InternalServerErrorResponse(c, "Failed to revoke sessions", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint64("user_id", userID).Msg("Admin revoked all user sessions")
+	utils.SuccessResponse(c, "All sessions revoked", gin.H{"revoked": revoked})
+}
+
+func (h *SessionHandler) revokeSession(c *gin.Context, userID, sessionID uint) {
+	if err := h.sessionService.RevokeSession(userID, sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			utils.NotFoundResponse(c, "Session not found")
+			return
+		}
+		h.logger.Error().Err(err).Msg("Failed to revoke session")
+		utils.InternalServerErrorResponse(c, "Failed to revoke session", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Session revoked successfully", nil)
+}
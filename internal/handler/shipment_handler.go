@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type ShipmentHandler struct {
+	shipmentService *service.ShipmentService
+	logger          zerolog.Logger
+}
+
+func NewShipmentHandler(shipmentService *service.ShipmentService, logger zerolog.Logger) *ShipmentHandler {
+	return &ShipmentHandler{
+		shipmentService: shipmentService,
+		logger:          logger,
+	}
+}
+
+// GetTracking shows a signed-in customer where the parts of their order are
+func (h *ShipmentHandler) GetTracking(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid order ID", err)
+		return
+	}
+
+	tracking, err := h.shipmentService.GetTracking(c.GetUint("user_id"), uint(orderID))
+	if err != nil {
+		utils.NotFoundResponse(c, "Order not found")
+		return
+	}
+
+	utils.SuccessResponse(c, "Tracking retrieved successfully", tracking)
+}
+
+// LookupTracking lets guests track an order with its number and email address
+func (h *ShipmentHandler) LookupTracking(c *gin.Context) {
+	var req dto.OrderLookupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	tracking, err := h.shipmentService.LookupTracking(&req)
+	if err != nil {
+		utils.NotFoundResponse(c, "Order not found")
+		return
+	}
+
+	utils.SuccessResponse(c, "Tracking retrieved successfully", tracking)
+}
+
+func (h *ShipmentHandler) ListShipments(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid order ID", err)
+		return
+	}
+
+	shipments, err := h.shipmentService.ListShipments(uint(orderID))
+	if errors.Is(err, service.ErrOrderNotFound) {
+		utils.NotFoundResponse(c, "Order not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list shipments")
+		utils.InternalServerErrorResponse(c, "Failed to list shipments", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Shipments retrieved successfully", shipments)
+}
+
+func (h *ShipmentHandler) CreateShipment(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid order ID", err)
+		return
+	}
+
+	var req dto.CreateShipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	shipment, err := h.shipmentService.CreateShipment(uint(orderID), &req, c.GetUint("user_id"))
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound):
+		utils.NotFoundResponse(c, "Order not found")
+		return
+	case errors.Is(err, service.ErrOrderNotShippable), errors.Is(err, service.ErrNothingToShip):
+		utils.ErrorResponse(c, http.StatusConflict, "Order cannot be shipped", err)
+		return
+	case errors.Is(err, service.ErrInvalidShipmentItem):
+		utils.BadRequestResponse(c, "Invalid shipment items", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to create shipment")
+		utils.InternalServerErrorResponse(c, "Failed to create shipment", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("order_id", uint(orderID)).
+		Uint("shipment_id", shipment.ID).Msg("Shipment created")
+	utils.CreatedResponse(c, "Shipment created successfully", shipment)
+}
+
+func (h *ShipmentHandler) UpdateShipment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid shipment ID", err)
+		return
+	}
+
+	var req dto.UpdateShipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	shipment, err := h.shipmentService.UpdateShipment(uint(id), &req)
+	if errors.Is(err, service.ErrShipmentNotFound) {
+		utils.NotFoundResponse(c, "Shipment not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to update shipment")
+		utils.InternalServerErrorResponse(c, "Failed to update shipment", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("shipment_id", shipment.ID).Msg("Shipment updated")
+	utils.SuccessResponse(c, "Shipment updated successfully", shipment)
+}
+
+func (h *ShipmentHandler) MarkDelivered(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid shipment ID", err)
+		return
+	}
+
+	shipment, err := h.shipmentService.MarkDelivered(uint(id))
+	if errors.Is(err, service.ErrShipmentNotFound) {
+		utils.NotFoundResponse(c, "Shipment not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to mark shipment delivered")
+		utils.InternalServerErrorResponse(c, "Failed to mark shipment delivered", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("shipment_id", shipment.ID).Msg("Shipment delivered")
+	utils.SuccessResponse(c, "Shipment marked as delivered", shipment)
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type ShippingHandler struct {
+	shippingService *service.ShippingService
+	logger          zerolog.Logger
+}
+
+func NewShippingHandler(shippingService *service.ShippingService, logger zerolog.Logger) *ShippingHandler {
+	return &ShippingHandler{
+		shippingService: shippingService,
+		logger:          logger,
+	}
+}
+
+// GetShippingRates quotes the shipping methods for the current cart
+func (h *ShippingHandler) GetShippingRates(c *gin.Context) {
+	var req dto.ShippingRatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	rates, err := h.shippingService.GetShippingRates(cartOwner(c), &req)
+	switch {
+	case errors.Is(err, service.ErrInvalidCartToken):
+		utils.BadRequestResponse(c, "Invalid or expired cart token", err)
+		return
+	case errors.Is(err, service.ErrEmptyCart):
+		utils.BadRequestResponse(c, "Cart is empty", err)
+		return
+	case errors.Is(err, service.ErrAddressNotFound):
+		utils.NotFoundResponse(c, "Address not found")
+		return
+	case errors.Is(err, service.ErrShippingDestinationRequired):
+		utils.BadRequestResponse(c, "An address or country is required", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to quote shipping")
+		utils.InternalServerErrorResponse(c, "Failed to quote shipping", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Shipping rates retrieved successfully", rates)
+}
+
+func (h *ShippingHandler) ListZones(c *gin.Context) {
+	zones, err := h.shippingService.ListZones()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list shipping zones")
+		utils.InternalServerErrorResponse(c, "Failed to list shipping zones", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Shipping zones retrieved successfully", zones)
+}
+
+func (h *ShippingHandler) GetZone(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid shipping zone ID", err)
+		return
+	}
+
+	zone, err := h.shippingService.GetZone(uint(id))
+	if err != nil {
+		utils.NotFoundResponse(c, "Shipping zone not found")
+		return
+	}
+
+	utils.SuccessResponse(c, "Shipping zone retrieved successfully", zone)
+}
+
+func (h *ShippingHandler) CreateZone(c *gin.Context) {
+	var req dto.ShippingZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	zone, err := h.shippingService.CreateZone(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to create shipping zone")
+		utils.BadRequestResponse(c, "Failed to create shipping zone", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("zone_id", zone.ID).Msg("Shipping zone created")
+	utils.CreatedResponse(c, "Shipping zone created successfully", zone)
+}
+
+func (h *ShippingHandler) UpdateZone(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid shipping zone ID", err)
+		return
+	}
+
+	var req dto.ShippingZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	zone, err := h.shippingService.UpdateZone(uint(id), &req)
+	if errors.Is(err, service.ErrShippingZoneNotFound) {
+		utils.NotFoundResponse(c, "Shipping zone not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to update shipping zone")
+		utils.BadRequestResponse(c, "Failed to update shipping zone", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("zone_id", zone.ID).Msg("Shipping zone updated")
+	utils.SuccessResponse(c, "Shipping zone updated successfully", zone)
+}
+
+func (h *ShippingHandler) DeleteZone(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid shipping zone ID", err)
+		return
+	}
+
+	err = h.shippingService.DeleteZone(uint(id))
+	if errors.Is(err, service.ErrShippingZoneNotFound) {
+		utils.NotFoundResponse(c, "Shipping zone not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to delete shipping zone")
+		utils.InternalServerErrorResponse(c, "Failed to delete shipping zone", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Shipping zone deleted successfully", nil)
+}
+
+func (h *ShippingHandler) CreateMethod(c *gin.Context) {
+	var req dto.ShippingMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	method, err := h.shippingService.CreateMethod(&req)
+	if errors.Is(err, service.ErrShippingZoneNotFound) {
+		utils.NotFoundResponse(c, "Shipping zone not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to create shipping method")
+		utils.BadRequestResponse(c, "Failed to create shipping method", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("method_id", method.ID).Msg("Shipping method created")
+	utils.CreatedResponse(c, "Shipping method created successfully", method)
+}
+
+func (h *ShippingHandler) UpdateMethod(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid shipping method ID", err)
+		return
+	}
+
+	var req dto.ShippingMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	method, err := h.shippingService.UpdateMethod(uint(id), &req)
+	if errors.Is(err, service.ErrShippingMethodNotFound) {
+		utils.NotFoundResponse(c, "Shipping method not found")
+		return
+	}
+	if errors.Is(err, service.ErrShippingZoneNotFound) {
+		utils.NotFoundResponse(c, "Shipping zone not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to update shipping method")
+		utils.BadRequestResponse(c, "Failed to update shipping method", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("method_id", method.ID).Msg("Shipping method updated")
+	utils.SuccessResponse(c, "Shipping method updated successfully", method)
+}
+
+func (h *ShippingHandler) DeleteMethod(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid shipping method ID", err)
+		return
+	}
+
+	err = h.shippingService.DeleteMethod(uint(id))
+	if errors.Is(err, service.ErrShippingMethodNotFound) {
+		utils.NotFoundResponse(c, "Shipping method not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to delete shipping method")
+		utils.InternalServerErrorResponse(c, "Failed to delete shipping method", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Shipping method deleted successfully", nil)
+}
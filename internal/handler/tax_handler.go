@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type TaxHandler struct {
+	taxService *service.TaxService
+	logger     zerolog.Logger
+}
+
+func NewTaxHandler(taxService *service.TaxService, logger zerolog.Logger) *TaxHandler {
+	return &TaxHandler{
+		taxService: taxService,
+		logger:     logger,
+	}
+}
+
+func (h *TaxHandler) ListTaxRates(c *gin.Context) {
+	var req dto.ListTaxRatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	rates, meta, err := h.taxService.ListTaxRates(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list tax rates")
+		utils.InternalServerErrorResponse(c, "Failed to list tax rates", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Tax rates retrieved successfully", rates, meta)
+}
+
+func (h *TaxHandler) CreateTaxRate(c *gin.Context) {
+	var req dto.TaxRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	rate, err := h.taxService.CreateTaxRate(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to create tax rate")
+		utils.BadRequestResponse(c, "Failed to create tax rate", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("tax_rate_id", rate.ID).Msg("Tax rate created")
+	utils.CreatedResponse(c, "Tax rate created successfully", rate)
+}
+
+func (h *TaxHandler) UpdateTaxRate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tax rate ID", err)
+		return
+	}
+
+	var req dto.TaxRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	rate, err := h.taxService.UpdateTaxRate(uint(id), &req)
+	if errors.Is(err, service.ErrTaxRateNotFound) {
+		utils.NotFoundResponse(c, "Tax rate not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to update tax rate")
+		utils.BadRequestResponse(c, "Failed to update tax rate", err)
+		return
+	}
+
+	h.logger.Info().Uint("admin_id", c.GetUint("user_id")).Uint("tax_rate_id", rate.ID).Msg("Tax rate updated")
+	utils.SuccessResponse(c, "Tax rate updated successfully", rate)
+}
+
+func (h *TaxHandler) DeleteTaxRate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tax rate ID", err)
+		return
+	}
+
+	err = h.taxService.DeleteTaxRate(uint(id))
+	if errors.Is(err, service.ErrTaxRateNotFound) {
+		utils.NotFoundResponse(c, "Tax rate not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to delete tax rate")
+		utils.InternalServerErrorResponse(c, "Failed to delete tax rate", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Tax rate deleted successfully", nil)
+}
@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"errors"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"github.com/programmerjide/ecommerce/internal/dto"
 	"github.com/programmerjide/ecommerce/internal/service"
@@ -51,3 +54,180 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 	utils.SuccessResponse(c, "User profile updated successfully", updatedProfile)
 }
+
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	err := h.UserService.ChangePassword(userID, c.GetUint("session_id"), &req, clientInfo(c))
+	var policyErr *service.PasswordPolicyError
+	var lockedErr *service.LockedError
+	switch {
+	case errors.As(err, &lockedErr):
+		utils.TooManyRequestsResponse(c, "Too many failed attempts, please try again later", lockedErr.RetryAfter)
+		return
+	case errors.As(err, &policyErr):
+		utils.ValidationErrorResponse(c, "Password does not meet the password policy", policyErr.Violations)
+		return
+	case errors.Is(err, service.ErrInvalidCredentials):
+		utils.BadRequestResponse(c, "Current password is incorrect", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to change password")
+		utils.InternalServerErrorResponse(c, "Failed to change password", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Password changed successfully", nil)
+}
+
+func (h *UserHandler) ChangeEmail(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	var req dto.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	err := h.UserService.RequestEmailChange(userID, &req, clientInfo(c))
+	var lockedErr *service.LockedError
+	switch {
+	case errors.As(err, &lockedErr):
+		utils.TooManyRequestsResponse(c, "Too many failed attempts, please try again later", lockedErr.RetryAfter)
+		return
+	case errors.Is(err, service.ErrInvalidCredentials):
+		utils.BadRequestResponse(c, "Current password is incorrect", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to request email change")
+		utils.BadRequestResponse(c, "Failed to request email change", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "A confirmation link has been sent to the new email address", nil)
+}
+
+func (h *UserHandler) ConfirmEmailChange(c *gin.Context) {
+	var req dto.ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	profile, err := h.UserService.ConfirmEmailChange(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to confirm email change")
+		utils.BadRequestResponse(c, "Failed to confirm email change", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Email changed successfully", profile)
+}
+
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	var req dto.ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	users, meta, err := h.UserService.ListUsers(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list users")
+		utils.InternalServerErrorResponse(c, "Failed to list users", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Users retrieved successfully", users, meta)
+}
+
+func (h *UserHandler) GetUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	user, err := h.UserService.GetUser(uint(userID))
+	if err != nil {
+		utils.NotFoundResponse(c, "User not found")
+		return
+	}
+
+	utils.SuccessResponse(c, "User retrieved successfully", user)
+}
+
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	h.setUserActive(c, false)
+}
+
+func (h *UserHandler) ReactivateUser(c *gin.Context) {
+	h.setUserActive(c, true)
+}
+
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	err = h.UserService.DeleteUser(c.GetUint("user_id"), uint(userID))
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		utils.NotFoundResponse(c, "User not found")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to delete user")
+		utils.BadRequestResponse(c, "Failed to delete user", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "User deleted successfully", nil)
+}
+
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	user, err := h.UserService.RestoreUser(uint(userID))
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		utils.NotFoundResponse(c, "Deleted user not found")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to restore user")
+		utils.InternalServerErrorResponse(c, "Failed to restore user", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "User restored successfully", user)
+}
+
+func (h *UserHandler) setUserActive(c *gin.Context, active bool) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	user, err := h.UserService.SetUserActive(c.GetUint("user_id"), uint(userID), active)
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		utils.NotFoundResponse(c, "User not found")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to update account status")
+		utils.BadRequestResponse(c, "Failed to update account status", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Account status updated successfully", user)
+}
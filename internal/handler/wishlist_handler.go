@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type WishlistHandler struct {
+	wishlistService *service.WishlistService
+	logger          zerolog.Logger
+}
+
+func NewWishlistHandler(wishlistService *service.WishlistService, logger zerolog.Logger) *WishlistHandler {
+	return &WishlistHandler{
+		wishlistService: wishlistService,
+		logger:          logger,
+	}
+}
+
+func (h *WishlistHandler) ListWishlists(c *gin.Context) {
+	wishlists, err := h.wishlistService.ListWishlists(c.GetUint("user_id"))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list wishlists")
+		utils.InternalServerErrorResponse(c, "Failed to list wishlists", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Wishlists retrieved successfully", wishlists)
+}
+
+func (h *WishlistHandler) GetWishlist(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid wishlist ID", err)
+		return
+	}
+
+	wishlist, err := h.wishlistService.GetWishlist(c.GetUint("user_id"), uint(wishlistID))
+	if err != nil {
+		utils.NotFoundResponse(c, "Wishlist not found")
+		return
+	}
+
+	utils.SuccessResponse(c, "Wishlist retrieved successfully", wishlist)
+}
+
+func (h *WishlistHandler) CreateWishlist(c *gin.Context) {
+	var req dto.WishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	wishlist, err := h.wishlistService.CreateWishlist(c.GetUint("user_id"), &req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to create wishlist")
+		utils.InternalServerErrorResponse(c, "Failed to create wishlist", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Wishlist created successfully", wishlist)
+}
+
+func (h *WishlistHandler) RenameWishlist(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid wishlist ID", err)
+		return
+	}
+
+	var req dto.WishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	wishlist, err := h.wishlistService.RenameWishlist(c.GetUint("user_id"), uint(wishlistID), &req)
+	switch {
+	case errors.Is(err, service.ErrWishlistNotFound):
+		utils.NotFoundResponse(c, "Wishlist not found")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to rename wishlist")
+		utils.InternalServerErrorResponse(c, "Failed to rename wishlist", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Wishlist updated successfully", wishlist)
+}
+
+func (h *WishlistHandler) DeleteWishlist(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid wishlist ID", err)
+		return
+	}
+
+	err = h.wishlistService.DeleteWishlist(c.GetUint("user_id"), uint(wishlistID))
+	switch {
+	case errors.Is(err, service.ErrWishlistNotFound):
+		utils.NotFoundResponse(c, "Wishlist not found")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to delete wishlist")
+		utils.InternalServerErrorResponse(c, "Failed to delete wishlist", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Wishlist deleted successfully", nil)
+}
+
+func (h *WishlistHandler) AddItem(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid wishlist ID", err)
+		return
+	}
+
+	var req dto.AddWishlistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	wishlist, err := h.wishlistService.AddItem(c.GetUint("user_id"), uint(wishlistID), &req)
+	switch {
+	case errors.Is(err, service.ErrWishlistNotFound):
+		utils.NotFoundResponse(c, "Wishlist not found")
+		return
+	case errors.Is(err, service.ErrProductUnavailable):
+		utils.BadRequestResponse(c, "Product is not available", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to add item to wishlist")
+		utils.InternalServerErrorResponse(c, "Failed to add item to wishlist", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Item added to wishlist", wishlist)
+}
+
+func (h *WishlistHandler) RemoveItem(c *gin.Context) {
+	wishlistID, itemID, ok := wishlistItemParams(c)
+	if !ok {
+		return
+	}
+
+	wishlist, err := h.wishlistService.RemoveItem(c.GetUint("user_id"), wishlistID, itemID)
+	switch {
+	case errors.Is(err, service.ErrWishlistNotFound):
+		utils.NotFoundResponse(c, "Wishlist not found")
+		return
+	case errors.Is(err, service.ErrWishlistItemNotFound):
+		utils.NotFoundResponse(c, "Wishlist item not found")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to remove wishlist item")
+		utils.InternalServerErrorResponse(c, "Failed to remove wishlist item", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Item removed from wishlist", wishlist)
+}
+
+func (h *WishlistHandler) MoveToCart(c *gin.Context) {
+	wishlistID, itemID, ok := wishlistItemParams(c)
+	if !ok {
+		return
+	}
+
+	// The quantity is optional, so an empty body is accepted
+	var req dto.MoveToCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.BadRequestResponse(c, "Invalid request payload", err)
+		return
+	}
+
+	cart, err := h.wishlistService.MoveToCart(c.GetUint("user_id"), wishlistID, itemID, &req)
+	switch {
+	case errors.Is(err, service.ErrWishlistNotFound):
+		utils.NotFoundResponse(c, "Wishlist not found")
+		return
+	case errors.Is(err, service.ErrWishlistItemNotFound):
+		utils.NotFoundResponse(c, "Wishlist item not found")
+		return
+	case errors.Is(err, service.ErrProductUnavailable):
+		utils.BadRequestResponse(c, "Product is not available", err)
+		return
+	case errors.Is(err, service.ErrInsufficientStock):
+		utils.ErrorResponse(c, http.StatusConflict, "Not enough stock", err)
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to move wishlist item to cart")
+		utils.InternalServerErrorResponse(c, "Failed to move wishlist item to cart", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Item moved to cart", cart)
+}
+
+func (h *WishlistHandler) ShareWishlist(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid wishlist ID", err)
+		return
+	}
+
+	wishlist, err := h.wishlistService.ShareWishlist(c.GetUint("user_id"), uint(wishlistID))
+	switch {
+	case errors.Is(err, service.ErrWishlistNotFound):
+		utils.NotFoundResponse(c, "Wishlist not found")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to share wishlist")
+		utils.InternalServerErrorResponse(c, "Failed to share wishlist", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Wishlist shared successfully", wishlist)
+}
+
+func (h *WishlistHandler) UnshareWishlist(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid wishlist ID", err)
+		return
+	}
+
+	wishlist, err := h.wishlistService.UnshareWishlist(c.GetUint("user_id"), uint(wishlistID))
+	switch {
+	case errors.Is(err, service.ErrWishlistNotFound):
+		utils.NotFoundResponse(c, "Wishlist not found")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Msg("Failed to unshare wishlist")
+		utils.InternalServerErrorResponse(c, "Failed to unshare wishlist", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Wishlist is private again", wishlist)
+}
+
+// GetSharedWishlist serves the public view of a shared wishlist
+func (h *WishlistHandler) GetSharedWishlist(c *gin.Context) {
+	wishlist, err := h.wishlistService.GetSharedWishlist(c.Param("slug"))
+	if err != nil {
+		utils.NotFoundResponse(c, "Wishlist not found")
+		return
+	}
+
+	utils.SuccessResponse(c, "Wishlist retrieved successfully", wishlist)
+}
+
+func wishlistItemParams(c *gin.Context) (wishlistID, itemID uint, ok bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid wishlist ID", err)
+		return 0, 0, false
+	}
+
+	item, err := strconv.ParseUint(c.Param("item_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid wishlist item ID", err)
+		return 0, 0, false
+	}
+	return uint(id), uint(item), true
+}
@@ -0,0 +1,54 @@
+// Description: This file defines the mailer abstraction used to send emails to users.
+package mailer
+
+import (
+	"github.com/programmerjide/ecommerce/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// Message is a single email. Body is the plain text version; HTMLBody, when set, is
+// sent alongside it for clients that show HTML.
+type Message struct {
+	To       string
+	Subject  string
+	Body     string
+	HTMLBody string
+}
+
+// Mailer sends emails to users
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// New returns the mailer selected by the configured driver
+func New(cfg *config.MailConfig, logger zerolog.Logger) Mailer {
+	switch cfg.Driver {
+	case config.MailDriverSMTP:
+		return NewSMTPMailer(cfg)
+	case config.MailDriverFile:
+		return NewFileMailer(cfg.DropPath, cfg.From)
+	default:
+		return NewLogMailer(logger)
+	}
+}
+
+// LogMailer writes emails to the application log instead of delivering them.
+// It is intended for local development.
+type LogMailer struct {
+	logger zerolog.Logger
+}
+
+func NewLogMailer(logger zerolog.Logger) *LogMailer {
+	return &LogMailer{
+		logger: logger,
+	}
+}
+
+func (m *LogMailer) Send(msg *Message) error {
+	m.logger.Info().
+		Str("to", msg.To).
+		Str("subject", msg.Subject).
+		Str("body", msg.Body).
+		Msg("Email sent")
+	return nil
+}
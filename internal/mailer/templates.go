@@ -0,0 +1,147 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// decimalCommaLocales write amounts with a decimal comma
+var decimalCommaLocales = map[string]bool{"de": true, "es": true, "fr": true, "it": true, "nl": true, "pt": true}
+
+// Templates renders the localized emails kept in the templates directory. Each email is
+// one file per locale, templates/<locale>/<name>.tmpl, defining a "subject", a plain
+// "text" body and an "html" body that is wrapped in the shared layout. The HTML is
+// rendered with html/template, so data is escaped.
+type Templates struct {
+	defaultLocale string
+	text          map[string]*texttemplate.Template
+	html          map[string]*htmltemplate.Template
+}
+
+// NewTemplates parses every template, failing on the first one that does not parse
+func NewTemplates(defaultLocale string) (*Templates, error) {
+	t := &Templates{
+		defaultLocale: defaultLocale,
+		text:          make(map[string]*texttemplate.Template),
+		html:          make(map[string]*htmltemplate.Template),
+	}
+
+	layout, err := templateFiles.ReadFile("templates/layout.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := fs.Glob(templateFiles, "templates/*/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		locale := path.Base(path.Dir(file))
+		name := strings.TrimSuffix(path.Base(file), ".tmpl")
+		content, err := templateFiles.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		funcs := templateFuncs(locale)
+		text, err := texttemplate.New(name).Funcs(funcs).Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("email template %s: %w", file, err)
+		}
+		html, err := htmltemplate.New("layout").Funcs(funcs).Parse(string(layout))
+		if err == nil {
+			html, err = html.Parse(string(content))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("email template %s: %w", file, err)
+		}
+
+		t.text[locale+"/"+name] = text
+		t.html[locale+"/"+name] = html
+	}
+
+	if !t.HasLocale(defaultLocale) {
+		return nil, fmt.Errorf("no email templates for the default locale %q", defaultLocale)
+	}
+	return t, nil
+}
+
+// Locales lists the languages emails are available in
+func (t *Templates) Locales() []string {
+	seen := make(map[string]bool)
+	var locales []string
+	for key := range t.text {
+		locale := strings.SplitN(key, "/", 2)[0]
+		if !seen[locale] {
+			seen[locale] = true
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+func (t *Templates) HasLocale(locale string) bool {
+	for _, available := range t.Locales() {
+		if available == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// Render builds the email name in the given locale, falling back to the default
+// locale when it has not been translated
+func (t *Templates) Render(name, locale string, data interface{}) (*Message, error) {
+	key := locale + "/" + name
+	if _, ok := t.text[key]; !ok {
+		key = t.defaultLocale + "/" + name
+	}
+	text, ok := t.text[key]
+	if !ok {
+		return nil, fmt.Errorf("no email template %q", name)
+	}
+
+	var subject, body, html bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := text.ExecuteTemplate(&body, "text", data); err != nil {
+		return nil, err
+	}
+	if err := t.html[key].ExecuteTemplate(&html, "layout", data); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		// A subject is a single line
+		Subject:  strings.Join(strings.Fields(subject.String()), " "),
+		Body:     strings.TrimSpace(body.String()) + "\n",
+		HTMLBody: html.String(),
+	}, nil
+}
+
+func templateFuncs(locale string) map[string]interface{} {
+	return map[string]interface{}{
+		"locale": func() string {
+			return locale
+		},
+		"money": func(amount float64) string {
+			formatted := fmt.Sprintf("%.2f", amount)
+			if decimalCommaLocales[locale] {
+				formatted = strings.Replace(formatted, ".", ",", 1)
+			}
+			return formatted
+		},
+	}
+}
@@ -4,46 +4,94 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/programmerjide/ecommerce/internal/config"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/service"
 	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
 )
 
-// AuthMiddleware validates JWT tokens
-func AuthMiddleware(cfg *config.JWTConfig) gin.HandlerFunc {
+// AuthMiddleware validates JWT tokens and rejects tokens whose session was signed out.
+// Machine clients may authenticate with an X-API-Key header instead.
+func AuthMiddleware(authService *service.AuthService, apiKeyService *service.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get token from Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			utils.UnauthorizedResponse(c, "Authorization header is required")
+		if !authenticate(c, authService, apiKeyService) {
 			c.Abort()
 			return
 		}
 
-		// Extract token (format: "Bearer <token>")
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			utils.UnauthorizedResponse(c, "Authorization header is required")
+		c.Next()
+	}
+}
+
+// OptionalAuth authenticates requests that carry credentials and lets anonymous
+// requests through, for routes guests may use as well
+func OptionalAuth(authService *service.AuthService, apiKeyService *service.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("X-API-Key") == "" && c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+
+		if !authenticate(c, authService, apiKeyService) {
 			c.Abort()
 			return
 		}
 
-		token := parts[1]
+		c.Next()
+	}
+}
 
-		// Validate token
-		claims, err := utils.ValidateToken(token, cfg.Secret)
+// authenticate stores the identity behind the request credentials in the context.
+// It writes the error response and returns false when the credentials are missing or invalid.
+func authenticate(c *gin.Context, authService *service.AuthService, apiKeyService *service.APIKeyService) bool {
+	if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+		principal, err := apiKeyService.Authenticate(apiKey, c.ClientIP())
 		if err != nil {
-			utils.UnauthorizedResponse(c, "Invalid or expired token")
-			c.Abort()
-			return
+			utils.UnauthorizedResponse(c, "Invalid or expired API key")
+			return false
 		}
 
-		// Store user info in context for later use
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_role", claims.Role)
+		c.Set("user_id", principal.UserID)
+		c.Set("user_email", principal.Email)
+		c.Set("user_role", principal.Role)
+		c.Set("user_permissions", principal.Permissions)
+		c.Set("api_key_id", principal.KeyID)
+		return true
+	}
 
-		c.Next()
+	// Get token from Authorization header
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		utils.UnauthorizedResponse(c, "Authorization header is required")
+		return false
 	}
+
+	// Extract token (format: "Bearer <token>")
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		utils.UnauthorizedResponse(c, "Authorization header is required")
+		return false
+	}
+
+	token := parts[1]
+
+	// Validate token
+	claims, err := authService.ValidateAccessToken(token)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Invalid or expired token")
+		return false
+	}
+
+	// Store user info in context for later use
+	c.Set("user_id", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("user_role", claims.Role)
+	c.Set("user_permissions", claims.Permissions)
+	c.Set("session_id", claims.SessionID)
+	if claims.ImpersonatorID != 0 {
+		c.Set("impersonator_id", claims.ImpersonatorID)
+	}
+	return true
 }
 
 // RoleMiddleware checks if user has required role
@@ -77,6 +125,78 @@ func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 	}
 }
 
-func AdminMiddleware() gin.HandlerFunc {
-	return RoleMiddleware("admin")
+// RequirePermission checks if the user's role grants the permission
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted, _ := c.Get("user_permissions")
+		permissions, _ := granted.([]string)
+
+		for _, p := range permissions {
+			if p == permission {
+				c.Next()
+				return
+			}
+		}
+
+		utils.ForbiddenResponse(c, "You are not authorized to access this resource")
+		c.Abort()
+	}
+}
+
+// BlockImpersonation rejects sensitive actions while staff are impersonating a customer
+func BlockImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetUint("impersonator_id") != 0 {
+			utils.ForbiddenResponse(c, "This action is not allowed while impersonating a customer")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// BlockAPIKey rejects requests authenticated with an API key, for actions that need a signed-in person
+func BlockAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetUint("api_key_id") != 0 {
+			utils.ForbiddenResponse(c, "This action is not available to API keys")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AuditImpersonation logs and stores every request made with an impersonation token
+func AuditImpersonation(authService *service.AuthService, logger zerolog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		impersonatorID := c.GetUint("impersonator_id")
+		if impersonatorID == 0 {
+			c.Next()
+			return
+		}
+
+		c.Next()
+
+		entry := &models.ImpersonationRequestLog{
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.RequestURI(),
+			StatusCode: c.Writer.Status(),
+			IPAddress:  c.ClientIP(),
+		}
+
+		logger.Info().
+			Uint("impersonator_id", impersonatorID).
+			Uint("user_id", c.GetUint("user_id")).
+			Str("method", entry.Method).
+			Str("path", entry.Path).
+			Int("status", entry.StatusCode).
+			Msg("Impersonated request")
+
+		if err := authService.RecordImpersonatedRequest(c.GetUint("session_id"), entry); err != nil {
+			logger.Error().Err(err).Msg("Failed to record impersonated request")
+		}
+	}
 }
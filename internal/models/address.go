@@ -0,0 +1,62 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Address is an entry in a customer's address book
+type Address struct {
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	UserID            uint           `json:"user_id" gorm:"not null;index"`
+	Label             string         `json:"label"`
+	FirstName         string         `json:"first_name" gorm:"not null"`
+	LastName          string         `json:"last_name" gorm:"not null"`
+	Company           string         `json:"company"`
+	Line1             string         `json:"line1" gorm:"not null"`
+	Line2             string         `json:"line2"`
+	City              string         `json:"city" gorm:"not null"`
+	Region            string         `json:"region"`
+	PostalCode        string         `json:"postal_code"`
+	CountryCode       string         `json:"country_code" gorm:"size:2;not null"`
+	Phone             string         `json:"phone"`
+	IsDefaultShipping bool           `json:"is_default_shipping" gorm:"default:false"`
+	IsDefaultBilling  bool           `json:"is_default_billing" gorm:"default:false"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	User User `json:"-"`
+}
+
+// AddressSnapshot is a copy of an address taken at checkout. It is stored on the
+// order itself so later edits to the address book never change past orders.
+type AddressSnapshot struct {
+	Name        string `json:"name"`
+	Company     string `json:"company"`
+	Line1       string `json:"line1"`
+	Line2       string `json:"line2"`
+	City        string `json:"city"`
+	Region      string `json:"region"`
+	PostalCode  string `json:"postal_code"`
+	CountryCode string `json:"country_code"`
+	Phone       string `json:"phone"`
+}
+
+// Snapshot copies the address into the immutable form stored on orders
+func (a *Address) Snapshot() AddressSnapshot {
+	return AddressSnapshot{
+		Name:        strings.TrimSpace(a.FirstName + " " + a.LastName),
+		Company:     a.Company,
+		Line1:       a.Line1,
+		Line2:       a.Line2,
+		City:        a.City,
+		Region:      a.Region,
+		PostalCode:  a.PostalCode,
+		CountryCode: a.CountryCode,
+		Phone:       a.Phone,
+	}
+}
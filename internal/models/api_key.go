@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// APIKeyPrefix starts every API key so leaked keys are easy to recognise
+const APIKeyPrefix = "sk_"
+
+// APIKey authenticates machine-to-machine requests on behalf of its owner.
+// Only a SHA-256 hash of the secret is stored; Prefix identifies the key.
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"uniqueIndex;not null"`
+	KeyHash    string     `json:"-" gorm:"not null"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json;type:jsonb"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CouponType defines what a coupon takes off an order
+type CouponType string
+
+const (
+	CouponTypePercentage   CouponType = "percentage"    // percentage off the eligible lines
+	CouponTypeFixed        CouponType = "fixed"         // fixed amount off the eligible lines
+	CouponTypeFreeShipping CouponType = "free_shipping" // waives the shipping charge
+)
+
+// Coupon is a discount code customers enter on their cart
+type Coupon struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	Code           string         `json:"code" gorm:"not null"` // stored upper-case, unique among live coupons
+	Description    string         `json:"description"`
+	Type           CouponType     `json:"type" gorm:"not null"`
+	Value          float64        `json:"value" gorm:"not null;default:0"`
+	MinimumSpend   float64        `json:"minimum_spend" gorm:"not null;default:0"`
+	MaxUses        *int           `json:"max_uses"`          // nil means unlimited
+	MaxUsesPerUser *int           `json:"max_uses_per_user"` // nil means unlimited
+	UsedCount      int            `json:"used_count" gorm:"not null;default:0"`
+	StartsAt       *time.Time     `json:"starts_at"`
+	EndsAt         *time.Time     `json:"ends_at"`
+	IsActive       bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// A coupon restricted to products or categories only discounts matching lines
+	Products   []Product  `json:"products" gorm:"many2many:coupon_products"`
+	Categories []Category `json:"categories" gorm:"many2many:coupon_categories"`
+}
+
+// DiscountSource tells where a discount line of an order came from
+type DiscountSource string
+
+const (
+	DiscountSourceCoupon    DiscountSource = "coupon"
+	DiscountSourcePromotion DiscountSource = "promotion"
+)
+
+// OrderDiscount is a discount line applied to an order at checkout
+type OrderDiscount struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	OrderID     uint           `json:"order_id" gorm:"not null;index"`
+	Source      DiscountSource `json:"source" gorm:"not null;default:coupon"`
+	CouponID    *uint          `json:"coupon_id" gorm:"index"`
+	PromotionID *uint          `json:"promotion_id" gorm:"index"`
+	Code        string         `json:"code"`
+	Type        string         `json:"type" gorm:"not null"`
+	Description string         `json:"description"`
+	Amount      float64        `json:"amount" gorm:"not null"`
+	CreatedAt   time.Time      `json:"created_at"`
+
+	Order     Order      `json:"-" gorm:"foreignKey:OrderID"`
+	Coupon    *Coupon    `json:"-" gorm:"foreignKey:CouponID"`
+	Promotion *Promotion `json:"-" gorm:"foreignKey:PromotionID"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// Impersonation records a member of staff signing in as a customer
+type Impersonation struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	ActorID   uint       `json:"actor_id" gorm:"not null"`
+	SubjectID uint       `json:"subject_id" gorm:"not null"`
+	SessionID uint       `json:"session_id" gorm:"uniqueIndex;not null"`
+	Reason    string     `json:"reason" gorm:"not null"`
+	IPAddress string     `json:"ip_address"`
+	UserAgent string     `json:"user_agent"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	EndedAt   *time.Time `json:"ended_at"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relationships
+	Actor    User                      `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+	Subject  User                      `json:"subject,omitempty" gorm:"foreignKey:SubjectID"`
+	Requests []ImpersonationRequestLog `json:"requests,omitempty"`
+}
+
+// ImpersonationRequestLog is a single API request made while impersonating
+type ImpersonationRequestLog struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	ImpersonationID uint      `json:"impersonation_id" gorm:"not null;index"`
+	Method          string    `json:"method" gorm:"not null"`
+	Path            string    `json:"path" gorm:"not null"`
+	StatusCode      int       `json:"status_code" gorm:"not null"`
+	IPAddress       string    `json:"ip_address"`
+	CreatedAt       time.Time `json:"created_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// InvoiceType tells an invoice from a credit note
+type InvoiceType string
+
+const (
+	InvoiceTypeInvoice    InvoiceType = "invoice"     // bills a paid order
+	InvoiceTypeCreditNote InvoiceType = "credit_note" // refunds part of an invoiced order
+)
+
+// Invoice is an issued accounting document. Its number comes from a gapless sequence
+// per type, and once issued neither the row nor the stored PDF ever changes.
+type Invoice struct {
+	ID              uint        `json:"id" gorm:"primaryKey"`
+	Number          string      `json:"number" gorm:"uniqueIndex;not null"`
+	Type            InvoiceType `json:"type" gorm:"not null"`
+	OrderID         uint        `json:"order_id" gorm:"not null;index"`
+	ReturnRequestID *uint       `json:"return_request_id" gorm:"uniqueIndex"` // the refunded return, for credit notes
+	Total           float64     `json:"total" gorm:"not null"`
+	TaxTotal        float64     `json:"tax_total" gorm:"not null;default:0"`
+	StorageKey      string      `json:"-" gorm:"not null"`
+	Checksum        string      `json:"checksum" gorm:"not null"` // SHA-256 of the PDF
+	IssuedAt        time.Time   `json:"issued_at" gorm:"not null"`
+	CreatedAt       time.Time   `json:"created_at"`
+
+	Order Order `json:"-" gorm:"foreignKey:OrderID"`
+}
+
+// DocumentSequence hands out consecutive numbers for one kind of document
+type DocumentSequence struct {
+	Name      string `gorm:"primaryKey"`
+	LastValue int    `gorm:"not null;default:0"`
+}
@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+// NotificationType is a kind of email sent to customers from a template
+type NotificationType string
+
+const (
+	NotificationOrderConfirmation    NotificationType = "order_confirmation"
+	NotificationOrderShipped         NotificationType = "order_shipped"
+	NotificationRefundIssued         NotificationType = "refund_issued"
+	NotificationReturnApproved       NotificationType = "return_approved"
+	NotificationReturnRejected       NotificationType = "return_rejected"
+	NotificationPasswordReset        NotificationType = "password_reset"
+	NotificationPasswordChanged      NotificationType = "password_changed"
+	NotificationAccountLocked        NotificationType = "account_locked"
+	NotificationConfirmEmail         NotificationType = "confirm_email"
+	NotificationEmailChangeRequested NotificationType = "email_change_requested"
+	NotificationEmailChanged         NotificationType = "email_changed"
+	NotificationGuestConversion      NotificationType = "guest_conversion"
+	NotificationErasureReceived      NotificationType = "erasure_received"
+	NotificationErasureRejected      NotificationType = "erasure_rejected"
+	NotificationErasureCompleted     NotificationType = "erasure_completed"
+)
+
+// NotificationTypes lists every notification in the order preferences are shown
+var NotificationTypes = []NotificationType{
+	NotificationOrderConfirmation,
+	NotificationOrderShipped,
+	NotificationRefundIssued,
+	NotificationReturnApproved,
+	NotificationReturnRejected,
+	NotificationPasswordReset,
+	NotificationPasswordChanged,
+	NotificationAccountLocked,
+	NotificationConfirmEmail,
+	NotificationEmailChangeRequested,
+	NotificationEmailChanged,
+	NotificationGuestConversion,
+	NotificationErasureReceived,
+	NotificationErasureRejected,
+	NotificationErasureCompleted,
+}
+
+// Optional reports whether users may turn the notification off. Only order updates
+// are; security, account and privacy emails are always sent.
+func (t NotificationType) Optional() bool {
+	switch t {
+	case NotificationOrderConfirmation, NotificationOrderShipped, NotificationRefundIssued,
+		NotificationReturnApproved, NotificationReturnRejected:
+		return true
+	}
+	return false
+}
+
+// NotificationPreference records that a user turned a notification on or off. Without
+// a row the notification is sent.
+type NotificationPreference struct {
+	ID        uint             `json:"id" gorm:"primaryKey"`
+	UserID    uint             `json:"user_id" gorm:"not null;uniqueIndex:idx_notification_preferences_user_type"`
+	Type      NotificationType `json:"type" gorm:"not null;uniqueIndex:idx_notification_preferences_user_type"`
+	Enabled   bool             `json:"enabled" gorm:"not null"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// OutboxEmailStatus is where an email is in delivery
+type OutboxEmailStatus string
+
+const (
+	OutboxEmailPending OutboxEmailStatus = "pending" // waiting to be (re)tried
+	OutboxEmailSent    OutboxEmailStatus = "sent"    // delivered to the mail server
+	OutboxEmailFailed  OutboxEmailStatus = "failed"  // gave up after the last attempt
+)
+
+// OutboxEmail is an email kept until it has been delivered, so that sends that fail
+// are retried. Bodies are cleared once sent since they may hold one-time links.
+type OutboxEmail struct {
+	ID            uint              `json:"id" gorm:"primaryKey"`
+	Recipient     string            `json:"recipient" gorm:"not null;index"`
+	Subject       string            `json:"subject" gorm:"not null"`
+	TextBody      string            `json:"-"`
+	HTMLBody      string            `json:"-"`
+	Status        OutboxEmailStatus `json:"status" gorm:"not null;default:pending"`
+	Attempts      int               `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time         `json:"next_attempt_at" gorm:"not null"`
+	LastError     string            `json:"last_error"`
+	SentAt        *time.Time        `json:"sent_at"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+func (OutboxEmail) TableName() string {
+	return "email_outbox"
+}
@@ -10,15 +10,42 @@ import (
 // Order represents a customer's order in the e-commerce system
 type Order struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
-	UserID      uint           `json:"user_id" gorm:"not null"`
+	UserID      *uint          `json:"user_id" gorm:"index"` // nil for guest orders
+	OrderNumber string         `json:"order_number" gorm:"uniqueIndex;not null"`
+	Email       string         `json:"email" gorm:"not null;index"`
 	Status      OrderStatus    `json:"status" gorm:"default:'pending'"`
 	TotalAmount float64        `json:"total_amount" gorm:"not null"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// DiscountTotal is the sum of the discount lines, already taken off TotalAmount
+	DiscountTotal float64         `json:"discount_total" gorm:"not null;default:0"`
+	Discounts     []OrderDiscount `json:"discounts" gorm:"foreignKey:OrderID"`
+
+	// TaxTotal is the tax charged on the order. It is added to TotalAmount unless the
+	// prices included tax when the order was placed.
+	TaxTotal         float64        `json:"tax_total" gorm:"not null;default:0"`
+	PricesIncludeTax bool           `json:"prices_include_tax" gorm:"not null;default:false"`
+	TaxLines         []OrderTaxLine `json:"tax_lines" gorm:"foreignKey:OrderID"`
+
+	// ShippingCost is the charge of the chosen method, included in TotalAmount. A waived
+	// charge is kept here and offset by a discount line.
+	ShippingMethodID   *uint           `json:"shipping_method_id"`
+	ShippingMethodName string          `json:"shipping_method_name"`
+	ShippingCost       float64         `json:"shipping_cost" gorm:"not null;default:0"`
+	ShippingMethod     *ShippingMethod `json:"-" gorm:"foreignKey:ShippingMethodID"`
+
+	// Addresses are copied onto the order at checkout and never change afterwards
+	ShippingAddress AddressSnapshot `json:"shipping_address" gorm:"embedded;embeddedPrefix:shipping_"`
+	BillingAddress  AddressSnapshot `json:"billing_address" gorm:"embedded;embeddedPrefix:billing_"`
+
 	User       User        `json:"user" gorm:"foreignKey:UserID"`         // ✅ Included
 	OrderItems []OrderItem `json:"order_items" gorm:"foreignKey:OrderID"` // ✅ Included
+	Shipments  []Shipment  `json:"shipments" gorm:"foreignKey:OrderID"`
+
+	// RefundedTotal is what has been paid back for returned items
+	RefundedTotal float64 `json:"refunded_total" gorm:"not null;default:0"`
 }
 
 // OrderStatus defines the status of an order
@@ -27,12 +54,14 @@ type OrderStatus string
 const (
 	OrderStatusPending   OrderStatus = "pending"   // default status
 	OrderStatusPaid      OrderStatus = "paid"      // payment received
-	OrderStatusShipped   OrderStatus = "shipped"   // order shipped
+	OrderStatusShipped   OrderStatus = "shipped"   // every item shipped
 	OrderStatusDelivered OrderStatus = "delivered" // order delivered
 	OrderStatusCancelled OrderStatus = "cancelled" // order cancelled
 	OrderStatusRefunded  OrderStatus = "refunded"  // order refunded
-	OrderStatusConfirmed OrderStatus = "confirmed" // order confirmed
+	OrderStatusConfirmed OrderStatus = "confirmed" // payment confirmed, ready to ship
 	OrderStatusFailed    OrderStatus = "failed"    // payment failed
+
+	OrderStatusPartiallyShipped OrderStatus = "partially_shipped" // some items shipped
 )
 
 // OrderItem represents an item in a customer's order
@@ -42,24 +71,28 @@ type OrderItem struct {
 	ProductID uint           `json:"product_id" gorm:"not null"`
 	Quantity  int            `json:"quantity" gorm:"not null"`
 	Price     float64        `json:"unit_price" gorm:"not null"`
+	TaxAmount float64        `json:"tax_amount" gorm:"not null;default:0"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
-	Order   Order   `json:"-" gorm:"foreignKey:OrderID"`         // ✅ Excluded
-	Product Product `json:"product" gorm:"foreignKey:ProductID"` // ✅ Included
+	Order    Order          `json:"-" gorm:"foreignKey:OrderID"`         // ✅ Excluded
+	Product  Product        `json:"product" gorm:"foreignKey:ProductID"` // ✅ Included
+	TaxLines []OrderTaxLine `json:"tax_lines" gorm:"foreignKey:OrderItemID"`
 }
 
-// Cart represents a shopping cart for a user
+// Cart represents a shopping cart for a user, or for a guest holding its cart token
 type Cart struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
-	UserID    uint           `json:"user_id" gorm:"not null;uniqueIndex"`
+	UserID    *uint          `json:"user_id" gorm:"uniqueIndex"` // nil for guest carts
+	CouponID  *uint          `json:"coupon_id"`                  // coupon applied to the cart
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationship
 	CartItems []CartItem `json:"cart_items" gorm:"foreignKey:CartID"` // ✅ Included
+	Coupon    *Coupon    `json:"coupon" gorm:"foreignKey:CouponID"`
 }
 
 // CartItem represents an item in a shopping cart
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// ErasureRequest is a customer's request to have their personal data erased.
+// Requests are reviewed by staff before the account is anonymized.
+type ErasureRequest struct {
+	ID          uint                 `json:"id" gorm:"primaryKey"`
+	UserID      uint                 `json:"user_id" gorm:"not null;index"`
+	Status      ErasureRequestStatus `json:"status" gorm:"type:varchar(20);default:pending"`
+	Reason      string               `json:"reason"`
+	ReviewerID  *uint                `json:"reviewer_id"`
+	ReviewNote  string               `json:"review_note"`
+	ReviewedAt  *time.Time           `json:"reviewed_at"`
+	CompletedAt *time.Time           `json:"completed_at"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// ErasureRequestStatus defines the review state of an erasure request
+type ErasureRequestStatus string
+
+const (
+	ErasureRequestStatusPending   ErasureRequestStatus = "pending"   // awaiting review
+	ErasureRequestStatusRejected  ErasureRequestStatus = "rejected"  // declined by staff
+	ErasureRequestStatusCancelled ErasureRequestStatus = "cancelled" // withdrawn by the customer
+	ErasureRequestStatusCompleted ErasureRequestStatus = "completed" // account anonymized
+)
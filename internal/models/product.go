@@ -25,22 +25,83 @@ type Product struct {
 	CategoryID  uint           `json:"category_id" gorm:"not null"`
 	Name        string         `json:"name" gorm:"not null"`
 	Description string         `json:"description"`
-	Price       float64        `json:"price" gorm:"not null"`
+	Price       float64        `json:"price" gorm:"not null"` // regular price
 	Stock       int            `json:"stock" gorm:"default:0"`
 	SKU         string         `json:"sku" gorm:"uniqueIndex;not null"`
 	IsActive    bool           `json:"is_active" gorm:"default:true"`
-	IsOnSale    bool           `json:"is_on_sale" gorm:"default:true"`
+	IsOnSale    bool           `json:"is_on_sale" gorm:"default:false"` // denormalized by the sale scheduler; prices follow SaleActive
 	IsFeatured  bool           `json:"is_featured" gorm:"default:false"`
 	Rating      float64        `json:"rating" gorm:"default:0"`
+	TaxClass    string         `json:"tax_class" gorm:"not null;default:standard"` // picks the tax rates charged on the product
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"` // ✅ Proper soft deletes
 
+	// A sale charges SalePrice between SaleStartsAt and SaleEndsAt; open ends are unbounded
+	CompareAtPrice *float64   `json:"compare_at_price"` // "was" price shown next to the price
+	SalePrice      *float64   `json:"sale_price"`
+	SaleStartsAt   *time.Time `json:"sale_starts_at"`
+	SaleEndsAt     *time.Time `json:"sale_ends_at"`
+
+	// Shipping weight in kg and package dimensions in cm; zero when unknown
+	Weight float64 `json:"weight" gorm:"not null;default:0"`
+	Length float64 `json:"length" gorm:"not null;default:0"`
+	Width  float64 `json:"width" gorm:"not null;default:0"`
+	Height float64 `json:"height" gorm:"not null;default:0"`
+
 	Category   Category       `json:"category" gorm:"foreignKey:CategoryID"` // ✅ Included
 	Images     []ProductImage `json:"images" gorm:"foreignKey:ProductID"`    // ✅ Included
-	Tags       []string       `json:"tags" gorm:"foreignKey:ProductID"`
-	OrderItems []OrderItem    `json:"-" gorm:"foreignKey:ProductID"` // ✅ Excluded
-	CartItems  []CartItem     `json:"-" gorm:"foreignKey:ProductID"` // ✅ Excluded
+	Tags       []string       `json:"tags" gorm:"-"`                         // not persisted yet
+	OrderItems []OrderItem    `json:"-" gorm:"foreignKey:ProductID"`         // ✅ Excluded
+	CartItems  []CartItem     `json:"-" gorm:"foreignKey:ProductID"`         // ✅ Excluded
+}
+
+// SaleActive reports whether the product's sale window is open at the given time
+func (p *Product) SaleActive(now time.Time) bool {
+	return p.SalePrice != nil &&
+		(p.SaleStartsAt == nil || !now.Before(*p.SaleStartsAt)) &&
+		(p.SaleEndsAt == nil || now.Before(*p.SaleEndsAt))
+}
+
+// volumetricDivisor converts a package volume in cm³ into the weight carriers charge for it
+const volumetricDivisor = 5000
+
+// ShippingWeight is the weight one unit is charged at: its actual weight, or the
+// volumetric weight of its package when that is higher
+func (p *Product) ShippingWeight() float64 {
+	return max(p.Weight, p.Length*p.Width*p.Height/volumetricDivisor)
+}
+
+// CurrentPrice is the price customers pay: the sale price while the sale window is open
+func (p *Product) CurrentPrice() float64 {
+	if p.SaleActive(time.Now()) {
+		return *p.SalePrice
+	}
+	return p.Price
+}
+
+// Reasons a product's price history is written
+const (
+	PriceChangeCreated     = "created"
+	PriceChangeUpdated     = "updated"
+	PriceChangeSaleStarted = "sale_started"
+	PriceChangeSaleEnded   = "sale_ended"
+)
+
+// ProductPriceHistory records the pricing of a product from CreatedAt until the next
+// entry. ChangedByID is nil for changes made by the sale scheduler.
+type ProductPriceHistory struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ProductID    uint      `json:"product_id" gorm:"not null;index"`
+	Price        float64   `json:"price" gorm:"not null"`
+	SalePrice    *float64  `json:"sale_price"`
+	CurrentPrice float64   `json:"current_price" gorm:"not null"` // what customers paid
+	Reason       string    `json:"reason" gorm:"not null"`
+	ChangedByID  *uint     `json:"changed_by_id"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+
+	Product   Product `json:"-" gorm:"foreignKey:ProductID"`
+	ChangedBy *User   `json:"-" gorm:"foreignKey:ChangedByID"`
 }
 
 // ProductImage represents an image associated with a product
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PromotionType defines how an automatic promotion discounts the cart
+type PromotionType string
+
+const (
+	PromotionTypeBuyXGetY           PromotionType = "buy_x_get_y"         // every X bought gives Y more at a discount
+	PromotionTypeQuantityTier       PromotionType = "quantity_tier"       // percentage off that grows with the quantity bought
+	PromotionTypeCategoryPercentage PromotionType = "category_percentage" // percentage off whole categories
+	PromotionTypeBundle             PromotionType = "bundle"              // a set of products sold together at a fixed price
+)
+
+// Promotion is a discount applied automatically to every cart it matches. Promotions are
+// evaluated by descending priority; a promotion that is not stackable only fires when no
+// other promotion has, and stops the promotions after it.
+type Promotion struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"not null"`
+	Description string         `json:"description"`
+	Type        PromotionType  `json:"type" gorm:"not null"`
+	Priority    int            `json:"priority" gorm:"not null;default:0"`
+	Stackable   bool           `json:"stackable" gorm:"not null;default:true"`
+	IsActive    bool           `json:"is_active" gorm:"default:true"`
+	StartsAt    *time.Time     `json:"starts_at"`
+	EndsAt      *time.Time     `json:"ends_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Rule parameters, which ones are used depends on the type
+	BuyQuantity int     `json:"buy_quantity"` // buy_x_get_y
+	GetQuantity int     `json:"get_quantity"` // buy_x_get_y
+	Percentage  float64 `json:"percentage"`   // buy_x_get_y and category_percentage
+	BundlePrice float64 `json:"bundle_price"` // bundle
+
+	Tiers []PromotionTier `json:"tiers" gorm:"foreignKey:PromotionID"`
+
+	// Products and categories the promotion applies to. Without any it applies to the
+	// whole cart; a bundle lists exactly the products it is made of.
+	Products   []Product  `json:"products" gorm:"many2many:promotion_products"`
+	Categories []Category `json:"categories" gorm:"many2many:promotion_categories"`
+}
+
+// PromotionTier is a step of a quantity_tier promotion
+type PromotionTier struct {
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	PromotionID uint    `json:"promotion_id" gorm:"not null;index"`
+	MinQuantity int     `json:"min_quantity" gorm:"not null"`
+	Percentage  float64 `json:"percentage" gorm:"not null"`
+}
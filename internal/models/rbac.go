@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// Role is a named set of permissions assigned to users through User.Role
+type Role struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"`
+	Description string    `json:"description"`
+	IsSystem    bool      `json:"is_system" gorm:"default:false"` // built-in roles cannot be deleted
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions"`
+}
+
+// Permission is a single capability in "resource:action" form, e.g. products:write
+type Permission struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Permissions checked by the API
+const (
+	PermissionProductsWrite    = "products:write"
+	PermissionCategoriesWrite  = "categories:write"
+	PermissionOrdersRead       = "orders:read"
+	PermissionOrdersWrite      = "orders:write"
+	PermissionOrdersRefund     = "orders:refund"
+	PermissionOrdersFulfil     = "orders:fulfil"
+	PermissionUsersRead        = "users:read"
+	PermissionUsersWrite       = "users:write"
+	PermissionUsersImpersonate = "users:impersonate"
+	PermissionRolesManage      = "roles:manage"
+	PermissionPromotionsManage = "promotions:manage"
+	PermissionTaxManage        = "tax:manage"
+	PermissionShippingManage   = "shipping:manage"
+)
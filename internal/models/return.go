@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// ReturnStatus is where a return request is in the returns workflow
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested ReturnStatus = "requested" // waiting for staff
+	ReturnStatusApproved  ReturnStatus = "approved"  // customer may send the goods back
+	ReturnStatusRejected  ReturnStatus = "rejected"  // closed without a refund
+	ReturnStatusReceived  ReturnStatus = "received"  // goods are back in the warehouse
+	ReturnStatusRefunded  ReturnStatus = "refunded"  // money paid back, closed
+)
+
+// ReturnReason is why the customer sends an item back
+type ReturnReason string
+
+const (
+	ReturnReasonDamaged        ReturnReason = "damaged"
+	ReturnReasonDefective      ReturnReason = "defective"
+	ReturnReasonWrongItem      ReturnReason = "wrong_item"
+	ReturnReasonNotAsDescribed ReturnReason = "not_as_described"
+	ReturnReasonNoLongerNeeded ReturnReason = "no_longer_needed"
+	ReturnReasonOther          ReturnReason = "other"
+)
+
+// ReturnRequest (RMA) asks to send back some of the items of an order for a refund
+type ReturnRequest struct {
+	ID           uint         `json:"id" gorm:"primaryKey"`
+	OrderID      uint         `json:"order_id" gorm:"not null;index"`
+	UserID       *uint        `json:"user_id" gorm:"index"`
+	Status       ReturnStatus `json:"status" gorm:"not null;default:requested"`
+	CustomerNote string       `json:"customer_note"`
+	Restocked    bool         `json:"restocked" gorm:"not null;default:false"`
+	RefundAmount float64      `json:"refund_amount" gorm:"not null;default:0"` // set when refunded
+	RefundedAt   *time.Time   `json:"refunded_at"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+
+	Order  Order         `json:"-" gorm:"foreignKey:OrderID"`
+	Items  []ReturnItem  `json:"items" gorm:"foreignKey:ReturnRequestID"`
+	Events []ReturnEvent `json:"events" gorm:"foreignKey:ReturnRequestID"`
+}
+
+// ReturnItem is a quantity of an order item being returned and why
+type ReturnItem struct {
+	ID              uint         `json:"id" gorm:"primaryKey"`
+	ReturnRequestID uint         `json:"return_request_id" gorm:"not null;index"`
+	OrderItemID     uint         `json:"order_item_id" gorm:"not null;index"`
+	Quantity        int          `json:"quantity" gorm:"not null"`
+	Reason          ReturnReason `json:"reason" gorm:"not null"`
+	Note            string       `json:"note"`
+
+	OrderItem OrderItem `json:"-" gorm:"foreignKey:OrderItemID"`
+}
+
+// ReturnEvent is an entry of a return's audit trail: who moved it to which status, and why
+type ReturnEvent struct {
+	ID              uint         `json:"id" gorm:"primaryKey"`
+	ReturnRequestID uint         `json:"return_request_id" gorm:"not null;index"`
+	Status          ReturnStatus `json:"status" gorm:"not null"`
+	ActorID         *uint        `json:"actor_id"` // nil when the customer acted
+	Note            string       `json:"note"`
+	CreatedAt       time.Time    `json:"created_at"`
+
+	Actor *User `json:"-" gorm:"foreignKey:ActorID"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// ShipmentStatus is where a shipment is on its way to the customer
+type ShipmentStatus string
+
+const (
+	ShipmentStatusShipped   ShipmentStatus = "shipped"   // handed to the carrier
+	ShipmentStatusDelivered ShipmentStatus = "delivered" // received by the customer
+)
+
+// Shipment is a parcel sent for an order. Orders shipped in parts have several, each
+// carrying some of the ordered quantities.
+type Shipment struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	OrderID        uint           `json:"order_id" gorm:"not null;index"`
+	Carrier        string         `json:"carrier" gorm:"not null"`
+	TrackingNumber string         `json:"tracking_number"`
+	TrackingURL    string         `json:"tracking_url"`
+	Status         ShipmentStatus `json:"status" gorm:"not null;default:shipped"`
+	ShippedAt      time.Time      `json:"shipped_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+	CreatedByID    *uint          `json:"created_by_id"` // staff member who recorded the shipment
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+
+	Order Order          `json:"-" gorm:"foreignKey:OrderID"`
+	Items []ShipmentItem `json:"items" gorm:"foreignKey:ShipmentID"`
+}
+
+// ShipmentItem is the quantity of an order item packed in a shipment
+type ShipmentItem struct {
+	ID          uint `json:"id" gorm:"primaryKey"`
+	ShipmentID  uint `json:"shipment_id" gorm:"not null;index"`
+	OrderItemID uint `json:"order_item_id" gorm:"not null;index"`
+	Quantity    int  `json:"quantity" gorm:"not null"`
+
+	OrderItem OrderItem `json:"-" gorm:"foreignKey:OrderItemID"`
+}
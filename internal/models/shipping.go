@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShippingMethodType defines how a shipping method is charged
+type ShippingMethodType string
+
+const (
+	ShippingMethodFlat          ShippingMethodType = "flat"            // the same rate for every order
+	ShippingMethodWeightBased   ShippingMethodType = "weight_based"    // rate plus a charge per kilogram
+	ShippingMethodFreeOverTotal ShippingMethodType = "free_over_total" // rate, waived from a spend threshold
+)
+
+// ShippingZone groups the countries and regions that share the same shipping methods
+type ShippingZone struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Name      string         `json:"name" gorm:"not null"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Locations []ShippingZoneLocation `json:"locations" gorm:"foreignKey:ZoneID"`
+	Methods   []ShippingMethod       `json:"methods" gorm:"foreignKey:ZoneID"`
+}
+
+// ShippingZoneLocation is a country, or a region of it, covered by a zone. An address
+// belongs to the zone naming its region before one covering the whole country.
+type ShippingZoneLocation struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	ZoneID      uint   `json:"zone_id" gorm:"not null;index"`
+	CountryCode string `json:"country_code" gorm:"size:2;not null"`
+	Region      string `json:"region" gorm:"not null;default:''"` // empty for the whole country
+}
+
+// ShippingMethod is a way of shipping to a zone and how it is charged
+type ShippingMethod struct {
+	ID            uint               `json:"id" gorm:"primaryKey"`
+	ZoneID        uint               `json:"zone_id" gorm:"not null;index"`
+	Name          string             `json:"name" gorm:"not null"`
+	Type          ShippingMethodType `json:"type" gorm:"not null"`
+	Rate          float64            `json:"rate" gorm:"not null;default:0"`        // flat charge, or base charge of weight-based methods
+	RatePerKg     float64            `json:"rate_per_kg" gorm:"not null;default:0"` // weight-based methods only
+	FreeThreshold *float64           `json:"free_threshold"`                        // free_over_total methods only
+	MaxWeight     *float64           `json:"max_weight"`                            // kg; heavier orders cannot use the method
+	IsActive      bool               `json:"is_active" gorm:"default:true"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt     `json:"-" gorm:"index"`
+
+	Zone ShippingZone `json:"-" gorm:"foreignKey:ZoneID"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// StockMovementReason tells why a product's stock changed
+type StockMovementReason string
+
+const (
+	StockMovementSale   StockMovementReason = "sale"   // units sold at checkout
+	StockMovementReturn StockMovementReason = "return" // returned units put back on sale
+)
+
+// StockMovement is an entry of the stock ledger. Every change to Product.Stock made by
+// the shop is recorded with what caused it; Quantity is negative for units leaving stock.
+type StockMovement struct {
+	ID              uint                `json:"id" gorm:"primaryKey"`
+	ProductID       uint                `json:"product_id" gorm:"not null;index"`
+	Quantity        int                 `json:"quantity" gorm:"not null"`
+	Reason          StockMovementReason `json:"reason" gorm:"not null"`
+	OrderID         *uint               `json:"order_id" gorm:"index"`
+	ReturnRequestID *uint               `json:"return_request_id" gorm:"index"`
+	ActorID         *uint               `json:"actor_id"` // staff member, nil for customer actions
+	CreatedAt       time.Time           `json:"created_at"`
+
+	Product Product `json:"-" gorm:"foreignKey:ProductID"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// TaxClassStandard is the tax class of products that do not name one
+const TaxClassStandard = "standard"
+
+// TaxRate is a rate charged on products of a tax class in a country, or in one region
+// of it. Rates of the whole country and of the region all apply, so a federal and a
+// state rate are two rows.
+type TaxRate struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	CountryCode string    `json:"country_code" gorm:"size:2;not null"`
+	Region      string    `json:"region" gorm:"not null;default:''"` // empty for the whole country
+	TaxClass    string    `json:"tax_class" gorm:"not null;default:standard"`
+	Name        string    `json:"name" gorm:"not null"`
+	Rate        float64   `json:"rate" gorm:"not null"` // percent
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// OrderTaxLine is the tax charged at one rate on one order item. The rate is copied so
+// later changes to the rate table do not alter placed orders.
+type OrderTaxLine struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	OrderID     uint      `json:"order_id" gorm:"not null;index"`
+	OrderItemID uint      `json:"order_item_id" gorm:"not null;index"`
+	Name        string    `json:"name" gorm:"not null"`
+	CountryCode string    `json:"country_code" gorm:"size:2;not null"`
+	Region      string    `json:"region" gorm:"not null;default:''"`
+	Rate        float64   `json:"rate" gorm:"not null"`
+	Amount      float64   `json:"amount" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -8,40 +8,136 @@ import (
 
 // User represents a user in the e-commerce system
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null"`
-	Password  string         `json:"-" gorm:"not null"`
-	FirstName string         `json:"first_name" gorm:"not null"`
-	LastName  string         `json:"last_name" gorm:"not null"`
-	Phone     string         `json:"phone"`
-	IsActive  bool           `json:"is_active" gorm:"default:true"`
-	Role      UserRole       `json:"role" gorm:"type:varchar(20);default:customer"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"` // ✅ Proper soft deletes
-
-	RefreshTokens []RefreshToken `json:"-" gorm:"foreignKey:UserID"`
-	Orders        []Order        `json:"-" gorm:"foreignKey:UserID"`
-	Cart          Cart           `json:"-" gorm:"foreignKey:UserID"`
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	Email            string         `json:"email" gorm:"uniqueIndex;not null"`
+	Password         string         `json:"-" gorm:"not null"`
+	FirstName        string         `json:"first_name" gorm:"not null"`
+	LastName         string         `json:"last_name" gorm:"not null"`
+	Phone            string         `json:"phone"`
+	IsActive         bool           `json:"is_active" gorm:"default:true"`
+	Role             UserRole       `json:"role" gorm:"type:varchar(50);default:customer"`
+	IsServiceAccount bool           `json:"is_service_account" gorm:"default:false"` // authenticates with API keys only
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"deleted_at" gorm:"index"` // ✅ Proper soft deletes
+
+	// Locale is the language emails are sent in; empty means the shop's default
+	Locale string `json:"locale" gorm:"not null;default:''"`
+
+	// PasswordUnset marks accounts created through a sign-in provider whose owner has
+	// not chosen a password yet
+	PasswordUnset bool `json:"-" gorm:"not null;default:false"`
+
+	// EmailVerifiedAt is set once the user has proven they own the email address, by
+	// following a link sent to it or signing in through a provider that verified it
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+
+	RefreshTokens    []RefreshToken   `json:"-" gorm:"foreignKey:UserID"`
+	LinkedIdentities []LinkedIdentity `json:"-" gorm:"foreignKey:UserID"`
+	Addresses        []Address        `json:"-" gorm:"foreignKey:UserID"`
+	APIKeys          []APIKey         `json:"-" gorm:"foreignKey:UserID"`
+	Orders           []Order          `json:"-" gorm:"foreignKey:UserID"`
+	Cart             Cart             `json:"-" gorm:"foreignKey:UserID"`
 }
 
 // UserRole defines the role of a user in the system
 type UserRole string
 
+// Built-in roles; further roles can be created through the admin API
 const (
-	UserRoleCustomer UserRole = "customer" // default role
-	UserRoleAdmin    UserRole = "admin"    // admin role
+	UserRoleCustomer  UserRole = "customer"  // default role
+	UserRoleAdmin     UserRole = "admin"     // admin role
+	UserRoleSupport   UserRole = "support"   // customer support staff
+	UserRoleWarehouse UserRole = "warehouse" // fulfilment staff
 )
 
-// RefreshToken represents a refresh token for user authentication
+// RefreshToken represents a refresh token for user authentication.
+// Each row is one signed-in device session; the token rotates in place on refresh.
 type RefreshToken struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	UserID    uint           `json:"user_id" gorm:"not null"`
-	Token     string         `json:"token" gorm:"uniqueIndex;not null"`
-	ExpiresAt time.Time      `json:"expires_at" gorm:"not null"`
-	CreatedAt time.Time      `json:"created_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	UserID         uint           `json:"user_id" gorm:"not null"`
+	Token          string         `json:"token" gorm:"uniqueIndex;not null"`
+	UserAgent      string         `json:"user_agent"`
+	IPAddress      string         `json:"ip_address"`
+	LastUsedAt     time.Time      `json:"last_used_at"`
+	ImpersonatorID *uint          `json:"impersonator_id"` // set when staff opened the session on the user's behalf
+	ExpiresAt      time.Time      `json:"expires_at" gorm:"not null"`
+	CreatedAt      time.Time      `json:"created_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	User User `json:"-"`
+}
+
+// LoginThrottleScope identifies what a login throttle counts failures for
+type LoginThrottleScope string
+
+const (
+	LoginThrottleScopeAccount LoginThrottleScope = "account" // keyed by lower-cased email
+	LoginThrottleScopeIP      LoginThrottleScope = "ip"      // keyed by client IP address
+)
+
+// LoginThrottle tracks consecutive failed logins for an account or IP address
+type LoginThrottle struct {
+	ID           uint               `json:"id" gorm:"primaryKey"`
+	Scope        LoginThrottleScope `json:"scope" gorm:"type:varchar(20);not null;uniqueIndex:idx_login_throttles_scope_key"`
+	Key          string             `json:"key" gorm:"not null;uniqueIndex:idx_login_throttles_scope_key"`
+	FailedCount  int                `json:"failed_count" gorm:"default:0"`
+	LastFailedAt time.Time          `json:"last_failed_at"`
+	LockedUntil  *time.Time         `json:"locked_until"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// VerificationPurpose defines what a verification token may be used for
+type VerificationPurpose string
+
+const (
+	VerificationPurposePasswordReset   VerificationPurpose = "password_reset"
+	VerificationPurposeEmailChange     VerificationPurpose = "email_change"     // payload holds the new email
+	VerificationPurposeGuestConversion VerificationPurpose = "guest_conversion" // payload holds the guest's email
+)
+
+// VerificationToken is a single-use token emailed to a user. Only its hash is stored.
+type VerificationToken struct {
+	ID        uint                `json:"id" gorm:"primaryKey"`
+	UserID    *uint               `json:"user_id" gorm:"index"` // nil until a guest conversion is used
+	Purpose   VerificationPurpose `json:"purpose" gorm:"type:varchar(30);not null"`
+	TokenHash string              `json:"-" gorm:"uniqueIndex;not null"`
+	Payload   string              `json:"-"`
+	ExpiresAt time.Time           `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time          `json:"used_at"`
+	CreatedAt time.Time           `json:"created_at"`
+
+	// Relationships
+	User User `json:"-"`
+}
+
+// LinkedIdentity ties an account at an external OpenID Connect provider to a user
+type LinkedIdentity struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Provider  string    `json:"provider" gorm:"not null;uniqueIndex:idx_linked_identities_provider_subject"`
+	Subject   string    `json:"subject" gorm:"not null;uniqueIndex:idx_linked_identities_provider_subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 
 	// Relationships
 	User User `json:"-"`
 }
+
+// OIDCLoginState remembers a started external sign-in until the provider redirects back
+type OIDCLoginState struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	State        string    `json:"-" gorm:"uniqueIndex;not null"`
+	Provider     string    `json:"provider" gorm:"not null"`
+	CodeVerifier string    `json:"-" gorm:"not null"`
+	Nonce        string    `json:"-" gorm:"not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
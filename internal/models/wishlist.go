@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Wishlist is a named list of products a customer saved for later
+type Wishlist struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	UserID    uint           `json:"user_id" gorm:"not null;index"`
+	Name      string         `json:"name" gorm:"not null"`
+	ShareSlug *string        `json:"share_slug" gorm:"uniqueIndex"` // set while the list is shared publicly
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	User  User           `json:"-" gorm:"foreignKey:UserID"`
+	Items []WishlistItem `json:"items" gorm:"foreignKey:WishlistID"`
+}
+
+// WishlistItem is a product saved on a wishlist
+type WishlistItem struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	WishlistID uint      `json:"wishlist_id" gorm:"not null"`
+	ProductID  uint      `json:"product_id" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	Wishlist Wishlist `json:"-" gorm:"foreignKey:WishlistID"`
+	Product  Product  `json:"product" gorm:"foreignKey:ProductID"`
+}
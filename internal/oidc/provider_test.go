@@ -0,0 +1,266 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/programmerjide/ecommerce/internal/config"
+)
+
+const (
+	testClientID    = "shop"
+	testRedirectURL = "https://shop.example/auth/oidc/stub/callback"
+	testKeyID       = "stub-key"
+)
+
+// stubProvider is a local identity provider serving discovery, JWKS and a token endpoint
+type stubProvider struct {
+	*httptest.Server
+	t   *testing.T
+	key *rsa.PrivateKey
+
+	mu sync.Mutex
+	// issuer is what discovery reports; it defaults to the server URL
+	issuer string
+	// challenges holds the PKCE challenge sent with each authorization code
+	challenges map[string]string
+	// idTokens is what the token endpoint returns for each code
+	idTokens map[string]string
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stub := &stubProvider{
+		t:          t,
+		key:        key,
+		challenges: make(map[string]string),
+		idTokens:   make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", stub.discovery)
+	mux.HandleFunc("/jwks", stub.jwks)
+	mux.HandleFunc("/token", stub.token)
+	stub.Server = httptest.NewServer(mux)
+	t.Cleanup(stub.Close)
+
+	stub.issuer = stub.URL
+	return stub
+}
+
+func (s *stubProvider) provider() *Provider {
+	return NewProvider(config.OIDCProviderConfig{
+		Name:        "stub",
+		IssuerURL:   s.URL,
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+		Scopes:      []string{"openid", "email"},
+	})
+}
+
+// authorize plays the user approving the sign-in: it records the PKCE challenge
+// from the authorization URL and returns the code the provider would redirect with
+func (s *stubProvider) authorize(authorizationURL, code, idToken string) {
+	s.t.Helper()
+
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if method := parsed.Query().Get("code_challenge_method"); method != "S256" {
+		s.t.Fatalf("code_challenge_method = %q, want S256", method)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.challenges[code] = parsed.Query().Get("code_challenge")
+	s.idTokens[code] = idToken
+}
+
+func (s *stubProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	issuer := s.issuer
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, discoveryDocument{
+		Issuer:                issuer,
+		AuthorizationEndpoint: s.URL + "/authorize",
+		TokenEndpoint:         s.URL + "/token",
+		JWKSURI:               s.URL + "/jwks",
+	})
+}
+
+func (s *stubProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, jsonWebKeySet{Keys: []jsonWebKey{{
+		Kty: "RSA",
+		Kid: testKeyID,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+	}}})
+}
+
+func (s *stubProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	s.mu.Lock()
+	challenge, ok := s.challenges[r.PostForm.Get("code")]
+	idToken := s.idTokens[r.PostForm.Get("code")]
+	s.mu.Unlock()
+
+	if r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("client_id") != testClientID ||
+		r.PostForm.Get("redirect_uri") != testRedirectURL {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	// The verifier must hash to the challenge the authorization request carried
+	if !ok || CodeChallenge(r.PostForm.Get("code_verifier")) != challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Tokens{AccessToken: "provider-access-token", TokenType: "Bearer", IDToken: idToken})
+}
+
+// signIDToken issues an ID token for the default identity, letting the caller adjust the claims
+func (s *stubProvider) signIDToken(key *rsa.PrivateKey, mutate func(*IDTokenClaims)) string {
+	s.t.Helper()
+
+	claims := &IDTokenClaims{
+		Nonce:         "nonce-1",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		Name:          "Jane Doe",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.URL,
+			Subject:   "subject-1",
+			Audience:  jwt.ClaimStrings{testClientID},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+	if mutate != nil {
+		mutate(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+	signed, err := token.SignedString(key)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return signed
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	stub := newStubProvider(t)
+	provider := stub.provider()
+	ctx := context.Background()
+
+	authorizationURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authorizationURL, stub.URL+"/authorize?") {
+		t.Fatalf("authorization URL %q does not use the discovered endpoint", authorizationURL)
+	}
+	query, _ := url.Parse(authorizationURL)
+	for param, want := range map[string]string{
+		"client_id":    testClientID,
+		"redirect_uri": testRedirectURL,
+		"state":        "state-1",
+		"nonce":        "nonce-1",
+	} {
+		if got := query.Query().Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+
+	stub.authorize(authorizationURL, "code-1", stub.signIDToken(stub.key, nil))
+
+	if _, err := provider.Exchange(ctx, "code-1", "another-verifier"); err == nil {
+		t.Fatal("expected exchange with the wrong PKCE verifier to fail")
+	}
+
+	tokens, err := provider.Exchange(ctx, "code-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("exchange failed: %v", err)
+	}
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if claims.Subject != "subject-1" || claims.Email != "jane@example.com" || !claims.EmailVerified {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	stub := newStubProvider(t)
+	provider := stub.provider()
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		key    *rsa.PrivateKey
+		mutate func(*IDTokenClaims)
+	}{
+		{"nonce of another sign-in", stub.key, func(c *IDTokenClaims) { c.Nonce = "nonce-2" }},
+		{"another issuer", stub.key, func(c *IDTokenClaims) { c.Issuer = "https://evil.example" }},
+		{"another client", stub.key, func(c *IDTokenClaims) { c.Audience = jwt.ClaimStrings{"other-client"} }},
+		{"expired", stub.key, func(c *IDTokenClaims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) }},
+		{"no expiry", stub.key, func(c *IDTokenClaims) { c.ExpiresAt = nil }},
+		{"no subject", stub.key, func(c *IDTokenClaims) { c.Subject = "" }},
+		{"not signed by the provider", otherKey, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idToken := stub.signIDToken(tt.key, tt.mutate)
+			if _, err := provider.VerifyIDToken(context.Background(), idToken, "nonce-1"); err == nil {
+				t.Fatal("expected id_token to be rejected")
+			}
+		})
+	}
+}
+
+func TestDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	stub := newStubProvider(t)
+	stub.issuer = "https://evil.example"
+
+	if _, err := stub.provider().AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-1"); err == nil {
+		t.Fatal("expected discovery with a foreign issuer to fail")
+	}
+}
@@ -2,27 +2,39 @@ package server
 
 import (
 	"github.com/programmerjide/ecommerce/internal/handler"
+	"github.com/programmerjide/ecommerce/internal/mailer"
 	"github.com/programmerjide/ecommerce/internal/service"
+	"github.com/programmerjide/ecommerce/internal/storage"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/programmerjide/ecommerce/internal/config"
 	"github.com/programmerjide/ecommerce/internal/middleware"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/utils"
 	"github.com/rs/zerolog"
 	"gorm.io/gorm"
 )
 
 type Server struct {
-	config *config.Config
-	db     *gorm.DB
-	logger *zerolog.Logger
+	config         *config.Config
+	db             *gorm.DB
+	keys           *utils.KeySet
+	passwordPolicy *service.PasswordPolicy
+	outbox         *service.EmailOutbox
+	templates      *mailer.Templates
+	logger         *zerolog.Logger
 }
 
-func NewServer(cfg *config.Config, db *gorm.DB, logger *zerolog.Logger) *Server {
+func NewServer(cfg *config.Config, db *gorm.DB, keys *utils.KeySet, passwordPolicy *service.PasswordPolicy, outbox *service.EmailOutbox, templates *mailer.Templates, logger *zerolog.Logger) *Server {
 	return &Server{
-		config: cfg,
-		db:     db,
-		logger: logger,
+		config:         cfg,
+		db:             db,
+		keys:           keys,
+		passwordPolicy: passwordPolicy,
+		outbox:         outbox,
+		templates:      templates,
+		logger:         logger,
 	}
 }
 
@@ -38,13 +50,55 @@ func (s *Server) SetupRoutes() *gin.Engine {
 	// Add routes here
 	router.GET("/health", s.healthCheckHandler)
 
-	authService := service.NewAuthService(s.db, s.config)
-	userService := service.NewUserService(s.db)
+	// Every email goes through the outbox so that failed sends are retried
+	mail := s.outbox
+	files := storage.NewLocalStorage(s.config.Storage.Path)
+
+	notificationService := service.NewNotificationService(s.db, mail, s.outbox.Transport(), s.templates, s.config)
 	productService := service.NewProductService(s.db)
+	taxCalculator := service.NewTableTaxCalculator(s.db, &s.config.Tax)
+	cartService := service.NewCartService(s.db, s.keys, &s.config.Cart, productService, taxCalculator)
+	authService := service.NewAuthService(s.db, s.config, s.keys, notificationService, s.passwordPolicy, cartService)
+	userService := service.NewUserService(s.db, s.config, notificationService, s.passwordPolicy)
+	sessionService := service.NewSessionService(s.db)
+	oidcService := service.NewOIDCService(s.db, &s.config.OIDC, authService)
+	rbacService := service.NewRBACService(s.db)
+	addressService := service.NewAddressService(s.db)
+	apiKeyService := service.NewAPIKeyService(s.db)
+	orderService := service.NewOrderService(s.db, notificationService, authService, cartService, addressService, productService, taxCalculator)
+	couponService := service.NewCouponService(s.db)
+	promotionService := service.NewPromotionService(s.db)
+	taxService := service.NewTaxService(s.db)
+	shippingService := service.NewShippingService(s.db, cartService)
+	shipmentService := service.NewShipmentService(s.db, notificationService)
+	invoiceService := service.NewInvoiceService(s.db, files, &s.config.Invoice)
+	returnService := service.NewReturnService(s.db, notificationService, invoiceService)
+	wishlistService := service.NewWishlistService(s.db, s.config, cartService, productService)
+	privacyService := service.NewPrivacyService(s.db, notificationService, cartService, orderService, wishlistService)
 
 	authHandler := handler.NewAuthHandler(authService, *s.logger)
 	userHandler := handler.NewUserHandler(userService, *s.logger)
+	sessionHandler := handler.NewSessionHandler(sessionService, *s.logger)
+	oidcHandler := handler.NewOIDCHandler(oidcService, *s.logger)
+	rbacHandler := handler.NewRBACHandler(rbacService, *s.logger)
+	addressHandler := handler.NewAddressHandler(addressService, *s.logger)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService, *s.logger)
 	productHandler := handler.NewProductHandler(productService, *s.logger)
+	cartHandler := handler.NewCartHandler(cartService, *s.logger)
+	orderHandler := handler.NewOrderHandler(orderService, *s.logger)
+	couponHandler := handler.NewCouponHandler(couponService, *s.logger)
+	promotionHandler := handler.NewPromotionHandler(promotionService, *s.logger)
+	taxHandler := handler.NewTaxHandler(taxService, *s.logger)
+	shippingHandler := handler.NewShippingHandler(shippingService, *s.logger)
+	shipmentHandler := handler.NewShipmentHandler(shipmentService, *s.logger)
+	returnHandler := handler.NewReturnHandler(returnService, *s.logger)
+	invoiceHandler := handler.NewInvoiceHandler(invoiceService, *s.logger)
+	wishlistHandler := handler.NewWishlistHandler(wishlistService, *s.logger)
+	privacyHandler := handler.NewPrivacyHandler(privacyService, *s.logger)
+	notificationHandler := handler.NewNotificationHandler(notificationService, s.outbox, *s.logger)
+
+	// Public verification keys for services that validate our tokens independently
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
 
 	api := router.Group("/api/v1") // API v1 routes
 	{
@@ -55,11 +109,48 @@ func (s *Server) SetupRoutes() *gin.Engine {
 			auth.POST("/login", authHandler.Login)
 			auth.POST("/refresh", authHandler.RefreshToken)
 			auth.POST("/logout", authHandler.Logout)
+			auth.POST("/forgot-password", authHandler.ForgotPassword)
+			auth.POST("/reset-password", authHandler.ResetPassword)
+			auth.POST("/confirm-email", userHandler.ConfirmEmailChange)
+			auth.POST("/convert-guest", authHandler.RequestGuestConversion)
+			auth.POST("/convert-guest/confirm", authHandler.ConvertGuest)
+
+			// External identity providers (authorization code + PKCE)
+			auth.GET("/oidc/:provider/authorize", oidcHandler.Authorize)
+			auth.GET("/oidc/:provider/callback", oidcHandler.Callback)
+		}
+
+		// Guests can find an order with its number and the email it was placed with
+		api.POST("/orders/lookup", orderHandler.LookupOrder)
+		api.POST("/orders/lookup/tracking", shipmentHandler.LookupTracking)
+
+		// Wishlists their owners chose to share
+		api.GET("/wishlists/shared/:slug", wishlistHandler.GetSharedWishlist)
+
+		// Shopping routes, open to signed-in users and to guests holding a cart token
+		shop := api.Group("/")
+		shop.Use(middleware.OptionalAuth(authService, apiKeyService))
+		shop.Use(middleware.AuditImpersonation(authService, *s.logger))
+		{
+			cart := shop.Group("/cart")
+			{
+				cart.GET("/", cartHandler.GetCart)
+				cart.DELETE("/", cartHandler.ClearCart)
+				cart.POST("/items", cartHandler.AddToCart)
+				cart.PUT("/items/:id", cartHandler.UpdateCartItem)
+				cart.DELETE("/items/:id", cartHandler.RemoveCartItem)
+				cart.POST("/coupon", cartHandler.ApplyCoupon)
+				cart.DELETE("/coupon", cartHandler.RemoveCoupon)
+				cart.GET("/shipping-rates", shippingHandler.GetShippingRates)
+			}
+
+			shop.POST("/checkout", middleware.BlockImpersonation(), orderHandler.Checkout)
 		}
 
 		// Protected routes (authentication required)
 		protected := api.Group("/")
-		protected.Use(middleware.AuthMiddleware(&s.config.JWT))
+		protected.Use(middleware.AuthMiddleware(authService, apiKeyService))
+		protected.Use(middleware.AuditImpersonation(authService, *s.logger))
 		{
 			users := protected.Group("/users")
 			{
@@ -67,26 +158,205 @@ func (s *Server) SetupRoutes() *gin.Engine {
 				userRoutes := users
 				userRoutes.GET("/profile", userHandler.GetProfile)
 				userRoutes.PUT("/profile", userHandler.UpdateProfile)
+				userRoutes.PUT("/password", middleware.BlockImpersonation(), middleware.BlockAPIKey(), userHandler.ChangePassword)
+				userRoutes.PUT("/email", middleware.BlockImpersonation(), middleware.BlockAPIKey(), userHandler.ChangeEmail)
+
+				// Device session routes
+				userRoutes.GET("/sessions", sessionHandler.ListSessions)
+				userRoutes.DELETE("/sessions", middleware.BlockImpersonation(), sessionHandler.RevokeOtherSessions)
+				userRoutes.DELETE("/sessions/:id", middleware.BlockImpersonation(), sessionHandler.RevokeSession)
+
+				// Linked external identities
+				userRoutes.GET("/identities", oidcHandler.ListIdentities)
+				userRoutes.DELETE("/identities/:id", middleware.BlockImpersonation(), oidcHandler.UnlinkIdentity)
+
+				// Address book
+				userRoutes.GET("/addresses", addressHandler.ListAddresses)
+				userRoutes.POST("/addresses", addressHandler.CreateAddress)
+				userRoutes.GET("/addresses/:id", addressHandler.GetAddress)
+				userRoutes.PUT("/addresses/:id", addressHandler.UpdateAddress)
+				userRoutes.DELETE("/addresses/:id", addressHandler.DeleteAddress)
+
+				// Wishlists
+				userRoutes.GET("/wishlists", wishlistHandler.ListWishlists)
+				userRoutes.POST("/wishlists", wishlistHandler.CreateWishlist)
+				userRoutes.GET("/wishlists/:id", wishlistHandler.GetWishlist)
+				userRoutes.PUT("/wishlists/:id", wishlistHandler.RenameWishlist)
+				userRoutes.DELETE("/wishlists/:id", wishlistHandler.DeleteWishlist)
+				userRoutes.POST("/wishlists/:id/items", wishlistHandler.AddItem)
+				userRoutes.DELETE("/wishlists/:id/items/:item_id", wishlistHandler.RemoveItem)
+				userRoutes.POST("/wishlists/:id/items/:item_id/move-to-cart", wishlistHandler.MoveToCart)
+				userRoutes.POST("/wishlists/:id/share", wishlistHandler.ShareWishlist)
+				userRoutes.DELETE("/wishlists/:id/share", wishlistHandler.UnshareWishlist)
+
+				// API keys for machine-to-machine access, managed from an interactive session only
+				userRoutes.GET("/api-keys", middleware.BlockAPIKey(), apiKeyHandler.ListAPIKeys)
+				userRoutes.POST("/api-keys", middleware.BlockImpersonation(), middleware.BlockAPIKey(), apiKeyHandler.CreateAPIKey)
+				userRoutes.DELETE("/api-keys/:key_id", middleware.BlockImpersonation(), middleware.BlockAPIKey(), apiKeyHandler.RevokeAPIKey)
+
+				// Email language and which notifications to receive
+				userRoutes.GET("/notification-preferences", notificationHandler.GetPreferences)
+				userRoutes.PUT("/notification-preferences", notificationHandler.UpdatePreferences)
+
+				// Data-subject requests
+				userRoutes.GET("/data-export", middleware.BlockImpersonation(), privacyHandler.ExportData)
+				userRoutes.GET("/erasure-requests", privacyHandler.ListMyErasureRequests)
+				userRoutes.POST("/erasure-requests", middleware.BlockImpersonation(), privacyHandler.RequestErasure)
+				userRoutes.DELETE("/erasure-requests", middleware.BlockImpersonation(), privacyHandler.CancelErasure)
+
+				// Staff acting as this user can hand the session back early
+				userRoutes.DELETE("/impersonation", authHandler.EndImpersonation)
+			}
+
+			orders := protected.Group("/orders")
+			{
+				orders.GET("/", orderHandler.ListOrders)
+				orders.GET("/:id", orderHandler.GetOrder)
+				orders.GET("/:id/tracking", shipmentHandler.GetTracking)
+				orders.POST("/:id/returns", returnHandler.CreateReturn)
+				orders.GET("/:id/invoices", invoiceHandler.ListOrderInvoices)
+			}
+
+			protected.GET("/invoices/:id/download", invoiceHandler.DownloadMyInvoice)
+
+			returns := protected.Group("/returns")
+			{
+				returns.GET("/", returnHandler.ListMyReturns)
+				returns.GET("/:id", returnHandler.GetMyReturn)
 			}
 
 			categories := protected.Group("/categories")
 			{
 				categoryRoutes := categories
-				categoryRoutes.POST("/", middleware.AdminMiddleware(), productHandler.CreateCategory)
+				categoryRoutes.POST("/", middleware.RequirePermission(models.PermissionCategoriesWrite), productHandler.CreateCategory)
 				categoryRoutes.GET("/", productHandler.GetCategories)
-				categoryRoutes.PUT("/:id", middleware.AdminMiddleware(), productHandler.UpdateCategory)
-				categoryRoutes.DELETE("/:id", middleware.AdminMiddleware(), productHandler.DeleteCategory)
+				categoryRoutes.PUT("/:id", middleware.RequirePermission(models.PermissionCategoriesWrite), productHandler.UpdateCategory)
+				categoryRoutes.DELETE("/:id", middleware.RequirePermission(models.PermissionCategoriesWrite), productHandler.DeleteCategory)
 			}
 
 			products := protected.Group("/products")
 			{
 				productRoutes := products
-				productRoutes.POST("/", middleware.AdminMiddleware(), productHandler.CreateProduct)      // No ()
-				productRoutes.GET("/", productHandler.GetProducts)                                       // No ()
-				productRoutes.GET("/:id", productHandler.GetProduct)                                     // No () - FIXED
-				productRoutes.PUT("/:id", middleware.AdminMiddleware(), productHandler.UpdateProduct)    // No ()
-				productRoutes.DELETE("/:id", middleware.AdminMiddleware(), productHandler.DeleteProduct) // No ()
-				productRoutes.GET("/search", productHandler.SearchProducts)                              // Changed from POST, moved before /:id
+				productRoutes.POST("/", middleware.RequirePermission(models.PermissionProductsWrite), productHandler.CreateProduct)      // No ()
+				productRoutes.GET("/", productHandler.GetProducts)                                                                       // No ()
+				productRoutes.GET("/:id", productHandler.GetProduct)                                                                     // No () - FIXED
+				productRoutes.PUT("/:id", middleware.RequirePermission(models.PermissionProductsWrite), productHandler.UpdateProduct)    // No ()
+				productRoutes.DELETE("/:id", middleware.RequirePermission(models.PermissionProductsWrite), productHandler.DeleteProduct) // No ()
+				productRoutes.GET("/search", productHandler.SearchProducts)                                                              // Changed from POST, moved before /:id
+
+				productRoutes.GET("/:id/price-history", middleware.RequirePermission(models.PermissionProductsWrite), productHandler.GetPriceHistory)
+			}
+
+			// Staff routes, each guarded by the permission it needs
+			admin := protected.Group("/admin")
+			{
+				adminUsers := admin.Group("/users")
+				adminUsers.GET("/", middleware.RequirePermission(models.PermissionUsersRead), userHandler.ListUsers)
+				adminUsers.GET("/:id", middleware.RequirePermission(models.PermissionUsersRead), userHandler.GetUser)
+				adminUsers.POST("/:id/deactivate", middleware.RequirePermission(models.PermissionUsersWrite), userHandler.DeactivateUser)
+				adminUsers.POST("/:id/reactivate", middleware.RequirePermission(models.PermissionUsersWrite), userHandler.ReactivateUser)
+				adminUsers.DELETE("/:id", middleware.RequirePermission(models.PermissionUsersWrite), userHandler.DeleteUser)
+				adminUsers.POST("/:id/restore", middleware.RequirePermission(models.PermissionUsersWrite), userHandler.RestoreUser)
+				adminUsers.POST("/:id/unlock", middleware.RequirePermission(models.PermissionUsersWrite), authHandler.UnlockAccount)
+				adminUsers.GET("/:id/sessions", middleware.RequirePermission(models.PermissionUsersRead), sessionHandler.ListUserSessions)
+				adminUsers.DELETE("/:id/sessions", middleware.RequirePermission(models.PermissionUsersWrite), sessionHandler.RevokeAllUserSessions)
+				adminUsers.DELETE("/:id/sessions/:session_id", middleware.RequirePermission(models.PermissionUsersWrite), sessionHandler.RevokeUserSession)
+				adminUsers.PUT("/:id/role", middleware.RequirePermission(models.PermissionRolesManage), rbacHandler.AssignRole)
+				adminUsers.POST("/:id/impersonate", middleware.BlockAPIKey(), middleware.RequirePermission(models.PermissionUsersImpersonate), authHandler.Impersonate)
+
+				admin.GET("/impersonations", middleware.RequirePermission(models.PermissionUsersRead), authHandler.ListImpersonations)
+				admin.GET("/impersonations/:id", middleware.RequirePermission(models.PermissionUsersRead), authHandler.GetImpersonation)
+
+				adminRoles := admin.Group("/roles")
+				adminRoles.Use(middleware.RequirePermission(models.PermissionRolesManage))
+				adminRoles.GET("/", rbacHandler.ListRoles)
+				adminRoles.POST("/", rbacHandler.CreateRole)
+				adminRoles.PUT("/:id/permissions", rbacHandler.UpdateRolePermissions)
+				adminRoles.DELETE("/:id", rbacHandler.DeleteRole)
+
+				adminCoupons := admin.Group("/coupons")
+				adminCoupons.Use(middleware.RequirePermission(models.PermissionPromotionsManage))
+				adminCoupons.GET("/", couponHandler.ListCoupons)
+				adminCoupons.POST("/", couponHandler.CreateCoupon)
+				adminCoupons.GET("/:id", couponHandler.GetCoupon)
+				adminCoupons.PUT("/:id", couponHandler.UpdateCoupon)
+				adminCoupons.DELETE("/:id", couponHandler.DeleteCoupon)
+
+				adminPromotions := admin.Group("/promotions")
+				adminPromotions.Use(middleware.RequirePermission(models.PermissionPromotionsManage))
+				adminPromotions.GET("/", promotionHandler.ListPromotions)
+				adminPromotions.POST("/", promotionHandler.CreatePromotion)
+				adminPromotions.GET("/:id", promotionHandler.GetPromotion)
+				adminPromotions.PUT("/:id", promotionHandler.UpdatePromotion)
+				adminPromotions.DELETE("/:id", promotionHandler.DeletePromotion)
+
+				adminTaxRates := admin.Group("/tax-rates")
+				adminTaxRates.Use(middleware.RequirePermission(models.PermissionTaxManage))
+				adminTaxRates.GET("/", taxHandler.ListTaxRates)
+				adminTaxRates.POST("/", taxHandler.CreateTaxRate)
+				adminTaxRates.PUT("/:id", taxHandler.UpdateTaxRate)
+				adminTaxRates.DELETE("/:id", taxHandler.DeleteTaxRate)
+
+				adminShippingZones := admin.Group("/shipping-zones")
+				adminShippingZones.Use(middleware.RequirePermission(models.PermissionShippingManage))
+				adminShippingZones.GET("/", shippingHandler.ListZones)
+				adminShippingZones.POST("/", shippingHandler.CreateZone)
+				adminShippingZones.GET("/:id", shippingHandler.GetZone)
+				adminShippingZones.PUT("/:id", shippingHandler.UpdateZone)
+				adminShippingZones.DELETE("/:id", shippingHandler.DeleteZone)
+
+				adminShippingMethods := admin.Group("/shipping-methods")
+				adminShippingMethods.Use(middleware.RequirePermission(models.PermissionShippingManage))
+				adminShippingMethods.POST("/", shippingHandler.CreateMethod)
+				adminShippingMethods.PUT("/:id", shippingHandler.UpdateMethod)
+				adminShippingMethods.DELETE("/:id", shippingHandler.DeleteMethod)
+
+				// Fulfilment: orders ship in one or more shipments
+				adminOrders := admin.Group("/orders")
+				adminOrders.Use(middleware.RequirePermission(models.PermissionOrdersFulfil))
+				adminOrders.GET("/:id/shipments", shipmentHandler.ListShipments)
+				adminOrders.POST("/:id/shipments", shipmentHandler.CreateShipment)
+				adminOrders.GET("/:id/packing-slip", invoiceHandler.PackingSlip)
+
+				adminShipments := admin.Group("/shipments")
+				adminShipments.Use(middleware.RequirePermission(models.PermissionOrdersFulfil))
+				adminShipments.PUT("/:id", shipmentHandler.UpdateShipment)
+				adminShipments.POST("/:id/deliver", shipmentHandler.MarkDelivered)
+
+				// Returns are reviewed and refunded by staff who can refund, received by fulfilment
+				adminReturns := admin.Group("/returns")
+				adminReturns.GET("/", middleware.RequirePermission(models.PermissionOrdersRead), returnHandler.ListReturns)
+				adminReturns.GET("/:id", middleware.RequirePermission(models.PermissionOrdersRead), returnHandler.GetReturn)
+				adminReturns.POST("/:id/approve", middleware.RequirePermission(models.PermissionOrdersRefund), returnHandler.ApproveReturn)
+				adminReturns.POST("/:id/reject", middleware.RequirePermission(models.PermissionOrdersRefund), returnHandler.RejectReturn)
+				adminReturns.POST("/:id/receive", middleware.RequirePermission(models.PermissionOrdersFulfil), returnHandler.ReceiveReturn)
+				adminReturns.POST("/:id/refund", middleware.RequirePermission(models.PermissionOrdersRefund), returnHandler.RefundReturn)
+
+				adminInvoices := admin.Group("/invoices")
+				adminInvoices.Use(middleware.RequirePermission(models.PermissionOrdersRead))
+				adminInvoices.GET("/", invoiceHandler.ListInvoices)
+				adminInvoices.GET("/:id/download", invoiceHandler.DownloadInvoice)
+				admin.POST("/orders/:id/confirm", middleware.RequirePermission(models.PermissionOrdersWrite), orderHandler.ConfirmOrder)
+				admin.POST("/orders/:id/invoice", middleware.RequirePermission(models.PermissionOrdersWrite), invoiceHandler.IssueInvoice)
+
+				adminServiceAccounts := admin.Group("/service-accounts")
+				adminServiceAccounts.Use(middleware.BlockAPIKey())
+				adminServiceAccounts.GET("/", middleware.RequirePermission(models.PermissionUsersRead), apiKeyHandler.ListServiceAccounts)
+				adminServiceAccounts.POST("/", middleware.RequirePermission(models.PermissionUsersWrite), apiKeyHandler.CreateServiceAccount)
+				adminServiceAccounts.GET("/:id/api-keys", middleware.RequirePermission(models.PermissionUsersRead), apiKeyHandler.ListServiceAccountKeys)
+				adminServiceAccounts.POST("/:id/api-keys", middleware.RequirePermission(models.PermissionUsersWrite), apiKeyHandler.CreateServiceAccountKey)
+				adminServiceAccounts.DELETE("/:id/api-keys/:key_id", middleware.RequirePermission(models.PermissionUsersWrite), apiKeyHandler.RevokeServiceAccountKey)
+
+				adminErasure := admin.Group("/erasure-requests")
+				adminErasure.GET("/", middleware.RequirePermission(models.PermissionUsersRead), privacyHandler.ListErasureRequests)
+				adminErasure.POST("/:id/approve", middleware.RequirePermission(models.PermissionUsersWrite), privacyHandler.ApproveErasure)
+				adminErasure.POST("/:id/reject", middleware.RequirePermission(models.PermissionUsersWrite), privacyHandler.RejectErasure)
+
+				adminOutbox := admin.Group("/notifications/outbox")
+				adminOutbox.GET("/", middleware.RequirePermission(models.PermissionUsersRead), notificationHandler.ListOutboxEmails)
+				adminOutbox.POST("/:id/retry", middleware.RequirePermission(models.PermissionUsersWrite), notificationHandler.RetryOutboxEmail)
+
+				admin.GET("/permissions", middleware.RequirePermission(models.PermissionRolesManage), rbacHandler.ListPermissions)
 			}
 		}
 	}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"gorm.io/gorm"
+)
+
+type AddressService struct {
+	db *gorm.DB
+}
+
+func NewAddressService(db *gorm.DB) *AddressService {
+	return &AddressService{
+		db: db,
+	}
+}
+
+// ListAddresses returns the address book of a user, defaults first
+func (s *AddressService) ListAddresses(userID uint) ([]dto.AddressResponse, error) {
+	var addresses []models.Address
+	if err := s.db.Where("user_id = ?", userID).
+		Order("is_default_shipping DESC, is_default_billing DESC, created_at DESC").
+		Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.AddressResponse, len(addresses))
+	for i := range addresses {
+		response[i] = convertToAddressResponse(&addresses[i])
+	}
+	return response, nil
+}
+
+func (s *AddressService) GetAddress(userID, addressID uint) (*dto.AddressResponse, error) {
+	var address models.Address
+	if err := s.db.Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error; err != nil {
+		return nil, ErrAddressNotFound
+	}
+
+	response := convertToAddressResponse(&address)
+	return &response, nil
+}
+
+// CreateAddress adds an address. The first address a user saves becomes both defaults.
+func (s *AddressService) CreateAddress(userID uint, req *dto.AddressRequest) (*dto.AddressResponse, error) {
+	address := models.Address{UserID: userID}
+	if err := applyAddressRequest(&address, req); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Address{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			address.IsDefaultShipping = true
+			address.IsDefaultBilling = true
+		}
+
+		if err := clearDefaultAddresses(tx, &address); err != nil {
+			return err
+		}
+		return tx.Create(&address).Error
+	})
+	if err != nil {
+		return nil, errors.New("failed to save address")
+	}
+
+	response := convertToAddressResponse(&address)
+	return &response, nil
+}
+
+// UpdateAddress replaces an address. Orders keep the snapshot taken at checkout.
+func (s *AddressService) UpdateAddress(userID, addressID uint, req *dto.AddressRequest) (*dto.AddressResponse, error) {
+	var address models.Address
+	if err := s.db.Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error; err != nil {
+		return nil, ErrAddressNotFound
+	}
+
+	if err := applyAddressRequest(&address, req); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaultAddresses(tx, &address); err != nil {
+			return err
+		}
+		return tx.Save(&address).Error
+	})
+	if err != nil {
+		return nil, errors.New("failed to save address")
+	}
+
+	response := convertToAddressResponse(&address)
+	return &response, nil
+}
+
+func (s *AddressService) DeleteAddress(userID, addressID uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", addressID, userID).Delete(&models.Address{})
+	if result.Error != nil {
+		return errors.New("failed to delete address")
+	}
+	if result.RowsAffected == 0 {
+		return ErrAddressNotFound
+	}
+	return nil
+}
+
+// CheckoutAddresses copies the chosen addresses into the snapshots stored on an order.
+// A zero ID selects the default address; billing falls back to the shipping address.
+func (s *AddressService) CheckoutAddresses(userID, shippingAddressID, billingAddressID uint) (shipping, billing models.AddressSnapshot, err error) {
+	shippingAddress, err := s.resolveAddress(userID, shippingAddressID, "is_default_shipping")
+	if err != nil {
+		return shipping, billing, err
+	}
+
+	billingAddress := shippingAddress
+	if billingAddressID != 0 {
+		if billingAddress, err = s.resolveAddress(userID, billingAddressID, "is_default_billing"); err != nil {
+			return shipping, billing, err
+		}
+	} else if defaultBilling, err := s.resolveAddress(userID, 0, "is_default_billing"); err == nil {
+		billingAddress = defaultBilling
+	}
+
+	return shippingAddress.Snapshot(), billingAddress.Snapshot(), nil
+}
+
+// snapshotAddressRequest validates an address entered at checkout without saving it
+func snapshotAddressRequest(req *dto.AddressRequest) (models.AddressSnapshot, error) {
+	var address models.Address
+	if err := applyAddressRequest(&address, req); err != nil {
+		return models.AddressSnapshot{}, err
+	}
+	return address.Snapshot(), nil
+}
+
+func (s *AddressService) resolveAddress(userID, addressID uint, defaultColumn string) (*models.Address, error) {
+	query := s.db.Where("user_id = ?", userID)
+	if addressID != 0 {
+		query = query.Where("id = ?", addressID)
+	} else {
+		query = query.Where(defaultColumn + " = true")
+	}
+
+	var address models.Address
+	if err := query.First(&address).Error; err != nil {
+		return nil, ErrAddressNotFound
+	}
+	return &address, nil
+}
+
+// clearDefaultAddresses unsets the default flags of the user's other addresses
+// when the given address is about to become a default
+func clearDefaultAddresses(tx *gorm.DB, address *models.Address) error {
+	if address.IsDefaultShipping {
+		if err := tx.Model(&models.Address{}).
+			Where("user_id = ? AND id <> ? AND is_default_shipping = true", address.UserID, address.ID).
+			Update("is_default_shipping", false).Error; err != nil {
+			return err
+		}
+	}
+
+	if address.IsDefaultBilling {
+		if err := tx.Model(&models.Address{}).
+			Where("user_id = ? AND id <> ? AND is_default_billing = true", address.UserID, address.ID).
+			Update("is_default_billing", false).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyAddressRequest(address *models.Address, req *dto.AddressRequest) error {
+	countryCode := strings.ToUpper(req.CountryCode)
+	postalCode, err := normalizePostalCode(countryCode, req.PostalCode)
+	if err != nil {
+		return err
+	}
+
+	address.Label = strings.TrimSpace(req.Label)
+	address.FirstName = strings.TrimSpace(req.FirstName)
+	address.LastName = strings.TrimSpace(req.LastName)
+	address.Company = strings.TrimSpace(req.Company)
+	address.Line1 = strings.TrimSpace(req.Line1)
+	address.Line2 = strings.TrimSpace(req.Line2)
+	address.City = strings.TrimSpace(req.City)
+	address.Region = strings.TrimSpace(req.Region)
+	address.PostalCode = postalCode
+	address.CountryCode = countryCode
+	address.Phone = strings.TrimSpace(req.Phone)
+	address.IsDefaultShipping = req.IsDefaultShipping
+	address.IsDefaultBilling = req.IsDefaultBilling
+	return nil
+}
+
+func convertToAddressResponse(address *models.Address) dto.AddressResponse {
+	return dto.AddressResponse{
+		ID:                address.ID,
+		Label:             address.Label,
+		FirstName:         address.FirstName,
+		LastName:          address.LastName,
+		Company:           address.Company,
+		Line1:             address.Line1,
+		Line2:             address.Line2,
+		City:              address.City,
+		Region:            address.Region,
+		PostalCode:        address.PostalCode,
+		CountryCode:       address.CountryCode,
+		Phone:             address.Phone,
+		IsDefaultShipping: address.IsDefaultShipping,
+		IsDefaultBilling:  address.IsDefaultBilling,
+		CreatedAt:         address.CreatedAt,
+		UpdatedAt:         address.UpdatedAt,
+	}
+}
@@ -2,7 +2,10 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/programmerjide/ecommerce/internal/config"
 	"github.com/programmerjide/ecommerce/internal/dto"
@@ -12,18 +15,28 @@ import (
 )
 
 type AuthService struct {
-	db     *gorm.DB
-	config *config.Config
+	db             *gorm.DB
+	config         *config.Config
+	keys           *utils.KeySet
+	notifications  *NotificationService
+	passwordPolicy *PasswordPolicy
+	loginGuard     *LoginGuard
+	cartService    *CartService
 }
 
-func NewAuthService(db *gorm.DB, cfg *config.Config) *AuthService {
+func NewAuthService(db *gorm.DB, cfg *config.Config, keys *utils.KeySet, notifications *NotificationService, passwordPolicy *PasswordPolicy, cartService *CartService) *AuthService {
 	return &AuthService{
-		db:     db,
-		config: cfg,
+		db:             db,
+		config:         cfg,
+		keys:           keys,
+		notifications:  notifications,
+		passwordPolicy: passwordPolicy,
+		loginGuard:     NewLoginGuard(db, &cfg.Login),
+		cartService:    cartService,
 	}
 }
 
-func (s *AuthService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, error) {
+func (s *AuthService) Register(req *dto.RegisterRequest, client dto.ClientInfo) (*dto.AuthResponse, error) {
 	var existingUser models.User
 
 	// ✅ FIXED: Check if user EXISTS (err == nil means found)
@@ -31,12 +44,40 @@ func (s *AuthService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, err
 		return nil, errors.New("email already in use")
 	}
 
+	user, err := s.newCustomer(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(user).Error; err != nil {
+		return nil, errors.New("failed to create user")
+	}
+
+	response, err := s.generateAuthResponse(user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mergeGuestCart(response, req.CartToken)
+	return response, nil
+}
+
+// newCustomer checks the password against the policy and builds the customer to create
+func (s *AuthService) newCustomer(req *dto.RegisterRequest) (*models.User, error) {
+	if err := s.passwordPolicy.Validate(req.Password, PasswordContext{
+		Email:     req.Email,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+	}); err != nil {
+		return nil, err
+	}
+
 	hashPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
 		return nil, errors.New("failed to hash password")
 	}
 
-	user := models.User{
+	return &models.User{
 		Email:     req.Email,
 		Password:  hashPassword,
 		FirstName: req.FirstName,
@@ -44,30 +85,290 @@ func (s *AuthService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, err
 		Phone:     req.Phone,
 		Role:      models.UserRoleCustomer,
 		IsActive:  true,
+	}, nil
+}
+
+// RequestGuestConversion emails a guest a link to register and take over their guest
+// orders. Only the link proves the guest owns the mailbox: anyone can place an order
+// with any email and learn its number. Requests that do not match a guest order, or
+// whose email already has an account, are ignored so nothing can be discovered.
+func (s *AuthService) RequestGuestConversion(req *dto.GuestConversionRequest) error {
+	email := normalizeEmail(req.Email)
+	var order models.Order
+	if err := s.db.Where("order_number = ? AND email = ? AND user_id IS NULL",
+		strings.ToUpper(strings.TrimSpace(req.OrderNumber)), email).
+		First(&order).Error; err != nil {
+		return nil
+	}
+	var count int64
+	if err := s.db.Unscoped().Model(&models.User{}).Where("LOWER(email) = ?", email).Count(&count).Error; err != nil || count > 0 {
+		return nil
 	}
 
-	if err := s.db.Create(&user).Error; err != nil {
-		return nil, errors.New("failed to create user")
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return errors.New("failed to generate confirmation token")
 	}
 
-	return s.generateAuthResponse(&user)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Only the most recent link stays valid
+		if err := tx.Where("payload = ? AND purpose = ? AND used_at IS NULL", email, models.VerificationPurposeGuestConversion).
+			Delete(&models.VerificationToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.VerificationToken{
+			Purpose:   models.VerificationPurposeGuestConversion,
+			TokenHash: utils.HashToken(token),
+			Payload:   email,
+			ExpiresAt: time.Now().Add(s.config.Password.EmailChangeExpires),
+		}).Error
+	})
+	if err != nil {
+		return errors.New("failed to save confirmation token")
+	}
+
+	return s.notifications.Notify(models.NotificationGuestConversion, nil, email, guestConversionEmail{
+		ConvertURL:     fmt.Sprintf("%s/convert-guest?token=%s", s.config.Server.PublicURL, token),
+		ExpiresInHours: int(s.config.Password.EmailChangeExpires.Hours()),
+	})
+}
+
+// ConvertGuest registers the account of a guest who followed the emailed link and
+// attaches every guest order placed with their email to it
+func (s *AuthService) ConvertGuest(req *dto.ConvertGuestRequest, client dto.ClientInfo) (*dto.AuthResponse, error) {
+	var conversionToken models.VerificationToken
+	if err := s.db.Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?",
+		utils.HashToken(req.Token), models.VerificationPurposeGuestConversion, time.Now()).
+		First(&conversionToken).Error; err != nil {
+		return nil, ErrInvalidEmailToken
+	}
+
+	user, err := s.newCustomer(&dto.RegisterRequest{
+		Email:     conversionToken.Payload,
+		Password:  req.Password,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Phone:     req.Phone,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Claim the token first so that concurrent requests cannot both use it
+		result := tx.Model(&models.VerificationToken{}).
+			Where("id = ? AND used_at IS NULL", conversionToken.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidEmailToken
+		}
+
+		var count int64
+		if err := tx.Unscoped().Model(&models.User{}).Where("LOWER(email) = ?", user.Email).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrDuplicateEmail
+		}
+		// The token was emailed to this address, which proves it
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.VerificationToken{}).Where("id = ?", conversionToken.ID).
+			Update("user_id", user.ID).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Order{}).
+			Where("user_id IS NULL AND email = ?", user.Email).
+			Update("user_id", user.ID).Error
+	})
+	if errors.Is(err, ErrInvalidEmailToken) || errors.Is(err, ErrDuplicateEmail) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to create account")
+	}
+
+	return s.generateAuthResponse(user, client)
 }
 
-func (s *AuthService) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
+func (s *AuthService) Login(req *dto.LoginRequest, client dto.ClientInfo) (*dto.AuthResponse, error) {
+	if err := s.loginGuard.Check(req.Email, client.IPAddress); err != nil {
+		var lockedErr *LockedError
+		if errors.As(err, &lockedErr) {
+			return nil, err
+		}
+		return nil, errors.New("failed to check login attempts")
+	}
+
 	var user models.User
-	if err := s.db.Where("email = ? AND is_active = ?", req.Email, true).First(&user).Error; err != nil {
-		return nil, errors.New("invalid email or password")
+	// Service accounts authenticate with API keys only
+	if err := s.db.Where("email = ? AND is_active = ? AND is_service_account = ?", req.Email, true, false).First(&user).Error; err != nil {
+		s.recordFailedLogin(req.Email, client, nil)
+		return nil, ErrInvalidCredentials
 	}
 
 	if err := utils.VerifyPassword(user.Password, req.Password); err != nil {
-		return nil, errors.New("invalid email or password")
+		s.recordFailedLogin(req.Email, client, &user)
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.loginGuard.RecordSuccess(req.Email); err != nil {
+		return nil, errors.New("failed to reset login attempts")
+	}
+
+	response, err := s.generateAuthResponse(&user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mergeGuestCart(response, req.CartToken)
+	return response, nil
+}
+
+// mergeGuestCart moves the cart the user filled before signing in into their account.
+// A stale or invalid cart token must not stop anyone from signing in, so failures only
+// leave the cart out of the response.
+func (s *AuthService) mergeGuestCart(response *dto.AuthResponse, cartToken string) {
+	if cartToken == "" {
+		return
 	}
 
-	return s.generateAuthResponse(&user)
+	if cart, err := s.cartService.MergeGuestCart(response.User.ID, cartToken); err == nil {
+		response.Cart = cart
+	}
 }
 
-func (s *AuthService) RefreshToken(req *dto.RefreshTokenRequest) (*dto.AuthResponse, error) {
-	claims, err := utils.ValidateToken(req.RefreshToken, s.config.JWT.Secret)
+// ForgotPassword emails a single-use reset link. Unknown emails are ignored so
+// the endpoint cannot be used to discover registered addresses.
+func (s *AuthService) ForgotPassword(req *dto.ForgotPasswordRequest) error {
+	var user models.User
+	if err := s.db.Where("email = ? AND is_active = ? AND is_service_account = ?", req.Email, true, false).First(&user).Error; err != nil {
+		return nil
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return errors.New("failed to generate reset token")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Only the most recent reset link stays valid
+		if err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.ID, models.VerificationPurposePasswordReset).
+			Delete(&models.VerificationToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.VerificationToken{
+			UserID:    &user.ID,
+			Purpose:   models.VerificationPurposePasswordReset,
+			TokenHash: utils.HashToken(token),
+			ExpiresAt: time.Now().Add(s.config.Password.ResetTokenExpires),
+		}).Error
+	})
+	if err != nil {
+		return errors.New("failed to save reset token")
+	}
+
+	return s.notifications.Notify(models.NotificationPasswordReset, &user.ID, user.Email, passwordResetEmail{
+		Name:             user.FirstName,
+		ResetURL:         fmt.Sprintf("%s/reset-password?token=%s", s.config.Server.PublicURL, token),
+		ExpiresInMinutes: int(s.config.Password.ResetTokenExpires.Minutes()),
+	})
+}
+
+// ResetPassword sets a new password from a reset token and signs the user out everywhere
+func (s *AuthService) ResetPassword(req *dto.ResetPasswordRequest) error {
+	var resetToken models.VerificationToken
+	if err := s.db.Preload("User").
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?",
+			utils.HashToken(req.Token), models.VerificationPurposePasswordReset, time.Now()).
+		First(&resetToken).Error; err != nil {
+		return ErrInvalidResetToken
+	}
+
+	user := resetToken.User
+	if err := s.passwordPolicy.Validate(req.NewPassword, PasswordContext{
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+	}); err != nil {
+		return err
+	}
+
+	hashPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Claim the token first so that concurrent requests cannot both use it
+		result := tx.Model(&models.VerificationToken{}).
+			Where("id = ? AND used_at IS NULL", resetToken.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidResetToken
+		}
+
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"password":          hashPassword,
+			"password_unset":    false,
+			"email_verified_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RefreshToken{}).Error
+	})
+	if errors.Is(err, ErrInvalidResetToken) {
+		return err
+	}
+	if err != nil {
+		return errors.New("failed to reset password")
+	}
+
+	// A successful reset proves ownership, so any login lockout is lifted
+	return s.loginGuard.Unlock(user.Email)
+}
+
+// UnlockAccount clears the login lockout of a user. Throttling of the IP addresses the
+// failed attempts came from is left in place, since an address may be shared by others.
+func (s *AuthService) UnlockAccount(userID uint) error {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return ErrUserNotFound
+	}
+
+	return s.loginGuard.Unlock(user.Email)
+}
+
+// recordFailedLogin counts the failure and tells the account owner when it gets locked
+func (s *AuthService) recordFailedLogin(email string, client dto.ClientInfo, user *models.User) {
+	locked, err := s.loginGuard.RecordFailure(email, client.IPAddress)
+	if err != nil || !locked || user == nil {
+		return
+	}
+
+	// Notification is best effort; the lockout itself is already in place
+	_ = s.notifications.Notify(models.NotificationAccountLocked, &user.ID, user.Email, accountLockedEmail{
+		Name:             user.FirstName,
+		LockedForMinutes: int(s.config.Login.LockoutDuration.Minutes()),
+		Attempts:         s.config.Login.MaxAccountAttempts,
+		IPAddress:        client.IPAddress,
+	})
+}
+
+func (s *AuthService) RefreshToken(req *dto.RefreshTokenRequest, client dto.ClientInfo) (*dto.AuthResponse, error) {
+	claims, err := utils.ValidateRefreshToken(req.RefreshToken, s.keys)
 	if err != nil {
 		return nil, errors.New("invalid refresh token")
 	}
@@ -78,14 +379,59 @@ func (s *AuthService) RefreshToken(req *dto.RefreshTokenRequest) (*dto.AuthRespo
 	}
 
 	var user models.User
-	if err := s.db.Where("id = ?", claims.UserID).First(&user).Error; err != nil {
+	if err := s.db.Where("id = ? AND is_active = ?", claims.UserID, true).First(&user).Error; err != nil {
 		return nil, errors.New("user not found")
 	}
 
-	// Optional: Delete old refresh token before generating new one
-	s.db.Delete(&refreshToken)
+	// Rotate the token in place so the session keeps its identity across refreshes
+	accessToken, newRefreshToken, err := s.generateTokens(refreshToken.ID, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(&refreshToken).Updates(map[string]interface{}{
+		"token":        newRefreshToken,
+		"user_agent":   truncate(client.UserAgent, 512),
+		"ip_address":   client.IPAddress,
+		"last_used_at": time.Now(),
+		"expires_at":   time.Now().Add(s.config.JWT.RefreshTokenExpires),
+	}).Error; err != nil {
+		return nil, errors.New("failed to save refresh token")
+	}
 
-	return s.generateAuthResponse(&user)
+	return buildAuthResponse(&user, accessToken, newRefreshToken), nil
+}
+
+// ValidateAccessToken verifies an access token and checks that the session it
+// was issued for has not been signed out or expired
+func (s *AuthService) ValidateAccessToken(token string) (*utils.JWTClaims, error) {
+	claims, err := utils.ValidateAccessToken(token, s.keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var session models.RefreshToken
+	if err := s.db.Select("id", "last_used_at", "impersonator_id").
+		Where("id = ? AND user_id = ? AND expires_at > ?", claims.SessionID, claims.UserID, time.Now()).
+		First(&session).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	// A token must claim exactly the impersonator its session was opened by
+	var impersonatorID uint
+	if session.ImpersonatorID != nil {
+		impersonatorID = *session.ImpersonatorID
+	}
+	if impersonatorID != claims.ImpersonatorID {
+		return nil, ErrSessionNotFound
+	}
+
+	// Only touch last_used_at once a minute to avoid a write on every request
+	if time.Since(session.LastUsedAt) > time.Minute {
+		s.db.Model(&session).Update("last_used_at", time.Now())
+	}
+
+	return claims, nil
 }
 
 func (s *AuthService) Logout(refreshToken string) error {
@@ -99,27 +445,273 @@ func (s *AuthService) Logout(refreshToken string) error {
 	return nil
 }
 
-func (s *AuthService) generateAuthResponse(user *models.User) (*dto.AuthResponse, error) {
-	accessToken, refreshToken, err := utils.GenerateJWTToken(
-		&s.config.JWT,
-		user.ID,
-		user.Email,
-		string(user.Role),
-	)
+// JWKS returns the public keys other services use to verify our tokens
+func (s *AuthService) JWKS() utils.JWKS {
+	return s.keys.JWKS()
+}
+
+// generateAuthResponse starts a new session for the user and issues its token pair
+func (s *AuthService) generateAuthResponse(user *models.User, client dto.ClientInfo) (*dto.AuthResponse, error) {
+	var accessToken, refreshToken string
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// The session row is created first so its ID can be embedded in the tokens
+		placeholder, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			return err
+		}
+
+		session := &models.RefreshToken{
+			UserID:     user.ID,
+			Token:      placeholder,
+			UserAgent:  truncate(client.UserAgent, 512),
+			IPAddress:  client.IPAddress,
+			LastUsedAt: time.Now(),
+			ExpiresAt:  time.Now().Add(s.config.JWT.RefreshTokenExpires),
+		}
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+
+		accessToken, refreshToken, err = s.generateTokens(session.ID, user)
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(session).Update("token", refreshToken).Error
+	})
 	if err != nil {
-		return nil, errors.New("failed to generate tokens")
+		return nil, errors.New("failed to save refresh token")
 	}
 
-	refreshTokenModel := &models.RefreshToken{
-		UserID:    user.ID,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(s.config.JWT.RefreshTokenExpires),
+	return buildAuthResponse(user, accessToken, refreshToken), nil
+}
+
+func (s *AuthService) generateTokens(sessionID uint, user *models.User) (accessToken, refreshToken string, err error) {
+	permissions, err := permissionsForRole(s.db, string(user.Role))
+	if err != nil {
+		return "", "", errors.New("failed to load permissions")
 	}
 
-	if err := s.db.Create(refreshTokenModel).Error; err != nil {
-		return nil, errors.New("failed to save refresh token")
+	accessToken, refreshToken, err = utils.GenerateJWTToken(s.keys, &s.config.JWT, &utils.TokenSubject{
+		SessionID:   sessionID,
+		UserID:      user.ID,
+		Email:       user.Email,
+		Role:        string(user.Role),
+		Permissions: permissions,
+	})
+	if err != nil {
+		return "", "", errors.New("failed to generate tokens")
+	}
+	return accessToken, refreshToken, nil
+}
+
+// Impersonate issues a short-lived access token that lets a member of staff act as a customer.
+// Only accounts whose role grants no permissions can be impersonated, so staff can never
+// borrow another employee's access.
+func (s *AuthService) Impersonate(actorID, subjectID uint, req *dto.ImpersonateRequest, client dto.ClientInfo) (*dto.ImpersonationTokenResponse, error) {
+	if actorID == subjectID {
+		return nil, errors.New("you cannot impersonate yourself")
+	}
+
+	var subject models.User
+	if err := s.db.Where("id = ? AND is_active = ?", subjectID, true).First(&subject).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	permissions, err := permissionsForRole(s.db, string(subject.Role))
+	if err != nil {
+		return nil, errors.New("failed to load permissions")
+	}
+	if len(permissions) > 0 {
+		return nil, errors.New("only customer accounts can be impersonated")
 	}
 
+	expiresAt := time.Now().Add(s.config.JWT.ImpersonationExpires)
+	var impersonation models.Impersonation
+	var accessToken string
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// The session is never handed out as a refresh token, so it cannot outlive the access token
+		placeholder, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			return err
+		}
+
+		session := &models.RefreshToken{
+			UserID:         subject.ID,
+			Token:          placeholder,
+			UserAgent:      truncate(client.UserAgent, 512),
+			IPAddress:      client.IPAddress,
+			LastUsedAt:     time.Now(),
+			ImpersonatorID: &actorID,
+			ExpiresAt:      expiresAt,
+		}
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+
+		impersonation = models.Impersonation{
+			ActorID:   actorID,
+			SubjectID: subject.ID,
+			SessionID: session.ID,
+			Reason:    req.Reason,
+			IPAddress: client.IPAddress,
+			UserAgent: truncate(client.UserAgent, 512),
+			ExpiresAt: expiresAt,
+		}
+		if err := tx.Create(&impersonation).Error; err != nil {
+			return err
+		}
+
+		accessToken, err = utils.GenerateImpersonationToken(s.keys, &utils.TokenSubject{
+			SessionID:      session.ID,
+			UserID:         subject.ID,
+			Email:          subject.Email,
+			Role:           string(subject.Role),
+			Permissions:    permissions,
+			ImpersonatorID: actorID,
+		}, s.config.JWT.ImpersonationExpires)
+		return err
+	})
+	if err != nil {
+		return nil, errors.New("failed to start impersonation")
+	}
+
+	return &dto.ImpersonationTokenResponse{
+		ImpersonationID: impersonation.ID,
+		AccessToken:     accessToken,
+		ExpiresAt:       expiresAt,
+		User: dto.UserResponse{
+			ID:        subject.ID,
+			Email:     subject.Email,
+			FirstName: subject.FirstName,
+			LastName:  subject.LastName,
+			Phone:     subject.Phone,
+			Role:      string(subject.Role),
+			IsActive:  subject.IsActive,
+		},
+	}, nil
+}
+
+// EndImpersonation signs the impersonation session out before it expires
+func (s *AuthService) EndImpersonation(sessionID, actorID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND impersonator_id = ?", sessionID, actorID).Delete(&models.RefreshToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrImpersonationNotFound
+		}
+
+		return tx.Model(&models.Impersonation{}).
+			Where("session_id = ? AND ended_at IS NULL", sessionID).
+			Update("ended_at", time.Now()).Error
+	})
+}
+
+// RecordImpersonatedRequest appends a request to the audit trail of an impersonation session
+func (s *AuthService) RecordImpersonatedRequest(sessionID uint, entry *models.ImpersonationRequestLog) error {
+	var impersonation models.Impersonation
+	if err := s.db.Select("id").Where("session_id = ?", sessionID).First(&impersonation).Error; err != nil {
+		return ErrImpersonationNotFound
+	}
+
+	entry.ImpersonationID = impersonation.ID
+	return s.db.Create(entry).Error
+}
+
+// ListImpersonations returns the impersonation audit trail, newest first
+func (s *AuthService) ListImpersonations(req *dto.ListImpersonationsRequest) ([]dto.ImpersonationResponse, *utils.PaginationMeta, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	query := s.db.Model(&models.Impersonation{})
+	if req.ActorID != 0 {
+		query = query.Where("actor_id = ?", req.ActorID)
+	}
+	if req.SubjectID != 0 {
+		query = query.Where("subject_id = ?", req.SubjectID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var impersonations []models.Impersonation
+	if err := query.Preload("Actor").Preload("Subject").
+		Order("created_at DESC").Offset(offset).Limit(req.Limit).
+		Find(&impersonations).Error; err != nil {
+		return nil, nil, err
+	}
+
+	response := make([]dto.ImpersonationResponse, len(impersonations))
+	for i := range impersonations {
+		response[i] = convertToImpersonationResponse(&impersonations[i])
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	meta := &utils.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      int(total),
+		TotalPages: totalPages,
+	}
+
+	return response, meta, nil
+}
+
+// GetImpersonation returns a single impersonation with every request made during it
+func (s *AuthService) GetImpersonation(id uint) (*dto.ImpersonationResponse, error) {
+	var impersonation models.Impersonation
+	if err := s.db.Preload("Actor").Preload("Subject").
+		Preload("Requests", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		First(&impersonation, id).Error; err != nil {
+		return nil, ErrImpersonationNotFound
+	}
+
+	response := convertToImpersonationResponse(&impersonation)
+	return &response, nil
+}
+
+func convertToImpersonationResponse(impersonation *models.Impersonation) dto.ImpersonationResponse {
+	response := dto.ImpersonationResponse{
+		ID:           impersonation.ID,
+		ActorID:      impersonation.ActorID,
+		ActorEmail:   impersonation.Actor.Email,
+		SubjectID:    impersonation.SubjectID,
+		SubjectEmail: impersonation.Subject.Email,
+		Reason:       impersonation.Reason,
+		IPAddress:    impersonation.IPAddress,
+		CreatedAt:    impersonation.CreatedAt,
+		ExpiresAt:    impersonation.ExpiresAt,
+		EndedAt:      impersonation.EndedAt,
+	}
+
+	for _, request := range impersonation.Requests {
+		response.Requests = append(response.Requests, dto.ImpersonationRequestResponse{
+			Method:     request.Method,
+			Path:       request.Path,
+			StatusCode: request.StatusCode,
+			IPAddress:  request.IPAddress,
+			CreatedAt:  request.CreatedAt,
+		})
+	}
+	return response
+}
+
+func buildAuthResponse(user *models.User, accessToken, refreshToken string) *dto.AuthResponse {
 	return &dto.AuthResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -132,5 +724,18 @@ func (s *AuthService) generateAuthResponse(user *models.User) (*dto.AuthResponse
 			Role:      string(user.Role),
 			IsActive:  user.IsActive,
 		},
-	}, nil
+	}
+}
+
+// truncate shortens value to at most limit bytes without splitting a character, since
+// Postgres rejects invalid UTF-8
+func truncate(value string, limit int) string {
+	value = strings.ToValidUTF8(value, "")
+	if len(value) <= limit {
+		return value
+	}
+	for limit > 0 && !utf8.RuneStart(value[limit]) {
+		limit--
+	}
+	return value[:limit]
 }
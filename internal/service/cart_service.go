@@ -0,0 +1,487 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/config"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"gorm.io/gorm"
+)
+
+// CartOwner identifies whose cart a request works on: a signed-in user, or a guest
+// presenting the token of an anonymous cart
+type CartOwner struct {
+	UserID    uint
+	CartToken string
+}
+
+type CartService struct {
+	db             *gorm.DB
+	keys           *utils.KeySet
+	config         *config.CartConfig
+	productService *ProductService
+	taxCalculator  TaxCalculator
+}
+
+func NewCartService(db *gorm.DB, keys *utils.KeySet, cfg *config.CartConfig, productService *ProductService, taxCalculator TaxCalculator) *CartService {
+	return &CartService{
+		db:             db,
+		keys:           keys,
+		config:         cfg,
+		productService: productService,
+		taxCalculator:  taxCalculator,
+	}
+}
+
+// GetCart returns the owner's cart. Guests that have not added anything yet get an empty cart.
+func (s *CartService) GetCart(owner CartOwner) (*dto.CartResponse, error) {
+	cart, err := s.findCart(owner)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return &dto.CartResponse{CartItems: []dto.CartItemResponse{}, TaxLines: []dto.TaxLineResponse{}, Promotions: []dto.AppliedPromotionResponse{}}, nil
+	}
+
+	return s.cartResponse(cart.ID)
+}
+
+// AddToCart adds a product to the cart, creating a guest cart and its token on first use
+func (s *CartService) AddToCart(owner CartOwner, req *dto.AddToCartRequest) (*dto.CartResponse, error) {
+	var product models.Product
+	if err := s.db.First(&product, req.ProductID).Error; err != nil || !product.IsActive {
+		return nil, ErrProductUnavailable
+	}
+
+	cart, err := s.findCart(owner)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		cart = &models.Cart{}
+		if owner.UserID != 0 {
+			cart.UserID = &owner.UserID
+		}
+		if err := s.db.Create(cart).Error; err != nil {
+			return nil, errors.New("failed to create cart")
+		}
+	}
+
+	var item models.CartItem
+	err = s.db.Where("cart_id = ? AND product_id = ?", cart.ID, product.ID).First(&item).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if req.Quantity > product.Stock {
+			return nil, ErrInsufficientStock
+		}
+		item = models.CartItem{CartID: cart.ID, ProductID: product.ID, Quantity: req.Quantity}
+		if err := s.db.Create(&item).Error; err != nil {
+			return nil, errors.New("failed to add item to cart")
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if item.Quantity+req.Quantity > product.Stock {
+			return nil, ErrInsufficientStock
+		}
+		if err := s.db.Model(&item).Update("quantity", item.Quantity+req.Quantity).Error; err != nil {
+			return nil, errors.New("failed to update cart item")
+		}
+	}
+
+	return s.cartResponse(cart.ID)
+}
+
+// UpdateCartItem sets the quantity of a line in the cart
+func (s *CartService) UpdateCartItem(owner CartOwner, itemID uint, req *dto.UpdateCartItemRequest) (*dto.CartResponse, error) {
+	cart, err := s.findCart(owner)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return nil, ErrCartItemNotFound
+	}
+
+	var item models.CartItem
+	if err := s.db.Preload("Product").Where("id = ? AND cart_id = ?", itemID, cart.ID).First(&item).Error; err != nil {
+		return nil, ErrCartItemNotFound
+	}
+	if req.Quantity > item.Product.Stock {
+		return nil, ErrInsufficientStock
+	}
+
+	if err := s.db.Model(&item).Update("quantity", req.Quantity).Error; err != nil {
+		return nil, errors.New("failed to update cart item")
+	}
+
+	return s.cartResponse(cart.ID)
+}
+
+// RemoveCartItem deletes a line from the cart
+func (s *CartService) RemoveCartItem(owner CartOwner, itemID uint) (*dto.CartResponse, error) {
+	cart, err := s.findCart(owner)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return nil, ErrCartItemNotFound
+	}
+
+	// Lines are removed for good so the product can be added again later
+	result := s.db.Unscoped().Where("id = ? AND cart_id = ?", itemID, cart.ID).Delete(&models.CartItem{})
+	if result.Error != nil {
+		return nil, errors.New("failed to remove cart item")
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrCartItemNotFound
+	}
+
+	return s.cartResponse(cart.ID)
+}
+
+// ClearCart removes every line from the cart
+func (s *CartService) ClearCart(owner CartOwner) error {
+	cart, err := s.findCart(owner)
+	if err != nil || cart == nil {
+		return err
+	}
+
+	if err := s.db.Unscoped().Where("cart_id = ?", cart.ID).Delete(&models.CartItem{}).Error; err != nil {
+		return errors.New("failed to clear cart")
+	}
+	return nil
+}
+
+// ApplyCoupon puts a coupon on the cart. The coupon is checked against the cart as it is
+// now, after automatic promotions; it is checked again at checkout, where it is redeemed.
+func (s *CartService) ApplyCoupon(owner CartOwner, req *dto.ApplyCouponRequest) (*dto.CartResponse, error) {
+	cart, err := s.findCart(owner)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return nil, ErrEmptyCart
+	}
+
+	cart, err = s.loadCart(cart.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.CartItems) == 0 {
+		return nil, ErrEmptyCart
+	}
+
+	coupon, err := findCouponByCode(s.db, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	promotions, err := loadActivePromotions(s.db, now)
+	if err != nil {
+		return nil, err
+	}
+	lines := cartPromotionLines(cart)
+	applyPromotions(promotions, lines)
+	if _, err := couponDiscount(coupon, couponLines(lines), now); err != nil {
+		return nil, err
+	}
+	if owner.UserID != 0 {
+		if err := checkCouponUserLimit(s.db, coupon, &owner.UserID, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.db.Model(cart).Update("coupon_id", coupon.ID).Error; err != nil {
+		return nil, errors.New("failed to apply coupon")
+	}
+
+	return s.cartResponse(cart.ID)
+}
+
+// RemoveCoupon takes the coupon off the cart
+func (s *CartService) RemoveCoupon(owner CartOwner) (*dto.CartResponse, error) {
+	cart, err := s.findCart(owner)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return &dto.CartResponse{CartItems: []dto.CartItemResponse{}, TaxLines: []dto.TaxLineResponse{}, Promotions: []dto.AppliedPromotionResponse{}}, nil
+	}
+
+	if err := s.db.Model(cart).Update("coupon_id", nil).Error; err != nil {
+		return nil, errors.New("failed to remove coupon")
+	}
+
+	return s.cartResponse(cart.ID)
+}
+
+// MergeGuestCart moves the lines of a guest cart into the user's cart when they sign in.
+// Products already in the user's cart are combined with the configured strategy, every
+// quantity is capped at the available stock, and the guest cart is removed afterwards.
+// The guest's coupon is kept unless the user's cart already has one.
+func (s *CartService) MergeGuestCart(userID uint, cartToken string) (*dto.CartResponse, error) {
+	if cartToken == "" {
+		return nil, ErrInvalidCartToken
+	}
+	guestCart, err := s.findCart(CartOwner{CartToken: cartToken})
+	if err != nil {
+		return nil, err
+	}
+
+	userCart := models.Cart{UserID: &userID}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).FirstOrCreate(&userCart).Error; err != nil {
+			return err
+		}
+
+		var userItems []models.CartItem
+		if err := tx.Where("cart_id = ?", userCart.ID).Find(&userItems).Error; err != nil {
+			return err
+		}
+		userItemsByProduct := make(map[uint]*models.CartItem, len(userItems))
+		for i := range userItems {
+			userItemsByProduct[userItems[i].ProductID] = &userItems[i]
+		}
+
+		var guestItems []models.CartItem
+		if err := tx.Preload("Product").Where("cart_id = ?", guestCart.ID).Order("id").Find(&guestItems).Error; err != nil {
+			return err
+		}
+
+		for _, guestItem := range guestItems {
+			// Preload leaves Product empty when the product was deleted meanwhile
+			if guestItem.Product.ID == 0 || !guestItem.Product.IsActive || guestItem.Product.Stock <= 0 {
+				continue
+			}
+
+			userItem, ok := userItemsByProduct[guestItem.ProductID]
+			if !ok {
+				if err := tx.Create(&models.CartItem{
+					CartID:    userCart.ID,
+					ProductID: guestItem.ProductID,
+					Quantity:  min(guestItem.Quantity, guestItem.Product.Stock),
+				}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			quantity := min(mergeQuantities(s.config.MergeStrategy, userItem.Quantity, guestItem.Quantity), guestItem.Product.Stock)
+			if quantity != userItem.Quantity {
+				if err := tx.Model(userItem).Update("quantity", quantity).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if userCart.CouponID == nil && guestCart.CouponID != nil {
+			if err := tx.Model(&userCart).Update("coupon_id", *guestCart.CouponID).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Where("cart_id = ?", guestCart.ID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(guestCart).Error
+	})
+	if err != nil {
+		return nil, errors.New("failed to merge guest cart")
+	}
+
+	return s.cartResponse(userCart.ID)
+}
+
+// mergeQuantities combines the quantities of a product found in both carts
+func mergeQuantities(strategy string, userQuantity, guestQuantity int) int {
+	switch strategy {
+	case config.CartMergeMax:
+		return max(userQuantity, guestQuantity)
+	case config.CartMergeKeepUser:
+		return userQuantity
+	case config.CartMergeKeepGuest:
+		return guestQuantity
+	default:
+		return userQuantity + guestQuantity
+	}
+}
+
+// findCart looks up the owner's cart, returning nil when there is none yet.
+// A signed-in user always works on their own cart, even if a cart token is sent as well.
+func (s *CartService) findCart(owner CartOwner) (*models.Cart, error) {
+	query := s.db.Model(&models.Cart{})
+	switch {
+	case owner.UserID != 0:
+		query = query.Where("user_id = ?", owner.UserID)
+	case owner.CartToken != "":
+		cartID, err := utils.ValidateCartToken(owner.CartToken, s.keys)
+		if err != nil {
+			return nil, ErrInvalidCartToken
+		}
+		query = query.Where("id = ? AND user_id IS NULL", cartID)
+	default:
+		return nil, nil
+	}
+
+	var cart models.Cart
+	err := query.First(&cart).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound) && owner.UserID == 0:
+		// The guest cart was checked out or cleaned up since the token was issued
+		return nil, ErrInvalidCartToken
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// loadCart loads a cart with its products and coupon
+func (s *CartService) loadCart(cartID uint) (*models.Cart, error) {
+	var cart models.Cart
+	if err := s.db.Preload("CartItems", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("CartItems.Product.Category").Preload("CartItems.Product.Images").
+		Preload("Coupon.Products").Preload("Coupon.Categories").
+		First(&cart, cartID).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// cartResponse reloads a cart with its products. Guest carts get a fresh token so
+// they stay alive for as long as the guest keeps shopping.
+func (s *CartService) cartResponse(cartID uint) (*dto.CartResponse, error) {
+	cart, err := s.loadCart(cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.convertToCartResponse(cart)
+	if err != nil {
+		return nil, err
+	}
+	if cart.UserID == nil {
+		token, err := utils.GenerateCartToken(s.keys, cart.ID, s.config.GuestTokenExpires)
+		if err != nil {
+			return nil, errors.New("failed to generate cart token")
+		}
+		response.CartToken = token
+	}
+	return response, nil
+}
+
+// convertToCartResponse prices the cart: automatic promotions first, then the coupon on
+// what the promotions left, then an estimate of the tax on the discounted lines
+func (s *CartService) convertToCartResponse(cart *models.Cart) (*dto.CartResponse, error) {
+	now := time.Now()
+	promotions, err := loadActivePromotions(s.db, now)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.CartResponse{
+		ID:         cart.ID,
+		UserID:     cart.UserID,
+		CartItems:  make([]dto.CartItemResponse, len(cart.CartItems)),
+		Promotions: []dto.AppliedPromotionResponse{},
+		CreatedAt:  cart.CreatedAt,
+		UpdatedAt:  cart.UpdatedAt,
+	}
+
+	for i := range cart.CartItems {
+		item := &cart.CartItems[i]
+		subtotal := float64(item.Quantity) * item.Product.CurrentPrice()
+		response.CartItems[i] = dto.CartItemResponse{
+			ID:        item.ID,
+			Product:   s.productService.convertToProductResponse(&item.Product),
+			Quantity:  item.Quantity,
+			Subtotal:  subtotal,
+			CreatedAt: item.CreatedAt,
+			UpdatedAt: item.UpdatedAt,
+		}
+		response.Subtotal += subtotal
+	}
+	response.Subtotal = roundMoney(response.Subtotal)
+
+	lines := cartPromotionLines(cart)
+	for _, result := range applyPromotions(promotions, lines) {
+		response.Promotions = append(response.Promotions, convertToAppliedPromotionResponse(&result))
+		response.Discount += result.Amount
+	}
+
+	// A coupon that no longer applies stays on the cart with the reason, so the
+	// customer can see why, and starts counting again once the cart qualifies
+	if cart.Coupon != nil {
+		applied := &dto.AppliedCouponResponse{
+			Code:        cart.Coupon.Code,
+			Type:        string(cart.Coupon.Type),
+			Description: cart.Coupon.Description,
+		}
+		discount, err := couponDiscount(cart.Coupon, couponLines(lines), now)
+		if err != nil {
+			applied.Error = err.Error()
+		} else {
+			applied.Discount = discount
+			response.Discount += discount
+			allocateCouponDiscount(cart.Coupon, lines, discount)
+		}
+		response.Coupon = applied
+	}
+
+	taxable := make([]TaxableLine, len(lines))
+	for i := range lines {
+		taxable[i] = TaxableLine{TaxClass: cart.CartItems[i].Product.TaxClass, Amount: roundMoney(lines[i].remaining())}
+	}
+	tax, err := s.taxCalculator.Calculate(s.cartTaxLocation(cart), taxable)
+	if err != nil {
+		return nil, err
+	}
+	for i := range response.CartItems {
+		response.CartItems[i].Tax = tax.LineTotals[i]
+	}
+	response.Tax = tax.Total
+	response.TaxLines = summarizeTaxLines(tax.Lines)
+	response.PricesIncludeTax = tax.Inclusive
+
+	response.Discount = roundMoney(response.Discount)
+	response.Total = roundMoney(response.Subtotal - response.Discount)
+	if !tax.Inclusive {
+		response.Total = roundMoney(response.Total + tax.Total)
+	}
+	return response, nil
+}
+
+// cartTaxLocation estimates where the cart will ship: the user's default shipping
+// address, or the configured default location for guests and users without one
+func (s *CartService) cartTaxLocation(cart *models.Cart) TaxLocation {
+	if cart.UserID == nil {
+		return TaxLocation{}
+	}
+
+	var address models.Address
+	if err := s.db.Where("user_id = ? AND is_default_shipping = ?", *cart.UserID, true).
+		First(&address).Error; err != nil {
+		return TaxLocation{}
+	}
+	return TaxLocation{CountryCode: address.CountryCode, Region: address.Region}
+}
+
+// cartPromotionLines turns the loaded cart lines into what the discount rules look at
+func cartPromotionLines(cart *models.Cart) []promotionLine {
+	lines := make([]promotionLine, len(cart.CartItems))
+	for i := range cart.CartItems {
+		item := &cart.CartItems[i]
+		lines[i] = promotionLine{
+			ProductID:  item.ProductID,
+			CategoryID: item.Product.CategoryID,
+			UnitPrice:  item.Product.CurrentPrice(),
+			Quantity:   item.Quantity,
+		}
+	}
+	return lines
+}
@@ -0,0 +1,467 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"gorm.io/gorm"
+)
+
+// couponLine is a cart or order line as seen by the coupon rules
+type couponLine struct {
+	ProductID  uint
+	CategoryID uint
+	Amount     float64
+}
+
+// CouponService lets staff manage discount codes
+type CouponService struct {
+	db *gorm.DB
+}
+
+func NewCouponService(db *gorm.DB) *CouponService {
+	return &CouponService{
+		db: db,
+	}
+}
+
+func (s *CouponService) CreateCoupon(req *dto.CouponRequest) (*dto.CouponResponse, error) {
+	coupon := models.Coupon{IsActive: true}
+	if err := applyCouponRequest(&coupon, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkCodeAvailable(coupon.Code, 0); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if coupon.Products, coupon.Categories, err = findDiscountTargets(s.db, req.ProductIDs, req.CategoryIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(&coupon).Error; err != nil {
+		return nil, errors.New("failed to create coupon")
+	}
+
+	response := convertToCouponResponse(&coupon)
+	return &response, nil
+}
+
+// ListCoupons returns coupons, most recently created first
+func (s *CouponService) ListCoupons(req *dto.ListCouponsRequest) ([]dto.CouponResponse, *utils.PaginationMeta, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	query := s.db.Model(&models.Coupon{})
+	if req.Code != "" {
+		query = query.Where("code LIKE ?", "%"+escapeLike(strings.ToUpper(req.Code))+"%")
+	}
+	if req.IsActive != nil {
+		query = query.Where("is_active = ?", *req.IsActive)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var coupons []models.Coupon
+	if err := query.Preload("Products").Preload("Categories").
+		Order("created_at DESC").Offset(offset).Limit(req.Limit).Find(&coupons).Error; err != nil {
+		return nil, nil, err
+	}
+
+	response := make([]dto.CouponResponse, len(coupons))
+	for i := range coupons {
+		response[i] = convertToCouponResponse(&coupons[i])
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	meta := &utils.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      int(total),
+		TotalPages: totalPages,
+	}
+
+	return response, meta, nil
+}
+
+func (s *CouponService) GetCoupon(couponID uint) (*dto.CouponResponse, error) {
+	var coupon models.Coupon
+	if err := s.db.Preload("Products").Preload("Categories").First(&coupon, couponID).Error; err != nil {
+		return nil, ErrCouponNotFound
+	}
+
+	response := convertToCouponResponse(&coupon)
+	return &response, nil
+}
+
+// UpdateCoupon replaces a coupon. Orders keep the discount lines they were placed with.
+func (s *CouponService) UpdateCoupon(couponID uint, req *dto.CouponRequest) (*dto.CouponResponse, error) {
+	var coupon models.Coupon
+	if err := s.db.First(&coupon, couponID).Error; err != nil {
+		return nil, ErrCouponNotFound
+	}
+
+	if err := applyCouponRequest(&coupon, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkCodeAvailable(coupon.Code, coupon.ID); err != nil {
+		return nil, err
+	}
+
+	products, categories, err := findDiscountTargets(s.db, req.ProductIDs, req.CategoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Products", "Categories").Save(&coupon).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&coupon).Association("Products").Replace(products); err != nil {
+			return err
+		}
+		return tx.Model(&coupon).Association("Categories").Replace(categories)
+	})
+	if err != nil {
+		return nil, errors.New("failed to update coupon")
+	}
+
+	return s.GetCoupon(coupon.ID)
+}
+
+// DeleteCoupon retires a coupon and takes it off every cart it was applied to
+func (s *CouponService) DeleteCoupon(couponID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Coupon{}, couponID)
+		if result.Error != nil {
+			return errors.New("failed to delete coupon")
+		}
+		if result.RowsAffected == 0 {
+			return ErrCouponNotFound
+		}
+
+		return tx.Model(&models.Cart{}).Where("coupon_id = ?", couponID).Update("coupon_id", nil).Error
+	})
+}
+
+func (s *CouponService) checkCodeAvailable(code string, couponID uint) error {
+	var count int64
+	if err := s.db.Model(&models.Coupon{}).Where("code = ? AND id <> ?", code, couponID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("a coupon with this code already exists")
+	}
+	return nil
+}
+
+// findDiscountTargets loads the products and categories a coupon or promotion is restricted to
+func findDiscountTargets(db *gorm.DB, productIDs, categoryIDs []uint) ([]models.Product, []models.Category, error) {
+	var products []models.Product
+	if len(productIDs) > 0 {
+		if err := db.Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+			return nil, nil, err
+		}
+		if len(products) != len(uniqueIDs(productIDs)) {
+			return nil, nil, errors.New("product_ids contains an unknown product")
+		}
+	}
+
+	var categories []models.Category
+	if len(categoryIDs) > 0 {
+		if err := db.Where("id IN ?", categoryIDs).Find(&categories).Error; err != nil {
+			return nil, nil, err
+		}
+		if len(categories) != len(uniqueIDs(categoryIDs)) {
+			return nil, nil, errors.New("category_ids contains an unknown category")
+		}
+	}
+	return products, categories, nil
+}
+
+func applyCouponRequest(coupon *models.Coupon, req *dto.CouponRequest) error {
+	couponType := models.CouponType(req.Type)
+	switch {
+	case couponType == models.CouponTypePercentage && (req.Value <= 0 || req.Value > 100):
+		return errors.New("a percentage coupon needs a value between 0 and 100")
+	case couponType == models.CouponTypeFixed && req.Value <= 0:
+		return errors.New("a fixed coupon needs a value above 0")
+	case req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt):
+		return errors.New("ends_at must be after starts_at")
+	}
+
+	coupon.Code = normalizeCouponCode(req.Code)
+	coupon.Description = strings.TrimSpace(req.Description)
+	coupon.Type = couponType
+	coupon.Value = req.Value
+	coupon.MinimumSpend = req.MinimumSpend
+	coupon.MaxUses = req.MaxUses
+	coupon.MaxUsesPerUser = req.MaxUsesPerUser
+	coupon.StartsAt = req.StartsAt
+	coupon.EndsAt = req.EndsAt
+	if req.IsActive != nil {
+		coupon.IsActive = *req.IsActive
+	}
+	if coupon.Type == models.CouponTypeFreeShipping {
+		coupon.Value = 0
+	}
+	return nil
+}
+
+// findCouponByCode loads a live coupon with its restrictions
+func findCouponByCode(db *gorm.DB, code string) (*models.Coupon, error) {
+	var coupon models.Coupon
+	if err := db.Preload("Products").Preload("Categories").
+		Where("code = ?", normalizeCouponCode(code)).First(&coupon).Error; err != nil {
+		return nil, ErrCouponNotFound
+	}
+	return &coupon, nil
+}
+
+// couponDiscount works out what a coupon takes off the given lines, or why it does not apply.
+// The global usage limit is only checked loosely here; redeemCoupon enforces it.
+func couponDiscount(coupon *models.Coupon, lines []couponLine, now time.Time) (float64, error) {
+	switch {
+	case !coupon.IsActive:
+		return 0, fmt.Errorf("%w: coupon is not active", ErrCouponNotApplicable)
+	case coupon.StartsAt != nil && now.Before(*coupon.StartsAt):
+		return 0, fmt.Errorf("%w: coupon is not valid yet", ErrCouponNotApplicable)
+	case coupon.EndsAt != nil && !now.Before(*coupon.EndsAt):
+		return 0, fmt.Errorf("%w: coupon has expired", ErrCouponNotApplicable)
+	case coupon.MaxUses != nil && coupon.UsedCount >= *coupon.MaxUses:
+		return 0, ErrCouponUsageLimit
+	}
+
+	var subtotal, eligible float64
+	for _, line := range lines {
+		subtotal += line.Amount
+		if couponCovers(coupon, line) {
+			eligible += line.Amount
+		}
+	}
+	if subtotal < coupon.MinimumSpend {
+		return 0, fmt.Errorf("%w: minimum spend is %.2f", ErrCouponNotApplicable, coupon.MinimumSpend)
+	}
+	if eligible == 0 {
+		return 0, fmt.Errorf("%w: no items in the cart qualify", ErrCouponNotApplicable)
+	}
+
+	switch coupon.Type {
+	case models.CouponTypePercentage:
+		return roundMoney(eligible * coupon.Value / 100), nil
+	case models.CouponTypeFixed:
+		return roundMoney(math.Min(coupon.Value, eligible)), nil
+	default:
+		// Free shipping takes nothing off the items, it waives the shipping charge
+		return 0, nil
+	}
+}
+
+// couponCovers reports whether a line is eligible. Unrestricted coupons cover every line.
+func couponCovers(coupon *models.Coupon, line couponLine) bool {
+	if len(coupon.Products) == 0 && len(coupon.Categories) == 0 {
+		return true
+	}
+	return targetsCover(coupon.Products, coupon.Categories, line.ProductID, line.CategoryID)
+}
+
+// targetsCover reports whether a product is one of the products, or in one of the
+// categories, a coupon or promotion is restricted to
+func targetsCover(products []models.Product, categories []models.Category, productID, categoryID uint) bool {
+	for i := range products {
+		if products[i].ID == productID {
+			return true
+		}
+	}
+	for i := range categories {
+		if categories[i].ID == categoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCouponUserLimit counts earlier orders of the customer, matched by account or by
+// email so guests are limited too, that used the coupon
+func checkCouponUserLimit(db *gorm.DB, coupon *models.Coupon, userID *uint, email string) error {
+	if coupon.MaxUsesPerUser == nil {
+		return nil
+	}
+
+	query := db.Model(&models.OrderDiscount{}).
+		Joins("JOIN orders ON orders.id = order_discounts.order_id").
+		Where("order_discounts.coupon_id = ?", coupon.ID)
+	switch {
+	case userID != nil && email != "":
+		query = query.Where("(orders.user_id = ? OR orders.email = ?)", *userID, email)
+	case userID != nil:
+		query = query.Where("orders.user_id = ?", *userID)
+	case email != "":
+		query = query.Where("orders.email = ?", email)
+	default:
+		return nil
+	}
+
+	var used int64
+	if err := query.Count(&used).Error; err != nil {
+		return err
+	}
+	if used >= int64(*coupon.MaxUsesPerUser) {
+		return ErrCouponUsageLimit
+	}
+	return nil
+}
+
+// redeemCoupon applies a coupon to an order being placed and counts the use. The
+// conditional update locks the coupon row until the transaction ends, so concurrent
+// checkouts queue up behind it and neither limit can be exceeded.
+func redeemCoupon(tx *gorm.DB, couponID uint, order *models.Order, lines []promotionLine) error {
+	var coupon models.Coupon
+	if err := tx.Preload("Products").Preload("Categories").First(&coupon, couponID).Error; err != nil {
+		return ErrCouponNotFound
+	}
+
+	amount, err := couponDiscount(&coupon, couponLines(lines), time.Now())
+	if err != nil {
+		return err
+	}
+	allocateCouponDiscount(&coupon, lines, amount)
+
+	result := tx.Model(&models.Coupon{}).
+		Where("id = ? AND (max_uses IS NULL OR used_count < max_uses)", coupon.ID).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCouponUsageLimit
+	}
+
+	if err := checkCouponUserLimit(tx, &coupon, order.UserID, order.Email); err != nil {
+		return err
+	}
+
+	description := coupon.Description
+	if description == "" {
+		description = "Coupon " + coupon.Code
+	}
+	order.Discounts = append(order.Discounts, models.OrderDiscount{
+		Source:      models.DiscountSourceCoupon,
+		CouponID:    &coupon.ID,
+		Code:        coupon.Code,
+		Type:        string(coupon.Type),
+		Description: description,
+		Amount:      amount,
+	})
+	order.DiscountTotal = roundMoney(order.DiscountTotal + amount)
+	order.TotalAmount = roundMoney(order.TotalAmount - amount)
+	return nil
+}
+
+// applyFreeShipping sets the discount line of a free shipping coupon, recorded at zero by
+// redeemCoupon, to the shipping charge it waives once the shipping method is chosen
+func applyFreeShipping(order *models.Order) {
+	for i := range order.Discounts {
+		discount := &order.Discounts[i]
+		if discount.Source != models.DiscountSourceCoupon || discount.Type != string(models.CouponTypeFreeShipping) {
+			continue
+		}
+		discount.Amount = order.ShippingCost
+		order.DiscountTotal = roundMoney(order.DiscountTotal + order.ShippingCost)
+		order.TotalAmount = roundMoney(order.TotalAmount - order.ShippingCost)
+	}
+}
+
+// allocateCouponDiscount spreads a coupon's discount over the lines it covers, in
+// proportion to what is left of them, so tax is charged on the discounted amounts
+func allocateCouponDiscount(coupon *models.Coupon, lines []promotionLine, amount float64) {
+	shares := make([]float64, len(lines))
+	var eligible float64
+	last := -1
+	for i := range lines {
+		covered := couponCovers(coupon, couponLine{ProductID: lines[i].ProductID, CategoryID: lines[i].CategoryID})
+		if covered && lines[i].remaining() > 0 {
+			shares[i] = lines[i].remaining()
+			eligible += shares[i]
+			last = i
+		}
+	}
+	if eligible <= 0 || amount <= 0 {
+		return
+	}
+
+	// The last covered line takes the rounding difference
+	left := amount
+	for i := range lines {
+		if shares[i] == 0 {
+			continue
+		}
+		share := roundMoney(amount * shares[i] / eligible)
+		if i == last {
+			share = roundMoney(left)
+		}
+		lines[i].Discount += share
+		left -= share
+	}
+}
+
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// roundMoney rounds an amount to whole cents
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func uniqueIDs(ids []uint) map[uint]struct{} {
+	unique := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		unique[id] = struct{}{}
+	}
+	return unique
+}
+
+func convertToCouponResponse(coupon *models.Coupon) dto.CouponResponse {
+	response := dto.CouponResponse{
+		ID:             coupon.ID,
+		Code:           coupon.Code,
+		Description:    coupon.Description,
+		Type:           string(coupon.Type),
+		Value:          coupon.Value,
+		MinimumSpend:   coupon.MinimumSpend,
+		MaxUses:        coupon.MaxUses,
+		MaxUsesPerUser: coupon.MaxUsesPerUser,
+		UsedCount:      coupon.UsedCount,
+		StartsAt:       coupon.StartsAt,
+		EndsAt:         coupon.EndsAt,
+		IsActive:       coupon.IsActive,
+		ProductIDs:     make([]uint, len(coupon.Products)),
+		CategoryIDs:    make([]uint, len(coupon.Categories)),
+		CreatedAt:      coupon.CreatedAt,
+		UpdatedAt:      coupon.UpdatedAt,
+	}
+	for i := range coupon.Products {
+		response.ProductIDs[i] = coupon.Products[i].ID
+	}
+	for i := range coupon.Categories {
+		response.CategoryIDs[i] = coupon.Categories[i].ID
+	}
+	return response
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/config"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/mailer"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// outboxClaimDuration keeps other senders off an email while one is sending it
+	outboxClaimDuration = 5 * time.Minute
+	// outboxBatchSize is how many due emails are retried per run
+	outboxBatchSize = 50
+	// outboxMaxRetryDelay caps the wait between attempts
+	outboxMaxRetryDelay = 6 * time.Hour
+)
+
+// EmailOutbox is the mailer services send through. Every email is saved to the outbox
+// and handed to the transport right away; one that fails stays pending and Run retries
+// it with a growing delay until it is sent or runs out of attempts.
+type EmailOutbox struct {
+	db        *gorm.DB
+	transport mailer.Mailer
+	config    *config.MailConfig
+	logger    zerolog.Logger
+}
+
+func NewEmailOutbox(db *gorm.DB, transport mailer.Mailer, cfg *config.MailConfig, logger zerolog.Logger) *EmailOutbox {
+	return &EmailOutbox{
+		db:        db,
+		transport: transport,
+		config:    cfg,
+		logger:    logger,
+	}
+}
+
+// Send queues the email and makes the first attempt. Only failing to queue it is an
+// error; a failed attempt is retried later.
+func (o *EmailOutbox) Send(msg *mailer.Message) error {
+	email := models.OutboxEmail{
+		Recipient:     msg.To,
+		Subject:       msg.Subject,
+		TextBody:      msg.Body,
+		HTMLBody:      msg.HTMLBody,
+		Status:        models.OutboxEmailPending,
+		NextAttemptAt: time.Now().Add(outboxClaimDuration),
+	}
+	if err := o.db.Create(&email).Error; err != nil {
+		return errors.New("failed to queue email")
+	}
+
+	o.deliver(&email)
+	return nil
+}
+
+// Transport returns the mailer the outbox delivers with, for the rare email no copy
+// may be kept of
+func (o *EmailOutbox) Transport() mailer.Mailer {
+	return o.transport
+}
+
+// Run retries due emails right away and then every interval until the context is cancelled
+func (o *EmailOutbox) Run(ctx context.Context, interval time.Duration) {
+	o.retry(time.Now())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			o.retry(now)
+		}
+	}
+}
+
+// RetryDue makes another attempt at the pending emails whose retry time has come
+func (o *EmailOutbox) RetryDue(now time.Time) (sent, failed int, err error) {
+	var emails []models.OutboxEmail
+	err = o.db.Transaction(func(tx *gorm.DB) error {
+		// Claim the batch so that other instances skip it
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.OutboxEmailPending, now).
+			Order("next_attempt_at, id").Limit(outboxBatchSize).Find(&emails).Error; err != nil {
+			return err
+		}
+		if len(emails) == 0 {
+			return nil
+		}
+
+		emailIDs := make([]uint, len(emails))
+		for i := range emails {
+			emailIDs[i] = emails[i].ID
+		}
+		return tx.Model(&models.OutboxEmail{}).Where("id IN ?", emailIDs).
+			Update("next_attempt_at", now.Add(outboxClaimDuration)).Error
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for i := range emails {
+		if o.deliver(&emails[i]) {
+			sent++
+		} else {
+			failed++
+		}
+	}
+	return sent, failed, nil
+}
+
+// ListEmails returns outbox emails for support, newest first. Bodies are not shown.
+func (o *EmailOutbox) ListEmails(req *dto.ListOutboxEmailsRequest) ([]dto.OutboxEmailResponse, *utils.PaginationMeta, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	query := o.db.Model(&models.OutboxEmail{})
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Recipient != "" {
+		query = query.Where("LOWER(recipient) = ?", normalizeEmail(req.Recipient))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var emails []models.OutboxEmail
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(req.Limit).Find(&emails).Error; err != nil {
+		return nil, nil, err
+	}
+
+	response := make([]dto.OutboxEmailResponse, len(emails))
+	for i := range emails {
+		response[i] = convertToOutboxEmailResponse(&emails[i])
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	meta := &utils.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      int(total),
+		TotalPages: totalPages,
+	}
+
+	return response, meta, nil
+}
+
+// RetryEmail gives an email that ran out of attempts a fresh set and tries it now
+func (o *EmailOutbox) RetryEmail(emailID uint) (*dto.OutboxEmailResponse, error) {
+	var email models.OutboxEmail
+	err := o.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&email, emailID).Error; err != nil {
+			return ErrOutboxEmailNotFound
+		}
+		if email.Status != models.OutboxEmailFailed {
+			return ErrEmailNotRetryable
+		}
+
+		email.Status = models.OutboxEmailPending
+		email.Attempts = 0
+		email.NextAttemptAt = time.Now().Add(outboxClaimDuration)
+		return tx.Model(&email).Updates(map[string]interface{}{
+			"status":          email.Status,
+			"attempts":        email.Attempts,
+			"next_attempt_at": email.NextAttemptAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	o.deliver(&email)
+	if err := o.db.First(&email, email.ID).Error; err != nil {
+		return nil, err
+	}
+
+	response := convertToOutboxEmailResponse(&email)
+	return &response, nil
+}
+
+// deliver makes one attempt at an email and records how it went
+func (o *EmailOutbox) deliver(email *models.OutboxEmail) bool {
+	sendErr := o.transport.Send(&mailer.Message{
+		To:       email.Recipient,
+		Subject:  email.Subject,
+		Body:     email.TextBody,
+		HTMLBody: email.HTMLBody,
+	})
+
+	email.Attempts++
+	now := time.Now()
+	updates := map[string]interface{}{"attempts": email.Attempts}
+	if sendErr == nil {
+		// The bodies may hold one-time links, so they are not kept once delivered
+		updates["status"] = models.OutboxEmailSent
+		updates["sent_at"] = now
+		updates["last_error"] = ""
+		updates["text_body"] = ""
+		updates["html_body"] = ""
+	} else {
+		updates["last_error"] = sendErr.Error()
+		if email.Attempts >= o.config.MaxAttempts {
+			updates["status"] = models.OutboxEmailFailed
+		} else {
+			updates["next_attempt_at"] = now.Add(outboxRetryDelay(email.Attempts))
+		}
+		o.logger.Warn().Err(sendErr).Uint("email_id", email.ID).Int("attempts", email.Attempts).Msg("Failed to send email")
+	}
+
+	if err := o.db.Model(&models.OutboxEmail{}).Where("id = ?", email.ID).Updates(updates).Error; err != nil {
+		o.logger.Error().Err(err).Uint("email_id", email.ID).Msg("Failed to record email delivery")
+	}
+	return sendErr == nil
+}
+
+func (o *EmailOutbox) retry(now time.Time) {
+	sent, failed, err := o.RetryDue(now)
+	if err != nil {
+		o.logger.Error().Err(err).Msg("Failed to retry emails")
+		return
+	}
+	if sent > 0 || failed > 0 {
+		o.logger.Info().Int("sent", sent).Int("failed", failed).Msg("Emails retried")
+	}
+}
+
+// outboxRetryDelay doubles from a minute with each failed attempt, up to a cap
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := time.Minute
+	for i := 1; i < attempts && delay < outboxMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, outboxMaxRetryDelay)
+}
+
+func convertToOutboxEmailResponse(email *models.OutboxEmail) dto.OutboxEmailResponse {
+	return dto.OutboxEmailResponse{
+		ID:            email.ID,
+		Recipient:     email.Recipient,
+		Subject:       email.Subject,
+		Status:        string(email.Status),
+		Attempts:      email.Attempts,
+		NextAttemptAt: email.NextAttemptAt,
+		LastError:     email.LastError,
+		SentAt:        email.SentAt,
+		CreatedAt:     email.CreatedAt,
+	}
+}
@@ -1,11 +1,95 @@
 package service
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
-	ErrUserNotFound       = errors.New("user not found")
-	ErrValidationFailed   = errors.New("validation failed")
-	ErrDuplicateEmail     = errors.New("email already exists")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrUnauthorized       = errors.New("unauthorized access")
+	ErrUserNotFound           = errors.New("user not found")
+	ErrValidationFailed       = errors.New("validation failed")
+	ErrDuplicateEmail         = errors.New("email already exists")
+	ErrInvalidCredentials     = errors.New("invalid credentials")
+	ErrUnauthorized           = errors.New("unauthorized access")
+	ErrSessionNotFound        = errors.New("session not found")
+	ErrAccountLocked          = errors.New("too many failed login attempts")
+	ErrInvalidResetToken      = errors.New("invalid or expired reset token")
+	ErrInvalidEmailToken      = errors.New("invalid or expired confirmation token")
+	ErrImpersonationNotFound  = errors.New("impersonation not found")
+	ErrIdentityNotFound       = errors.New("linked identity not found")
+	ErrLastSignInMethod       = errors.New("set a password before unlinking your last sign-in provider")
+	ErrAddressNotFound        = errors.New("address not found")
+	ErrInvalidPostalCode      = errors.New("invalid postal code")
+	ErrErasureRequestNotFound = errors.New("erasure request not found")
+	ErrErasureRequestPending  = errors.New("an erasure request is already pending")
+	ErrInvalidCartToken       = errors.New("invalid or expired cart token")
+	ErrCartItemNotFound       = errors.New("cart item not found")
+	ErrEmptyCart              = errors.New("cart is empty")
+	ErrProductUnavailable     = errors.New("product is not available")
+	ErrInsufficientStock      = errors.New("insufficient stock")
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrOrderNotPending        = errors.New("only pending orders can be confirmed")
+	ErrGuestDetailsRequired   = errors.New("guest checkout requires an email and a shipping address")
+	ErrWishlistNotFound       = errors.New("wishlist not found")
+	ErrWishlistItemNotFound   = errors.New("wishlist item not found")
+
+	ErrUnknownProvider = errors.New("unknown identity provider")
+
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrUnknownPermission = errors.New("unknown permission")
+
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrInvalidAPIKey      = errors.New("invalid or expired api key")
+	ErrScopeNotGranted    = errors.New("scope is not granted to the key owner")
+	ErrNotAServiceAccount = errors.New("user is not a service account")
+
+	ErrCouponNotFound      = errors.New("coupon not found")
+	ErrCouponNotApplicable = errors.New("coupon cannot be applied")
+	ErrCouponUsageLimit    = errors.New("coupon usage limit reached")
+
+	ErrPromotionNotFound = errors.New("promotion not found")
+
+	ErrInvalidSale     = errors.New("invalid sale")
+	ErrProductNotFound = errors.New("product not found")
+
+	ErrTaxRateNotFound = errors.New("tax rate not found")
+
+	ErrShippingZoneNotFound        = errors.New("shipping zone not found")
+	ErrShippingMethodNotFound      = errors.New("shipping method not found")
+	ErrShippingMethodRequired      = errors.New("a shipping method must be chosen")
+	ErrShippingUnavailable         = errors.New("shipping method is not available for this address")
+	ErrShippingDestinationRequired = errors.New("an address or country is required to quote shipping")
+
+	ErrShipmentNotFound    = errors.New("shipment not found")
+	ErrOrderNotShippable   = errors.New("order cannot be shipped in its current status")
+	ErrNothingToShip       = errors.New("every item of the order has already shipped")
+	ErrInvalidShipmentItem = errors.New("invalid shipment item")
+
+	ErrReturnNotFound          = errors.New("return not found")
+	ErrOrderNotReturnable      = errors.New("order has no shipped items that can be returned")
+	ErrInvalidReturnItem       = errors.New("invalid return item")
+	ErrInvalidReturnTransition = errors.New("return cannot move to this status")
+
+	ErrInvoiceNotFound     = errors.New("invoice not found")
+	ErrOrderNotInvoiceable = errors.New("order has not been paid")
+
+	ErrUnknownNotification  = errors.New("unknown notification type")
+	ErrNotificationRequired = errors.New("this notification cannot be turned off")
+	ErrUnsupportedLocale    = errors.New("emails are not available in this language")
+	ErrOutboxEmailNotFound  = errors.New("email not found")
+	ErrEmailNotRetryable    = errors.New("only failed emails can be retried")
 )
+
+// LockedError is returned when login attempts are temporarily blocked
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s, try again in %s", ErrAccountLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrAccountLocked
+}
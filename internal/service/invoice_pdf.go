@@ -0,0 +1,338 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/config"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/pdf"
+)
+
+// Layout of generated documents on an A4 page, in points
+const (
+	docLeft      = 50.0
+	docRight     = 545.0
+	docTop       = 60.0
+	docBottom    = 780.0
+	docFontSize  = 9.0
+	docRowHeight = 14.0
+)
+
+// documentColumn is a column of the item table. Text columns are drawn from X, amount
+// columns end at X.
+type documentColumn struct {
+	Title string
+	X     float64
+	Right bool
+}
+
+var invoiceColumns = []documentColumn{
+	{"Description", docLeft, false},
+	{"Qty", 330, true},
+	{"Unit price", 400, true},
+	{"Tax", 465, true},
+	{"Amount", docRight, true},
+}
+
+var packingSlipColumns = []documentColumn{
+	{"SKU", docLeft, false},
+	{"Description", 160, false},
+	{"Ordered", 420, true},
+	{"Shipped", 480, true},
+	{"To ship", docRight, true},
+}
+
+// documentWriter lays out a document top to bottom, starting new pages as they fill up
+type documentWriter struct {
+	doc     *pdf.Document
+	y       float64
+	columns []documentColumn
+}
+
+func newDocumentWriter(title string) *documentWriter {
+	w := &documentWriter{doc: pdf.New(title), y: docTop}
+	w.doc.AddPage()
+	return w
+}
+
+// header prints the document title, the seller and the document details
+func (w *documentWriter) header(title string, seller *config.InvoiceConfig, details [][2]string) {
+	w.doc.Text(docLeft, w.y+10, pdf.HelveticaBold, 20, title)
+
+	sellerY := w.y
+	if seller != nil {
+		lines := append([]string{}, seller.SellerAddress...)
+		if seller.SellerTaxID != "" {
+			lines = append(lines, "Tax ID: "+seller.SellerTaxID)
+		}
+		w.doc.TextRight(docRight, sellerY, pdf.HelveticaBold, docFontSize+1, seller.SellerName)
+		for _, line := range lines {
+			sellerY += 12
+			w.doc.TextRight(docRight, sellerY, pdf.Helvetica, docFontSize, line)
+		}
+	}
+
+	w.y += 40
+	for _, detail := range details {
+		w.doc.Text(docLeft, w.y, pdf.HelveticaBold, docFontSize, detail[0])
+		w.doc.Text(docLeft+100, w.y, pdf.Helvetica, docFontSize, detail[1])
+		w.y += 12
+	}
+	w.y = max(w.y, sellerY) + 20
+}
+
+// addresses prints address blocks side by side
+func (w *documentWriter) addresses(blocks ...addressBlock) {
+	height := 0.0
+	for i, block := range blocks {
+		x := docLeft + float64(i)*250
+		w.doc.Text(x, w.y, pdf.HelveticaBold, docFontSize, block.Title)
+		for j, line := range addressLines(block.Address) {
+			w.doc.Text(x, w.y+float64(j+1)*12, pdf.Helvetica, docFontSize, line)
+			height = max(height, float64(j+1)*12)
+		}
+	}
+	w.y += height + 30
+}
+
+// table starts the item table; rows added after it repeat its header on new pages
+func (w *documentWriter) table(columns []documentColumn) {
+	w.columns = columns
+	w.tableHeader()
+}
+
+func (w *documentWriter) tableHeader() {
+	for _, column := range w.columns {
+		w.cell(column, pdf.HelveticaBold, column.Title)
+	}
+	w.doc.Line(docLeft, w.y+4, docRight, w.y+4)
+	w.y += docRowHeight + 2
+}
+
+// row adds a table row. The first column is shortened to fit before the next one.
+func (w *documentWriter) row(values ...string) {
+	w.ensure(docRowHeight)
+	for i, value := range values {
+		column := w.columns[i]
+		if !column.Right && i+1 < len(w.columns) {
+			next := w.columns[i+1].X
+			if w.columns[i+1].Right {
+				next -= 50
+			}
+			value = pdf.Truncate(pdf.Helvetica, docFontSize, value, next-column.X-10)
+		}
+		w.cell(column, pdf.Helvetica, value)
+	}
+	w.y += docRowHeight
+}
+
+// total adds a labelled amount under the table, aligned with its last column
+func (w *documentWriter) total(label, amount string, bold bool) {
+	w.ensure(docRowHeight)
+	font := pdf.Helvetica
+	if bold {
+		font = pdf.HelveticaBold
+	}
+	w.doc.TextRight(docRight-90, w.y, font, docFontSize, label)
+	w.doc.TextRight(docRight, w.y, font, docFontSize, amount)
+	w.y += docRowHeight
+}
+
+func (w *documentWriter) rule() {
+	w.doc.Line(docLeft, w.y-10, docRight, w.y-10)
+	w.y += 4
+}
+
+func (w *documentWriter) note(text string) {
+	w.ensure(docRowHeight * 2)
+	w.y += docRowHeight
+	w.doc.Text(docLeft, w.y, pdf.Helvetica, docFontSize, text)
+	w.y += docRowHeight
+}
+
+func (w *documentWriter) cell(column documentColumn, font pdf.Font, value string) {
+	if column.Right {
+		w.doc.TextRight(column.X, w.y, font, docFontSize, value)
+	} else {
+		w.doc.Text(column.X, w.y, font, docFontSize, value)
+	}
+}
+
+// ensure starts a new page when the next height would not fit on this one
+func (w *documentWriter) ensure(height float64) {
+	if w.y+height <= docBottom {
+		return
+	}
+	w.doc.AddPage()
+	w.y = docTop
+	if w.columns != nil {
+		w.tableHeader()
+	}
+}
+
+func (w *documentWriter) bytes() []byte {
+	pages := w.doc.PageCount()
+	if pages > 1 {
+		// Page numbers are only known once everything is laid out
+		for i := 0; i < pages; i++ {
+			w.doc.SetPage(i)
+			w.doc.TextRight(docRight, docBottom+30, pdf.Helvetica, docFontSize-1, fmt.Sprintf("Page %d of %d", i+1, pages))
+		}
+	}
+	return w.doc.Bytes()
+}
+
+type addressBlock struct {
+	Title   string
+	Address models.AddressSnapshot
+}
+
+func addressLines(address models.AddressSnapshot) []string {
+	city := strings.TrimSpace(strings.Join(nonEmpty(address.City, strings.TrimSpace(address.Region+" "+address.PostalCode)), ", "))
+	return nonEmpty(address.Name, address.Company, address.Line1, address.Line2, city, address.CountryCode)
+}
+
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func formatMoney(amount float64) string {
+	return fmt.Sprintf("%.2f", roundMoney(amount))
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("2 January 2006")
+}
+
+// billingAddress falls back to the shipping address for orders placed without one
+func billingAddress(order *models.Order) models.AddressSnapshot {
+	if order.BillingAddress.Line1 == "" {
+		return order.ShippingAddress
+	}
+	return order.BillingAddress
+}
+
+// renderInvoice draws the invoice of a paid order
+func renderInvoice(seller *config.InvoiceConfig, invoice *models.Invoice, order *models.Order) []byte {
+	w := newDocumentWriter("Invoice " + invoice.Number)
+	w.header("INVOICE", seller, [][2]string{
+		{"Invoice number", invoice.Number},
+		{"Invoice date", formatDate(invoice.IssuedAt)},
+		{"Order number", order.OrderNumber},
+		{"Order date", formatDate(order.CreatedAt)},
+	})
+	w.addresses(
+		addressBlock{"Bill to", billingAddress(order)},
+		addressBlock{"Ship to", order.ShippingAddress},
+	)
+
+	w.table(invoiceColumns)
+	var subtotal float64
+	for _, item := range order.OrderItems {
+		amount := item.Price * float64(item.Quantity)
+		subtotal += amount
+		w.row(item.Product.Name, fmt.Sprint(item.Quantity), formatMoney(item.Price),
+			formatMoney(item.TaxAmount), formatMoney(amount))
+	}
+	w.rule()
+
+	w.total("Subtotal", formatMoney(subtotal), false)
+	for _, discount := range order.Discounts {
+		label := discount.Description
+		if label == "" {
+			label = "Discount " + discount.Code
+		}
+		w.total(label, "-"+formatMoney(discount.Amount), false)
+	}
+	if order.ShippingMethodName != "" || order.ShippingCost > 0 {
+		w.total("Shipping "+order.ShippingMethodName, formatMoney(order.ShippingCost), false)
+	}
+	w.taxTotals(orderTaxLines(order), order.PricesIncludeTax)
+	w.total("Total", formatMoney(order.TotalAmount), true)
+
+	if order.PricesIncludeTax {
+		w.note("Prices include tax.")
+	}
+	return w.bytes()
+}
+
+// renderCreditNote draws the credit note of a refunded return. Each line is refunded at
+// the unit amount the return was refunded at.
+func renderCreditNote(seller *config.InvoiceConfig, creditNote *models.Invoice, invoiceNumber string,
+	order *models.Order, returnRequest *models.ReturnRequest) []byte {
+	w := newDocumentWriter("Credit note " + creditNote.Number)
+	w.header("CREDIT NOTE", seller, [][2]string{
+		{"Credit note number", creditNote.Number},
+		{"Date", formatDate(creditNote.IssuedAt)},
+		{"Invoice number", invoiceNumber},
+		{"Order number", order.OrderNumber},
+		{"Return number", fmt.Sprint(returnRequest.ID)},
+	})
+	w.addresses(addressBlock{"Bill to", billingAddress(order)})
+
+	refunds := unitRefunds(order)
+	items := make(map[uint]*models.OrderItem, len(order.OrderItems))
+	for i := range order.OrderItems {
+		items[order.OrderItems[i].ID] = &order.OrderItems[i]
+	}
+
+	w.table(invoiceColumns)
+	for _, returned := range returnRequest.Items {
+		item := items[returned.OrderItemID]
+		if item == nil {
+			continue
+		}
+		tax := item.TaxAmount / float64(item.Quantity) * float64(returned.Quantity)
+		w.row(item.Product.Name, fmt.Sprint(returned.Quantity), formatMoney(refunds[item.ID]),
+			formatMoney(tax), formatMoney(refunds[item.ID]*float64(returned.Quantity)))
+	}
+	w.rule()
+
+	label := "Tax"
+	if order.PricesIncludeTax {
+		label = "Included tax"
+	}
+	w.total(label, formatMoney(creditNote.TaxTotal), false)
+	w.total("Total refunded", formatMoney(creditNote.Total), true)
+
+	w.note("Amounts are net of the discounts applied to the order.")
+	return w.bytes()
+}
+
+// renderPackingSlip draws the list of items still to be packed for an order
+func renderPackingSlip(order *models.Order, shipped map[uint]int) []byte {
+	w := newDocumentWriter("Packing slip " + order.OrderNumber)
+	w.header("PACKING SLIP", nil, [][2]string{
+		{"Order number", order.OrderNumber},
+		{"Order date", formatDate(order.CreatedAt)},
+		{"Shipping", order.ShippingMethodName},
+	})
+	w.addresses(addressBlock{"Ship to", order.ShippingAddress})
+
+	w.table(packingSlipColumns)
+	for _, item := range order.OrderItems {
+		w.row(item.Product.SKU, item.Product.Name, fmt.Sprint(item.Quantity),
+			fmt.Sprint(shipped[item.ID]), fmt.Sprint(max(item.Quantity-shipped[item.ID], 0)))
+	}
+	w.rule()
+	return w.bytes()
+}
+
+// taxTotals prints the tax charged at each rate
+func (w *documentWriter) taxTotals(lines []TaxLine, inclusive bool) {
+	for _, line := range summarizeTaxLines([][]TaxLine{lines}) {
+		label := fmt.Sprintf("%s %g%%", line.Name, line.Rate)
+		if inclusive {
+			label = "Includes " + label
+		}
+		w.total(label, formatMoney(line.Amount), false)
+	}
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/config"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/storage"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// invoiceableStatuses are the statuses of orders that have been paid
+var invoiceableStatuses = []models.OrderStatus{
+	models.OrderStatusPaid,
+	models.OrderStatusConfirmed,
+	models.OrderStatusPartiallyShipped,
+	models.OrderStatusShipped,
+	models.OrderStatusDelivered,
+	models.OrderStatusRefunded,
+}
+
+// Document number prefixes, each with its own sequence
+var invoiceNumberPrefixes = map[models.InvoiceType]string{
+	models.InvoiceTypeInvoice:    "INV",
+	models.InvoiceTypeCreditNote: "CN",
+}
+
+// InvoiceService issues the accounting documents of orders: an invoice once an order
+// is paid and a credit note for every refunded return. Documents are numbered without
+// gaps, rendered to PDF once and kept in storage; they are never regenerated.
+type InvoiceService struct {
+	db      *gorm.DB
+	storage storage.Storage
+	config  *config.InvoiceConfig
+}
+
+func NewInvoiceService(db *gorm.DB, store storage.Storage, cfg *config.InvoiceConfig) *InvoiceService {
+	return &InvoiceService{
+		db:      db,
+		storage: store,
+		config:  cfg,
+	}
+}
+
+// ListOrderInvoices returns the documents of one of the user's orders, issuing any that
+// are due but missing
+func (s *InvoiceService) ListOrderInvoices(userID, orderID uint) ([]dto.InvoiceResponse, error) {
+	var order models.Order
+	if err := s.db.Where("id = ? AND user_id = ?", orderID, userID).First(&order).Error; err != nil {
+		return nil, ErrOrderNotFound
+	}
+	if orderInvoiceable(order.Status) {
+		if _, err := s.issueDueDocuments(&order); err != nil {
+			return nil, err
+		}
+	}
+
+	var invoices []models.Invoice
+	if err := s.db.Preload("Order").Where("order_id = ?", order.ID).
+		Order("issued_at, id").Find(&invoices).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.InvoiceResponse, len(invoices))
+	for i := range invoices {
+		response[i] = convertToInvoiceResponse(&invoices[i])
+	}
+	return response, nil
+}
+
+// GetUserInvoicePDF returns a document of one of the user's orders with its PDF
+func (s *InvoiceService) GetUserInvoicePDF(userID, invoiceID uint) (*dto.InvoiceResponse, []byte, error) {
+	var invoice models.Invoice
+	if err := s.db.Preload("Order").Joins("JOIN orders ON orders.id = invoices.order_id").
+		Where("invoices.id = ? AND orders.user_id = ?", invoiceID, userID).First(&invoice).Error; err != nil {
+		return nil, nil, ErrInvoiceNotFound
+	}
+	return s.invoicePDF(&invoice)
+}
+
+// ListInvoices returns issued documents for accounting, newest first
+func (s *InvoiceService) ListInvoices(req *dto.ListInvoicesRequest) ([]dto.InvoiceResponse, *utils.PaginationMeta, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	query := s.db.Model(&models.Invoice{})
+	if req.Type != "" {
+		query = query.Where("type = ?", req.Type)
+	}
+	if req.OrderID != 0 {
+		query = query.Where("order_id = ?", req.OrderID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var invoices []models.Invoice
+	if err := query.Preload("Order").Order("issued_at DESC, id DESC").
+		Offset(offset).Limit(req.Limit).Find(&invoices).Error; err != nil {
+		return nil, nil, err
+	}
+
+	response := make([]dto.InvoiceResponse, len(invoices))
+	for i := range invoices {
+		response[i] = convertToInvoiceResponse(&invoices[i])
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	meta := &utils.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      int(total),
+		TotalPages: totalPages,
+	}
+
+	return response, meta, nil
+}
+
+func (s *InvoiceService) GetInvoicePDF(invoiceID uint) (*dto.InvoiceResponse, []byte, error) {
+	var invoice models.Invoice
+	if err := s.db.Preload("Order").First(&invoice, invoiceID).Error; err != nil {
+		return nil, nil, ErrInvoiceNotFound
+	}
+	return s.invoicePDF(&invoice)
+}
+
+// IssueInvoice invoices a paid order along with any credit notes still due. An order
+// already invoiced keeps its invoice.
+func (s *InvoiceService) IssueInvoice(orderID uint) (*dto.InvoiceResponse, error) {
+	var order models.Order
+	if err := s.db.First(&order, orderID).Error; err != nil {
+		return nil, ErrOrderNotFound
+	}
+	if !orderInvoiceable(order.Status) {
+		return nil, ErrOrderNotInvoiceable
+	}
+
+	invoice, err := s.issueDueDocuments(&order)
+	if err != nil {
+		return nil, err
+	}
+
+	response := convertToInvoiceResponse(invoice)
+	return &response, nil
+}
+
+// IssueCreditNote documents the refund of a return, invoicing the order first if needed
+func (s *InvoiceService) IssueCreditNote(returnID uint) error {
+	var returnRequest models.ReturnRequest
+	if err := s.db.First(&returnRequest, returnID).Error; err != nil {
+		return ErrReturnNotFound
+	}
+
+	invoice, err := s.issueInvoice(returnRequest.OrderID)
+	if err != nil {
+		return err
+	}
+	return s.issueCreditNote(returnID, invoice.Number)
+}
+
+// PackingSlip renders the list of an order's items for the warehouse, with how many of
+// each are still to be shipped. Packing slips are not accounting documents and are not kept.
+func (s *InvoiceService) PackingSlip(orderID uint) (string, []byte, error) {
+	var order models.Order
+	if err := preloadInvoiceOrder(s.db).First(&order, orderID).Error; err != nil {
+		return "", nil, ErrOrderNotFound
+	}
+
+	shipped, err := shippedQuantities(s.db, order.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	return order.OrderNumber, renderPackingSlip(&order, shipped), nil
+}
+
+// issueDueDocuments issues the invoice of a paid order and the credit notes of its
+// refunded returns that do not have one yet
+func (s *InvoiceService) issueDueDocuments(order *models.Order) (*models.Invoice, error) {
+	invoice, err := s.issueInvoice(order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var returnIDs []uint
+	if err := s.db.Model(&models.ReturnRequest{}).
+		Where("order_id = ? AND status = ?", order.ID, models.ReturnStatusRefunded).
+		Where("id NOT IN (?)", s.db.Model(&models.Invoice{}).Select("return_request_id").
+			Where("order_id = ? AND return_request_id IS NOT NULL", order.ID)).
+		Order("refunded_at, id").Pluck("id", &returnIDs).Error; err != nil {
+		return nil, err
+	}
+	for _, returnID := range returnIDs {
+		if err := s.issueCreditNote(returnID, invoice.Number); err != nil {
+			return nil, err
+		}
+	}
+	return invoice, nil
+}
+
+func (s *InvoiceService) issueInvoice(orderID uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the order so it is invoiced once
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; err != nil {
+			return ErrOrderNotFound
+		}
+		if err := tx.Where("order_id = ? AND type = ?", order.ID, models.InvoiceTypeInvoice).
+			First(&invoice).Error; err == nil {
+			return nil
+		}
+		if !orderInvoiceable(order.Status) {
+			return ErrOrderNotInvoiceable
+		}
+
+		if err := preloadInvoiceOrder(tx).First(&order, order.ID).Error; err != nil {
+			return err
+		}
+
+		invoice = models.Invoice{
+			Type:     models.InvoiceTypeInvoice,
+			OrderID:  order.ID,
+			Total:    order.TotalAmount,
+			TaxTotal: order.TaxTotal,
+		}
+		return s.issue(tx, &invoice, func() []byte {
+			return renderInvoice(s.config, &invoice, &order)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Preload("Order").First(&invoice, invoice.ID).Error; err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (s *InvoiceService) issueCreditNote(returnID uint, invoiceNumber string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var returnRequest models.ReturnRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&returnRequest, returnID).Error; err != nil {
+			return ErrReturnNotFound
+		}
+		if returnRequest.Status != models.ReturnStatusRefunded {
+			return fmt.Errorf("%w: return is %s", ErrInvalidReturnTransition, returnRequest.Status)
+		}
+
+		var count int64
+		if err := tx.Model(&models.Invoice{}).Where("return_request_id = ?", returnRequest.ID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		if err := tx.Where("return_request_id = ?", returnRequest.ID).Order("id").
+			Find(&returnRequest.Items).Error; err != nil {
+			return err
+		}
+		var order models.Order
+		if err := preloadInvoiceOrder(tx).First(&order, returnRequest.OrderID).Error; err != nil {
+			return err
+		}
+
+		// Tax is refunded in proportion to the units returned
+		var taxTotal float64
+		for _, returned := range returnRequest.Items {
+			for _, item := range order.OrderItems {
+				if item.ID == returned.OrderItemID && item.Quantity > 0 {
+					taxTotal += item.TaxAmount / float64(item.Quantity) * float64(returned.Quantity)
+				}
+			}
+		}
+
+		creditNote := models.Invoice{
+			Type:            models.InvoiceTypeCreditNote,
+			OrderID:         order.ID,
+			ReturnRequestID: &returnRequest.ID,
+			Total:           returnRequest.RefundAmount,
+			TaxTotal:        roundMoney(taxTotal),
+		}
+		return s.issue(tx, &creditNote, func() []byte {
+			return renderCreditNote(s.config, &creditNote, invoiceNumber, &order, &returnRequest)
+		})
+	})
+}
+
+// issue numbers a document, renders it, stores the PDF and records it, all within tx.
+// A failure rolls the number back, so numbers stay consecutive.
+func (s *InvoiceService) issue(tx *gorm.DB, invoice *models.Invoice, render func() []byte) error {
+	number, err := nextDocumentNumber(tx, invoice.Type)
+	if err != nil {
+		return err
+	}
+	invoice.Number = number
+	invoice.IssuedAt = time.Now()
+	invoice.StorageKey = fmt.Sprintf("invoices/%d/%s.pdf", invoice.IssuedAt.Year(), number)
+
+	data := render()
+	sum := sha256.Sum256(data)
+	invoice.Checksum = hex.EncodeToString(sum[:])
+	if err := s.storage.Put(invoice.StorageKey, data); err != nil {
+		return fmt.Errorf("failed to store %s: %w", number, err)
+	}
+
+	if err := tx.Create(invoice).Error; err != nil {
+		return errors.New("failed to create invoice")
+	}
+	return nil
+}
+
+// invoicePDF reads a stored document back, refusing a file that no longer matches
+// what was issued
+func (s *InvoiceService) invoicePDF(invoice *models.Invoice) (*dto.InvoiceResponse, []byte, error) {
+	data, err := s.storage.Get(invoice.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", invoice.Number, err)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != invoice.Checksum {
+		return nil, nil, fmt.Errorf("stored file of %s does not match its checksum", invoice.Number)
+	}
+
+	response := convertToInvoiceResponse(invoice)
+	return &response, data, nil
+}
+
+// nextDocumentNumber takes the next number of a document sequence. The sequence row
+// stays locked until tx ends, so concurrent documents are numbered one after another.
+func nextDocumentNumber(tx *gorm.DB, invoiceType models.InvoiceType) (string, error) {
+	var sequence models.DocumentSequence
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("name = ?", string(invoiceType)).First(&sequence).Error; err != nil {
+		return "", err
+	}
+
+	sequence.LastValue++
+	if err := tx.Model(&sequence).Update("last_value", sequence.LastValue).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%06d", invoiceNumberPrefixes[invoiceType], sequence.LastValue), nil
+}
+
+func preloadInvoiceOrder(query *gorm.DB) *gorm.DB {
+	return query.Preload("OrderItems", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).
+		Preload("OrderItems.Product", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Preload("Discounts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Preload("TaxLines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		})
+}
+
+func orderInvoiceable(status models.OrderStatus) bool {
+	for _, invoiceable := range invoiceableStatuses {
+		if status == invoiceable {
+			return true
+		}
+	}
+	return false
+}
+
+func convertToInvoiceResponse(invoice *models.Invoice) dto.InvoiceResponse {
+	return dto.InvoiceResponse{
+		ID:          invoice.ID,
+		Number:      invoice.Number,
+		Type:        string(invoice.Type),
+		OrderID:     invoice.OrderID,
+		OrderNumber: invoice.Order.OrderNumber,
+		ReturnID:    invoice.ReturnRequestID,
+		Total:       invoice.Total,
+		TaxTotal:    invoice.TaxTotal,
+		Checksum:    invoice.Checksum,
+		IssuedAt:    invoice.IssuedAt,
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/config"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginGuard throttles failed logins per account and per IP address with
+// exponential back-off followed by a temporary lockout
+type LoginGuard struct {
+	db     *gorm.DB
+	config *config.LoginConfig
+}
+
+func NewLoginGuard(db *gorm.DB, cfg *config.LoginConfig) *LoginGuard {
+	return &LoginGuard{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// Check returns a *LockedError when either the account or the IP address must wait before trying again
+func (g *LoginGuard) Check(email, ip string) error {
+	var throttles []models.LoginThrottle
+	if err := g.db.Where("(scope = ? AND key = ?) OR (scope = ? AND key = ?)",
+		models.LoginThrottleScopeAccount, normalizeEmail(email),
+		models.LoginThrottleScopeIP, ip,
+	).Find(&throttles).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var wait time.Duration
+	for i := range throttles {
+		if retryAt := g.retryAt(&throttles[i]); retryAt.After(now) && retryAt.Sub(now) > wait {
+			wait = retryAt.Sub(now)
+		}
+	}
+
+	if wait > 0 {
+		return &LockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login and reports whether it just locked the account
+func (g *LoginGuard) RecordFailure(email, ip string) (accountLocked bool, err error) {
+	accountLocked, err = g.recordFailure(models.LoginThrottleScopeAccount, normalizeEmail(email), g.config.MaxAccountAttempts)
+	if err != nil {
+		return false, err
+	}
+
+	if ip != "" {
+		if _, err := g.recordFailure(models.LoginThrottleScopeIP, ip, g.config.MaxIPAttempts); err != nil {
+			return false, err
+		}
+	}
+	return accountLocked, nil
+}
+
+// RecordSuccess clears the failure count of an account after a successful login
+func (g *LoginGuard) RecordSuccess(email string) error {
+	return g.Unlock(email)
+}
+
+// Unlock removes any back-off or lockout from an account
+func (g *LoginGuard) Unlock(email string) error {
+	return g.db.Where("scope = ? AND key = ?", models.LoginThrottleScopeAccount, normalizeEmail(email)).
+		Delete(&models.LoginThrottle{}).Error
+}
+
+func (g *LoginGuard) recordFailure(scope models.LoginThrottleScope, key string, maxAttempts int) (bool, error) {
+	locked := false
+
+	err := g.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.LoginThrottle{Scope: scope, Key: key}).Error; err != nil {
+			return err
+		}
+
+		var throttle models.LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("scope = ? AND key = ?", scope, key).
+			First(&throttle).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+
+		// Failures older than the window, or an expired lockout, start a fresh count
+		expiredLock := throttle.LockedUntil != nil && !throttle.LockedUntil.After(now)
+		if expiredLock || now.Sub(throttle.LastFailedAt) > g.config.AttemptWindow {
+			throttle.FailedCount = 0
+			throttle.LockedUntil = nil
+		}
+
+		throttle.FailedCount++
+		throttle.LastFailedAt = now
+
+		if throttle.FailedCount >= maxAttempts && throttle.LockedUntil == nil {
+			lockedUntil := now.Add(g.config.LockoutDuration)
+			throttle.LockedUntil = &lockedUntil
+			locked = true
+		}
+
+		return tx.Save(&throttle).Error
+	})
+
+	return locked, err
+}
+
+// retryAt returns the earliest time the next login attempt is allowed
+func (g *LoginGuard) retryAt(throttle *models.LoginThrottle) time.Time {
+	if throttle.LockedUntil != nil {
+		return *throttle.LockedUntil
+	}
+
+	excess := throttle.FailedCount - g.config.BackoffAfter
+	if excess <= 0 || time.Since(throttle.LastFailedAt) > g.config.AttemptWindow {
+		return time.Time{}
+	}
+
+	// Double the delay for every failure past the free attempts, capped at the lockout duration
+	delay := g.config.BackoffBase
+	for i := 1; i < excess && delay < g.config.LockoutDuration; i++ {
+		delay *= 2
+	}
+	if delay > g.config.LockoutDuration {
+		delay = g.config.LockoutDuration
+	}
+
+	return throttle.LastFailedAt.Add(delay)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/programmerjide/ecommerce/internal/config"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/mailer"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// notificationItem is an order line shown in an email
+type notificationItem struct {
+	Name     string
+	Quantity int
+	Total    float64
+}
+
+type orderConfirmationEmail struct {
+	Name        string
+	OrderNumber string
+	Items       []notificationItem
+	Subtotal    float64
+	Discount    float64
+	Shipping    float64
+	Tax         float64
+	TaxIncluded bool
+	Total       float64
+	OrderURL    string
+}
+
+type orderShippedEmail struct {
+	Name           string
+	OrderNumber    string
+	Partial        bool
+	Carrier        string
+	TrackingNumber string
+	TrackingURL    string
+	Items          []notificationItem
+}
+
+type refundIssuedEmail struct {
+	Name        string
+	OrderNumber string
+	Amount      float64
+	Items       []notificationItem
+	Note        string
+}
+
+// returnReviewedEmail tells a customer whether their return was approved or rejected
+type returnReviewedEmail struct {
+	Name        string
+	OrderNumber string
+	Note        string
+}
+
+// erasureEmail tells a customer where their data erasure request stands
+type erasureEmail struct {
+	Name string
+	Note string
+}
+
+type passwordResetEmail struct {
+	Name             string
+	ResetURL         string
+	ExpiresInMinutes int
+}
+
+// accountChangeEmail tells a user about a change to their account; NewEmail is set
+// for email changes
+type accountChangeEmail struct {
+	Name     string
+	NewEmail string
+}
+
+type accountLockedEmail struct {
+	Name             string
+	LockedForMinutes int
+	Attempts         int
+	IPAddress        string
+}
+
+type confirmEmailEmail struct {
+	Name           string
+	ConfirmURL     string
+	ExpiresInHours int
+}
+
+type guestConversionEmail struct {
+	ConvertURL     string
+	ExpiresInHours int
+}
+
+// NotificationService sends the templated emails customers receive, in their language
+// and only if they have not turned them off
+type NotificationService struct {
+	db        *gorm.DB
+	mailer    mailer.Mailer
+	transport mailer.Mailer
+	templates *mailer.Templates
+	config    *config.Config
+}
+
+// NewNotificationService takes the outbox as mail and the mailer behind it as transport,
+// which NotifyUntracked sends through
+func NewNotificationService(db *gorm.DB, mail, transport mailer.Mailer, templates *mailer.Templates, cfg *config.Config) *NotificationService {
+	return &NotificationService{
+		db:        db,
+		mailer:    mail,
+		transport: transport,
+		templates: templates,
+		config:    cfg,
+	}
+}
+
+// Notify renders a notification for its recipient and sends it. userID is nil for
+// guests, who get every notification in the default language.
+func (s *NotificationService) Notify(notificationType models.NotificationType, userID *uint, to string, data interface{}) error {
+	locale := s.config.Mail.DefaultLocale
+	if userID != nil {
+		var user models.User
+		if err := s.db.Select("id", "locale").First(&user, *userID).Error; err == nil && user.Locale != "" {
+			locale = user.Locale
+		}
+
+		if notificationType.Optional() {
+			var preference models.NotificationPreference
+			if err := s.db.Where("user_id = ? AND type = ?", *userID, notificationType).
+				First(&preference).Error; err == nil && !preference.Enabled {
+				return nil
+			}
+		}
+	}
+
+	return s.send(s.mailer, notificationType, locale, to, data)
+}
+
+// NotifyUntracked sends a notification in the given locale straight to the transport,
+// skipping the outbox so that no copy of the recipient is kept. It is not retried.
+func (s *NotificationService) NotifyUntracked(notificationType models.NotificationType, locale, to string, data interface{}) error {
+	if locale == "" {
+		locale = s.config.Mail.DefaultLocale
+	}
+	return s.send(s.transport, notificationType, locale, to, data)
+}
+
+func (s *NotificationService) send(mail mailer.Mailer, notificationType models.NotificationType, locale, to string, data interface{}) error {
+	msg, err := s.templates.Render(string(notificationType), locale, data)
+	if err != nil {
+		return fmt.Errorf("failed to render %s email: %w", notificationType, err)
+	}
+	msg.To = to
+	return mail.Send(msg)
+}
+
+func (s *NotificationService) GetPreferences(userID uint) (*dto.NotificationPreferencesResponse, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var preferences []models.NotificationPreference
+	if err := s.db.Where("user_id = ?", userID).Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+	enabled := make(map[models.NotificationType]bool, len(preferences))
+	for _, preference := range preferences {
+		enabled[preference.Type] = preference.Enabled
+	}
+
+	response := &dto.NotificationPreferencesResponse{
+		Locale:        user.Locale,
+		Locales:       s.templates.Locales(),
+		Notifications: make([]dto.NotificationPreferenceResponse, len(models.NotificationTypes)),
+	}
+	for i, notificationType := range models.NotificationTypes {
+		on, ok := enabled[notificationType]
+		response.Notifications[i] = dto.NotificationPreferenceResponse{
+			Type:     string(notificationType),
+			Enabled:  !ok || on || !notificationType.Optional(),
+			Optional: notificationType.Optional(),
+		}
+	}
+	return response, nil
+}
+
+func (s *NotificationService) UpdatePreferences(userID uint, req *dto.UpdateNotificationPreferencesRequest) (*dto.NotificationPreferencesResponse, error) {
+	var locale string
+	if req.Locale != nil {
+		locale = strings.ToLower(strings.TrimSpace(*req.Locale))
+		if locale != "" && !s.templates.HasLocale(locale) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedLocale, locale)
+		}
+	}
+
+	var preferences []models.NotificationPreference
+	for name, enabled := range req.Notifications {
+		notificationType := models.NotificationType(name)
+		if !knownNotification(notificationType) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNotification, name)
+		}
+		if !enabled && !notificationType.Optional() {
+			return nil, fmt.Errorf("%w: %s", ErrNotificationRequired, name)
+		}
+		preferences = append(preferences, models.NotificationPreference{
+			UserID:  userID,
+			Type:    notificationType,
+			Enabled: enabled,
+		})
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if req.Locale != nil {
+			result := tx.Model(&models.User{}).Where("id = ?", userID).Update("locale", locale)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrUserNotFound
+			}
+		}
+		if len(preferences) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+		}).Create(&preferences).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPreferences(userID)
+}
+
+// orderURL links to an order on the storefront
+func (s *NotificationService) orderURL(orderNumber string) string {
+	return fmt.Sprintf("%s/orders/%s", s.config.Server.PublicURL, orderNumber)
+}
+
+func knownNotification(notificationType models.NotificationType) bool {
+	for _, known := range models.NotificationTypes {
+		if notificationType == known {
+			return true
+		}
+	}
+	return false
+}
+
+// recipientName greets a customer by first name, or by the name on the order for guests
+func recipientName(db *gorm.DB, order *models.Order) string {
+	if order.UserID != nil {
+		var user models.User
+		if err := db.Select("id", "first_name").First(&user, *order.UserID).Error; err == nil && user.FirstName != "" {
+			return user.FirstName
+		}
+	}
+	if fields := strings.Fields(order.ShippingAddress.Name); len(fields) > 0 {
+		return fields[0]
+	}
+	return order.Email
+}
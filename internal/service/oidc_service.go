@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/config"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/oidc"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OIDCService signs customers in through external OpenID Connect providers
+type OIDCService struct {
+	db          *gorm.DB
+	config      *config.OIDCConfig
+	authService *AuthService
+	providers   map[string]*oidc.Provider
+}
+
+func NewOIDCService(db *gorm.DB, cfg *config.OIDCConfig, authService *AuthService) *OIDCService {
+	providers := make(map[string]*oidc.Provider, len(cfg.Providers))
+	for _, providerConfig := range cfg.Providers {
+		providers[providerConfig.Name] = oidc.NewProvider(providerConfig)
+	}
+
+	return &OIDCService{
+		db:          db,
+		config:      cfg,
+		authService: authService,
+		providers:   providers,
+	}
+}
+
+// StartLogin creates the state, nonce and PKCE verifier for a sign-in and returns the provider URL
+func (s *OIDCService) StartLogin(ctx context.Context, providerName string) (*dto.OIDCAuthorizeResponse, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	state, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	authorizationURL, err := provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clean up abandoned sign-ins while we are here
+	s.db.Where("expires_at < ?", time.Now()).Delete(&models.OIDCLoginState{})
+
+	loginState := models.OIDCLoginState{
+		State:        state,
+		Provider:     providerName,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(s.config.StateExpires),
+	}
+	if err := s.db.Create(&loginState).Error; err != nil {
+		return nil, errors.New("failed to save login state")
+	}
+
+	return &dto.OIDCAuthorizeResponse{
+		AuthorizationURL: authorizationURL,
+		State:            state,
+		ExpiresAt:        loginState.ExpiresAt,
+	}, nil
+}
+
+// StateBinding is the value the browser that started a sign-in keeps in a cookie;
+// CompleteLogin only accepts a state whose binding the callback presents
+func StateBinding(state string) string {
+	return utils.HashToken(state)
+}
+
+// CompleteLogin finishes the authorization code flow and issues our own token pair.
+// stateBinding comes from the cookie set when the sign-in started; without it an
+// attacker could sign the victim into the attacker's account by sending them a callback link.
+func (s *OIDCService) CompleteLogin(ctx context.Context, providerName string, req *dto.OIDCCallbackRequest, stateBinding string, client dto.ClientInfo) (*dto.AuthResponse, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	if subtle.ConstantTimeCompare([]byte(StateBinding(req.State)), []byte(stateBinding)) != 1 {
+		return nil, errors.New("sign-in was started in another browser")
+	}
+
+	// States are single use: delete first so a replayed callback fails
+	var loginState models.OIDCLoginState
+	if err := s.db.Where("state = ? AND provider = ? AND expires_at > ?", req.State, providerName, time.Now()).
+		First(&loginState).Error; err != nil {
+		return nil, errors.New("invalid or expired login state")
+	}
+	if result := s.db.Delete(&loginState); result.Error != nil || result.RowsAffected == 0 {
+		return nil, errors.New("failed to consume login state")
+	}
+
+	tokens, err := provider.Exchange(ctx, req.Code, loginState.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, loginState.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveUser(providerName, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, errors.New("account is deactivated")
+	}
+
+	return s.authService.generateAuthResponse(user, client)
+}
+
+// ListIdentities returns the external accounts linked to a user
+func (s *OIDCService) ListIdentities(userID uint) ([]dto.LinkedIdentityResponse, error) {
+	var identities []models.LinkedIdentity
+	if err := s.db.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.LinkedIdentityResponse, len(identities))
+	for i := range identities {
+		response[i] = dto.LinkedIdentityResponse{
+			ID:        identities[i].ID,
+			Provider:  identities[i].Provider,
+			Email:     identities[i].Email,
+			CreatedAt: identities[i].CreatedAt,
+		}
+	}
+	return response, nil
+}
+
+// UnlinkIdentity removes an external account from a user. The last one cannot be
+// removed from an account without a password, as the user could no longer sign in.
+func (s *OIDCService) UnlinkIdentity(userID, identityID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the user so concurrent unlinks cannot both pass the check
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return ErrUserNotFound
+		}
+
+		var identity models.LinkedIdentity
+		if err := tx.Where("id = ? AND user_id = ?", identityID, userID).First(&identity).Error; err != nil {
+			return ErrIdentityNotFound
+		}
+
+		if user.PasswordUnset {
+			var count int64
+			if err := tx.Model(&models.LinkedIdentity{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count <= 1 {
+				return ErrLastSignInMethod
+			}
+		}
+
+		if err := tx.Delete(&identity).Error; err != nil {
+			return errors.New("failed to unlink identity")
+		}
+		return nil
+	})
+}
+
+// resolveUser finds the user for an external identity. Unknown identities are
+// linked to the account with the same verified email, or get a new account.
+//
+// Registration does not prove the email address, so an account whose owner never did
+// may have been registered by someone else. Linking it hands it to the provider's user:
+// the password is made unusable and every session and API key is revoked.
+func (s *OIDCService) resolveUser(providerName string, claims *oidc.IDTokenClaims) (*models.User, error) {
+	var identity models.LinkedIdentity
+	err := s.db.Preload("User").Where("provider = ? AND subject = ?", providerName, claims.Subject).First(&identity).Error
+	if err == nil {
+		return &identity.User, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// An unverified email could belong to someone else, so it must never link accounts
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, errors.New("identity provider did not return a verified email")
+	}
+
+	var user models.User
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		findErr := tx.Where("LOWER(email) = LOWER(?)", claims.Email).First(&user).Error
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			newUser, createErr := newUserFromClaims(claims)
+			if createErr != nil {
+				return createErr
+			}
+			if createErr := tx.Create(newUser).Error; createErr != nil {
+				return createErr
+			}
+			user = *newUser
+		} else if findErr != nil {
+			return findErr
+		} else if user.EmailVerifiedAt == nil {
+			if err := takeOverUnverifiedUser(tx, &user); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&models.LinkedIdentity{
+			UserID:   user.ID,
+			Provider: providerName,
+			Subject:  claims.Subject,
+			Email:    claims.Email,
+		}).Error
+	})
+	if err != nil {
+		return nil, errors.New("failed to link identity")
+	}
+
+	return &user, nil
+}
+
+// takeOverUnverifiedUser locks out whoever registered an account with an email address
+// they never proved, now that a provider has verified it for someone else
+func takeOverUnverifiedUser(tx *gorm.DB, user *models.User) error {
+	hashPassword, err := unusablePassword()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := tx.Model(user).Updates(map[string]interface{}{
+		"password":          hashPassword,
+		"password_unset":    true,
+		"email_verified_at": now,
+	}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.RefreshToken{}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL", user.ID).
+		Update("revoked_at", now).Error
+}
+
+// unusablePassword hashes a random secret nobody knows
+func unusablePassword() (string, error) {
+	randomPassword, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	return utils.HashPassword(randomPassword)
+}
+
+// newUserFromClaims creates a customer without a usable password; they can set
+// one later through the password reset flow
+func newUserFromClaims(claims *oidc.IDTokenClaims) (*models.User, error) {
+	hashPassword, err := unusablePassword()
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(strings.TrimSpace(claims.Name), " ")
+	}
+	if firstName == "" {
+		firstName, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	now := time.Now()
+	return &models.User{
+		Email:     claims.Email,
+		Password:  hashPassword,
+		FirstName: firstName,
+		LastName:  lastName,
+		Role:      models.UserRoleCustomer,
+		IsActive:  true,
+		// The random password is unknown to the user
+		PasswordUnset: true,
+		// The provider verified the email
+		EmailVerifiedAt: &now,
+	}, nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/programmerjide/ecommerce/internal/config"
+	"github.com/programmerjide/ecommerce/internal/dto"
+)
+
+func TestCompleteLoginRequiresStateBinding(t *testing.T) {
+	// The binding is checked before the login state is looked up, so no database is needed
+	s := NewOIDCService(nil, &config.OIDCConfig{
+		Providers: []config.OIDCProviderConfig{{Name: "stub", IssuerURL: "http://127.0.0.1:0"}},
+	}, nil)
+
+	tests := []struct {
+		name    string
+		binding string
+	}{
+		{"no cookie", ""},
+		{"cookie of another sign-in", StateBinding("state-2")},
+		{"raw state instead of its binding", "state-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &dto.OIDCCallbackRequest{State: "state-1", Code: "code-1"}
+			if _, err := s.CompleteLogin(context.Background(), "stub", req, tt.binding, dto.ClientInfo{}); err == nil {
+				t.Fatal("expected callback without the matching state binding to be rejected")
+			}
+		})
+	}
+}
@@ -0,0 +1,454 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrderService struct {
+	db             *gorm.DB
+	notifications  *NotificationService
+	authService    *AuthService
+	cartService    *CartService
+	addressService *AddressService
+	productService *ProductService
+	taxCalculator  TaxCalculator
+}
+
+func NewOrderService(db *gorm.DB, notifications *NotificationService, authService *AuthService, cartService *CartService, addressService *AddressService, productService *ProductService, taxCalculator TaxCalculator) *OrderService {
+	return &OrderService{
+		db:             db,
+		notifications:  notifications,
+		authService:    authService,
+		cartService:    cartService,
+		addressService: addressService,
+		productService: productService,
+		taxCalculator:  taxCalculator,
+	}
+}
+
+// Checkout turns the owner's cart into an order. Signed-in users use their saved addresses
+// unless they enter one; guests give an email and address and get the order number by email.
+func (s *OrderService) Checkout(owner CartOwner, req *dto.CheckoutRequest) (*dto.OrderResponse, error) {
+	cart, err := s.cartService.findCart(owner)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return nil, ErrEmptyCart
+	}
+
+	order := models.Order{Status: models.OrderStatusPending}
+	if owner.UserID != 0 {
+		var user models.User
+		if err := s.db.First(&user, owner.UserID).Error; err != nil {
+			return nil, ErrUserNotFound
+		}
+		order.UserID = &user.ID
+		order.Email = normalizeEmail(user.Email)
+
+		if req.ShippingAddress == nil {
+			order.ShippingAddress, order.BillingAddress, err = s.addressService.CheckoutAddresses(user.ID, req.ShippingAddressID, req.BillingAddressID)
+		} else {
+			order.ShippingAddress, order.BillingAddress, err = checkoutAddressSnapshots(req)
+		}
+	} else {
+		if req.Email == "" || req.ShippingAddress == nil {
+			return nil, ErrGuestDetailsRequired
+		}
+		order.Email = normalizeEmail(req.Email)
+		order.ShippingAddress, order.BillingAddress, err = checkoutAddressSnapshots(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	order.OrderNumber, err = generateOrderNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return placeOrder(tx, cart, &order, req.ShippingMethodID, s.taxCalculator)
+	}); err != nil {
+		return nil, err
+	}
+
+	s.notifyPlaced(order.ID)
+
+	return s.getOrder(s.db.Where("id = ?", order.ID))
+}
+
+// ListOrders returns the orders of a signed-in user, newest first
+func (s *OrderService) ListOrders(userID uint, page, limit int) ([]dto.OrderResponse, *utils.PaginationMeta, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	var total int64
+	if err := s.db.Model(&models.Order{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var orders []models.Order
+	if err := s.preloadOrder(s.db).Where("user_id = ?", userID).
+		Order("created_at DESC").Offset(offset).Limit(limit).Find(&orders).Error; err != nil {
+		return nil, nil, err
+	}
+
+	response := make([]dto.OrderResponse, len(orders))
+	for i := range orders {
+		response[i] = s.convertToOrderResponse(&orders[i])
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	meta := &utils.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		Total:      int(total),
+		TotalPages: totalPages,
+	}
+
+	return response, meta, nil
+}
+
+func (s *OrderService) GetOrder(userID, orderID uint) (*dto.OrderResponse, error) {
+	return s.getOrder(s.db.Where("id = ? AND user_id = ?", orderID, userID))
+}
+
+// LookupOrder finds an order from its number and the email it was placed with
+func (s *OrderService) LookupOrder(req *dto.OrderLookupRequest) (*dto.OrderResponse, error) {
+	return s.getOrder(s.db.Where("order_number = ? AND email = ?",
+		strings.ToUpper(strings.TrimSpace(req.OrderNumber)), normalizeEmail(req.Email)))
+}
+
+// ConfirmOrder records that a pending order has been paid, which lets it be invoiced
+// and shipped
+func (s *OrderService) ConfirmOrder(orderID uint) (*dto.OrderResponse, error) {
+	result := s.db.Model(&models.Order{}).
+		Where("id = ? AND status = ?", orderID, models.OrderStatusPending).
+		Update("status", models.OrderStatusConfirmed)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := s.db.Model(&models.Order{}).Where("id = ?", orderID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, ErrOrderNotFound
+		}
+		return nil, ErrOrderNotPending
+	}
+
+	return s.getOrder(s.db.Where("id = ?", orderID))
+}
+
+// placeOrder creates the order from the cart lines, applies the running promotions and
+// redeems the cart's coupon, charges the chosen shipping method and the tax for the
+// shipping address on the discounted lines, takes the ordered quantities out of stock
+// and empties the cart. A guest cart is removed together with its token.
+func placeOrder(tx *gorm.DB, cart *models.Cart, order *models.Order, shippingMethodID uint, taxCalculator TaxCalculator) error {
+	var items []models.CartItem
+	if err := tx.Where("cart_id = ?", cart.ID).Order("id").Find(&items).Error; err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return ErrEmptyCart
+	}
+
+	productIDs := make([]uint, len(items))
+	for i := range items {
+		productIDs[i] = items[i].ProductID
+	}
+
+	// Lock the products so concurrent checkouts cannot sell the same stock twice
+	var products []models.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+		return err
+	}
+	productsByID := make(map[uint]*models.Product, len(products))
+	for i := range products {
+		productsByID[products[i].ID] = &products[i]
+	}
+
+	lines := make([]promotionLine, 0, len(items))
+	taxable := make([]TaxableLine, 0, len(items))
+	var weight float64
+	for _, item := range items {
+		product, ok := productsByID[item.ProductID]
+		if !ok || !product.IsActive {
+			return fmt.Errorf("%w: product %d", ErrProductUnavailable, item.ProductID)
+		}
+		if product.Stock < item.Quantity {
+			return fmt.Errorf("%w: %s", ErrInsufficientStock, product.Name)
+		}
+
+		// Items are charged at the sale price while the product is on sale
+		price := product.CurrentPrice()
+		order.OrderItems = append(order.OrderItems, models.OrderItem{
+			ProductID: product.ID,
+			Quantity:  item.Quantity,
+			Price:     price,
+		})
+		order.TotalAmount += price * float64(item.Quantity)
+		lines = append(lines, promotionLine{
+			ProductID:  product.ID,
+			CategoryID: product.CategoryID,
+			UnitPrice:  price,
+			Quantity:   item.Quantity,
+		})
+		taxable = append(taxable, TaxableLine{TaxClass: product.TaxClass})
+		weight += product.ShippingWeight() * float64(item.Quantity)
+	}
+	order.TotalAmount = roundMoney(order.TotalAmount)
+
+	promotions, err := loadActivePromotions(tx, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, result := range applyPromotions(promotions, lines) {
+		order.Discounts = append(order.Discounts, models.OrderDiscount{
+			Source:      models.DiscountSourcePromotion,
+			PromotionID: &result.Promotion.ID,
+			Type:        string(result.Promotion.Type),
+			Description: result.Promotion.Name + ": " + result.Explanation,
+			Amount:      result.Amount,
+		})
+		order.DiscountTotal = roundMoney(order.DiscountTotal + result.Amount)
+		order.TotalAmount = roundMoney(order.TotalAmount - result.Amount)
+	}
+
+	if cart.CouponID != nil {
+		if err := redeemCoupon(tx, *cart.CouponID, order, lines); err != nil {
+			return err
+		}
+	}
+
+	parcel := shippingParcel{Weight: weight}
+	for i := range lines {
+		taxable[i].Amount = roundMoney(lines[i].remaining())
+		parcel.Subtotal += taxable[i].Amount
+	}
+	if err := applyShipping(tx, order, parcel, shippingMethodID); err != nil {
+		return err
+	}
+	applyFreeShipping(order)
+
+	tax, err := taxCalculator.Calculate(TaxLocation{
+		CountryCode: order.ShippingAddress.CountryCode,
+		Region:      order.ShippingAddress.Region,
+	}, taxable)
+	if err != nil {
+		return err
+	}
+	order.TaxTotal = tax.Total
+	order.PricesIncludeTax = tax.Inclusive
+	if !tax.Inclusive {
+		order.TotalAmount = roundMoney(order.TotalAmount + tax.Total)
+	}
+	for i := range order.OrderItems {
+		order.OrderItems[i].TaxAmount = tax.LineTotals[i]
+	}
+
+	if err := tx.Create(order).Error; err != nil {
+		return errors.New("failed to create order")
+	}
+
+	// Tax lines point at the order items, so they are written once the items have IDs
+	for i := range order.OrderItems {
+		for _, line := range tax.Lines[i] {
+			order.TaxLines = append(order.TaxLines, models.OrderTaxLine{
+				OrderID:     order.ID,
+				OrderItemID: order.OrderItems[i].ID,
+				Name:        line.Name,
+				CountryCode: line.CountryCode,
+				Region:      line.Region,
+				Rate:        line.Rate,
+				Amount:      line.Amount,
+			})
+		}
+	}
+	if len(order.TaxLines) > 0 {
+		if err := tx.Create(&order.TaxLines).Error; err != nil {
+			return errors.New("failed to create order tax lines")
+		}
+	}
+
+	for _, item := range items {
+		if err := adjustStock(tx, &models.StockMovement{
+			ProductID: item.ProductID,
+			Quantity:  -item.Quantity,
+			Reason:    models.StockMovementSale,
+			OrderID:   &order.ID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Unscoped().Where("cart_id = ?", cart.ID).Delete(&models.CartItem{}).Error; err != nil {
+		return err
+	}
+	if cart.UserID == nil {
+		return tx.Unscoped().Delete(cart).Error
+	}
+	// The coupon was used up by this order
+	return tx.Model(cart).Update("coupon_id", nil).Error
+}
+
+// checkoutAddressSnapshots takes the addresses entered at checkout. Billing falls back to shipping.
+func checkoutAddressSnapshots(req *dto.CheckoutRequest) (shipping, billing models.AddressSnapshot, err error) {
+	if shipping, err = snapshotAddressRequest(req.ShippingAddress); err != nil {
+		return shipping, billing, err
+	}
+
+	if req.BillingAddress == nil {
+		return shipping, shipping, nil
+	}
+	billing, err = snapshotAddressRequest(req.BillingAddress)
+	return shipping, billing, err
+}
+
+// generateOrderNumber returns a random, hard to guess order number such as ORD-3F9A1C77E2
+func generateOrderNumber() (string, error) {
+	random, err := utils.GenerateRandomToken(5)
+	if err != nil {
+		return "", err
+	}
+	return "ORD-" + strings.ToUpper(random), nil
+}
+
+// notifyPlaced emails the customer a summary of the order they placed
+func (s *OrderService) notifyPlaced(orderID uint) {
+	var order models.Order
+	if err := s.db.Preload("OrderItems", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("OrderItems.Product").First(&order, orderID).Error; err != nil {
+		return
+	}
+
+	data := orderConfirmationEmail{
+		Name:        recipientName(s.db, &order),
+		OrderNumber: order.OrderNumber,
+		Discount:    order.DiscountTotal,
+		Shipping:    order.ShippingCost,
+		Tax:         order.TaxTotal,
+		TaxIncluded: order.PricesIncludeTax,
+		Total:       order.TotalAmount,
+		OrderURL:    s.notifications.orderURL(order.OrderNumber),
+	}
+	for _, item := range order.OrderItems {
+		total := roundMoney(item.Price * float64(item.Quantity))
+		data.Subtotal += total
+		data.Items = append(data.Items, notificationItem{Name: item.Product.Name, Quantity: item.Quantity, Total: total})
+	}
+	data.Subtotal = roundMoney(data.Subtotal)
+
+	_ = s.notifications.Notify(models.NotificationOrderConfirmation, order.UserID, order.Email, data)
+}
+
+func (s *OrderService) getOrder(query *gorm.DB) (*dto.OrderResponse, error) {
+	var order models.Order
+	if err := s.preloadOrder(query).First(&order).Error; err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	response := s.convertToOrderResponse(&order)
+	return &response, nil
+}
+
+func (s *OrderService) preloadOrder(query *gorm.DB) *gorm.DB {
+	return query.Preload("OrderItems.Product.Category").Preload("OrderItems.Product.Images").
+		Preload("Discounts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Preload("TaxLines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Preload("Shipments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("shipped_at, id")
+		}).
+		Preload("Shipments.Items.OrderItem.Product")
+}
+
+func (s *OrderService) convertToOrderResponse(order *models.Order) dto.OrderResponse {
+	shipments := make([]dto.ShipmentResponse, len(order.Shipments))
+	shipped := make(map[uint]int)
+	for i := range order.Shipments {
+		shipments[i] = convertToShipmentResponse(&order.Shipments[i])
+		for _, item := range order.Shipments[i].Items {
+			shipped[item.OrderItemID] += item.Quantity
+		}
+	}
+
+	items := make([]dto.OrderItemResponse, len(order.OrderItems))
+	for i := range order.OrderItems {
+		items[i] = dto.OrderItemResponse{
+			ID:        order.OrderItems[i].ID,
+			Product:   s.productService.convertToProductResponse(&order.OrderItems[i].Product),
+			Quantity:  order.OrderItems[i].Quantity,
+			Price:     order.OrderItems[i].Price,
+			Tax:       order.OrderItems[i].TaxAmount,
+			Shipped:   shipped[order.OrderItems[i].ID],
+			CreatedAt: order.OrderItems[i].CreatedAt,
+		}
+	}
+
+	// The subtotal is what the items cost before discounts, without shipping or tax added on top
+	subtotal := order.TotalAmount + order.DiscountTotal - order.ShippingCost
+	if !order.PricesIncludeTax {
+		subtotal -= order.TaxTotal
+	}
+
+	discounts := make([]dto.OrderDiscountResponse, len(order.Discounts))
+	for i := range order.Discounts {
+		discounts[i] = dto.OrderDiscountResponse{
+			Source:      string(order.Discounts[i].Source),
+			Code:        order.Discounts[i].Code,
+			Type:        order.Discounts[i].Type,
+			Description: order.Discounts[i].Description,
+			Amount:      order.Discounts[i].Amount,
+		}
+	}
+
+	return dto.OrderResponse{
+		ID:               order.ID,
+		UserID:           order.UserID,
+		OrderNumber:      order.OrderNumber,
+		Email:            order.Email,
+		Status:           string(order.Status),
+		Subtotal:         roundMoney(subtotal),
+		DiscountTotal:    order.DiscountTotal,
+		TotalAmount:      order.TotalAmount,
+		OrderItems:       items,
+		Discounts:        discounts,
+		ShippingAddress:  dto.AddressSnapshotResponse(order.ShippingAddress),
+		BillingAddress:   dto.AddressSnapshotResponse(order.BillingAddress),
+		CreatedAt:        order.CreatedAt,
+		UpdatedAt:        order.UpdatedAt,
+		TaxTotal:         order.TaxTotal,
+		TaxLines:         summarizeTaxLines([][]TaxLine{orderTaxLines(order)}),
+		PricesIncludeTax: order.PricesIncludeTax,
+		ShippingMethodID: order.ShippingMethodID,
+		ShippingMethod:   order.ShippingMethodName,
+		ShippingCost:     order.ShippingCost,
+		Shipments:        shipments,
+		RefundedTotal:    order.RefundedTotal,
+	}
+}
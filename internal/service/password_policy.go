@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/programmerjide/ecommerce/internal/config"
+)
+
+// Password policy rule identifiers returned in validation errors
+const (
+	PasswordRuleMinLength     = "min_length"
+	PasswordRuleMaxLength     = "max_length"
+	PasswordRuleUppercase     = "uppercase"
+	PasswordRuleLowercase     = "lowercase"
+	PasswordRuleDigit         = "digit"
+	PasswordRuleSymbol        = "symbol"
+	PasswordRuleContainsEmail = "contains_email"
+	PasswordRuleContainsName  = "contains_name"
+	PasswordRuleBreached      = "breached"
+)
+
+// hashPrefixLength is the number of hex characters used to bucket breached hashes,
+// mirroring the k-anonymity range lookups of breached password services
+const hashPrefixLength = 5
+
+// PasswordContext carries the personal details a password must not contain
+type PasswordContext struct {
+	Email     string
+	FirstName string
+	LastName  string
+}
+
+// PolicyViolation describes a single password rule that was not met
+type PolicyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError lists every rule a password failed
+type PasswordPolicyError struct {
+	Violations []PolicyViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+	return "password does not meet policy: " + strings.Join(messages, "; ")
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrValidationFailed
+}
+
+// PasswordPolicy validates new passwords for registration, change and reset
+type PasswordPolicy struct {
+	config   *config.PasswordConfig
+	breached map[string]map[string]struct{}
+}
+
+// NewPasswordPolicy builds the policy and loads the breached password list when configured
+func NewPasswordPolicy(cfg *config.PasswordConfig) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{
+		config:   cfg,
+		breached: make(map[string]map[string]struct{}),
+	}
+
+	if cfg.BreachedListPath != "" {
+		if err := policy.loadBreachedList(cfg.BreachedListPath); err != nil {
+			return nil, err
+		}
+	}
+	return policy, nil
+}
+
+// Validate checks the password against every rule and returns a *PasswordPolicyError listing the failures
+func (p *PasswordPolicy) Validate(password string, ctx PasswordContext) error {
+	var violations []PolicyViolation
+	add := func(rule, message string) {
+		violations = append(violations, PolicyViolation{Rule: rule, Message: message})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.config.MinLength {
+		add(PasswordRuleMinLength, fmt.Sprintf("must be at least %d characters long", p.config.MinLength))
+	}
+	// bcrypt ignores everything past 72 bytes, so the limit is enforced on bytes
+	if p.config.MaxLength > 0 && len(password) > p.config.MaxLength {
+		add(PasswordRuleMaxLength, fmt.Sprintf("must be at most %d bytes long", p.config.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.config.RequireUppercase && !hasUpper {
+		add(PasswordRuleUppercase, "must contain an uppercase letter")
+	}
+	if p.config.RequireLowercase && !hasLower {
+		add(PasswordRuleLowercase, "must contain a lowercase letter")
+	}
+	if p.config.RequireDigit && !hasDigit {
+		add(PasswordRuleDigit, "must contain a digit")
+	}
+	if p.config.RequireSymbol && !hasSymbol {
+		add(PasswordRuleSymbol, "must contain a symbol")
+	}
+
+	lowered := strings.ToLower(password)
+	if localPart, _, _ := strings.Cut(strings.ToLower(ctx.Email), "@"); containsPersonalValue(lowered, localPart) {
+		add(PasswordRuleContainsEmail, "must not contain your email address")
+	}
+	if containsPersonalValue(lowered, strings.ToLower(ctx.FirstName)) || containsPersonalValue(lowered, strings.ToLower(ctx.LastName)) {
+		add(PasswordRuleContainsName, "must not contain your name")
+	}
+
+	if p.isBreached(password) {
+		add(PasswordRuleBreached, "has appeared in a known data breach, choose a different password")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// isBreached looks the password up by the prefix of its SHA-1 hash, then by the remaining suffix
+func (p *PasswordPolicy) isBreached(password string) bool {
+	// SHA-1 is the format breached password corpora are published in; it is never used for storage
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, ok := p.breached[hash[:hashPrefixLength]]
+	if !ok {
+		return false
+	}
+	_, found := suffixes[hash[hashPrefixLength:]]
+	return found
+}
+
+// loadBreachedList reads one SHA-1 hash per line, optionally followed by ":count"
+func (p *PasswordPolicy) loadBreachedList(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(line, ":")
+		hash = strings.ToUpper(hash)
+		if len(hash) != sha1.Size*2 {
+			continue
+		}
+
+		prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+		if p.breached[prefix] == nil {
+			p.breached[prefix] = make(map[string]struct{})
+		}
+		p.breached[prefix][suffix] = struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read breached password list: %w", err)
+	}
+	return nil
+}
+
+// containsPersonalValue ignores very short values that would reject too many passwords
+func containsPersonalValue(password, value string) bool {
+	value = strings.TrimSpace(value)
+	return utf8.RuneCountInString(value) >= 3 && strings.Contains(password, value)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// postalCodeFormat describes the postal codes of a single country
+type postalCodeFormat struct {
+	pattern *regexp.Regexp
+	example string
+	// optional is set for countries where postal codes exist but are rarely used
+	optional bool
+}
+
+var postalCodeFormats = map[string]postalCodeFormat{
+	"AU": {pattern: regexp.MustCompile(`^\d{4}$`), example: "2000"},
+	"BR": {pattern: regexp.MustCompile(`^\d{5}-?\d{3}$`), example: "01310-100"},
+	"CA": {pattern: regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] ?\d[ABCEGHJ-NPRSTV-Z]\d$`), example: "K1A 0B1"},
+	"DE": {pattern: regexp.MustCompile(`^\d{5}$`), example: "10115"},
+	"ES": {pattern: regexp.MustCompile(`^\d{5}$`), example: "28013"},
+	"FR": {pattern: regexp.MustCompile(`^\d{5}$`), example: "75001"},
+	"GB": {pattern: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`), example: "SW1A 1AA"},
+	"GH": {pattern: regexp.MustCompile(`^[A-Z]{2}-?\d{3,4}-?\d{4}$`), example: "GA-183-8164", optional: true},
+	"IE": {pattern: regexp.MustCompile(`^[A-Z]\d[\dW] ?[0-9A-Z]{4}$`), example: "D02 X285", optional: true},
+	"IN": {pattern: regexp.MustCompile(`^[1-9]\d{5}$`), example: "110001"},
+	"IT": {pattern: regexp.MustCompile(`^\d{5}$`), example: "00184"},
+	"JP": {pattern: regexp.MustCompile(`^\d{3}-?\d{4}$`), example: "100-0001"},
+	"KE": {pattern: regexp.MustCompile(`^\d{5}$`), example: "00100"},
+	"NG": {pattern: regexp.MustCompile(`^\d{6}$`), example: "100001", optional: true},
+	"NL": {pattern: regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`), example: "1012 AB"},
+	"US": {pattern: regexp.MustCompile(`^\d{5}(-\d{4})?$`), example: "94105"},
+	"ZA": {pattern: regexp.MustCompile(`^\d{4}$`), example: "8001"},
+}
+
+// countriesWithoutPostalCodes do not use postal codes at all
+var countriesWithoutPostalCodes = map[string]bool{
+	"AE": true,
+	"HK": true,
+	"QA": true,
+}
+
+// genericPostalCode accepts any plausible code for countries without a specific format
+var genericPostalCode = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,9}$`)
+
+// normalizePostalCode validates a postal code for the country and returns it in canonical form
+func normalizePostalCode(countryCode, postalCode string) (string, error) {
+	postalCode = strings.ToUpper(strings.Join(strings.Fields(postalCode), " "))
+
+	if countriesWithoutPostalCodes[countryCode] {
+		return "", nil
+	}
+
+	format, known := postalCodeFormats[countryCode]
+	if postalCode == "" {
+		if known && !format.optional {
+			return "", fmt.Errorf("%w: postal code is required for %s", ErrInvalidPostalCode, countryCode)
+		}
+		return "", nil
+	}
+
+	if !known {
+		if !genericPostalCode.MatchString(postalCode) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidPostalCode, postalCode)
+		}
+		return postalCode, nil
+	}
+
+	if !format.pattern.MatchString(postalCode) {
+		return "", fmt.Errorf("%w: %q is not valid for %s, expected a code like %s", ErrInvalidPostalCode, postalCode, countryCode, format.example)
+	}
+	return postalCode, nil
+}
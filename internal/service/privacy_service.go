@@ -0,0 +1,481 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// erasedName replaces personal names on anonymized accounts and orders
+const erasedName = "Erased"
+
+// PrivacyService answers data-subject requests: exporting and erasing a customer's personal data
+type PrivacyService struct {
+	db              *gorm.DB
+	notifications   *NotificationService
+	cartService     *CartService
+	orderService    *OrderService
+	wishlistService *WishlistService
+}
+
+func NewPrivacyService(db *gorm.DB, notifications *NotificationService, cartService *CartService, orderService *OrderService, wishlistService *WishlistService) *PrivacyService {
+	return &PrivacyService{
+		db:              db,
+		notifications:   notifications,
+		cartService:     cartService,
+		orderService:    orderService,
+		wishlistService: wishlistService,
+	}
+}
+
+// ExportUserData collects everything we hold about a user
+func (s *PrivacyService) ExportUserData(userID uint) (*dto.UserDataExport, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	export := &dto.UserDataExport{
+		GeneratedAt: time.Now().UTC(),
+		Profile:     convertToAdminUserResponse(&user),
+	}
+
+	var addresses []models.Address
+	if err := s.db.Where("user_id = ?", userID).Order("created_at").Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	export.Addresses = make([]dto.AddressResponse, len(addresses))
+	for i := range addresses {
+		export.Addresses[i] = convertToAddressResponse(&addresses[i])
+	}
+
+	var orders []models.Order
+	if err := s.orderService.preloadOrder(s.db.Where("user_id = ?", userID)).
+		Order("created_at").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	export.Orders = make([]dto.OrderResponse, len(orders))
+	for i := range orders {
+		export.Orders[i] = s.orderService.convertToOrderResponse(&orders[i])
+	}
+
+	var cart models.Cart
+	err := s.db.Preload("CartItems.Product.Category").Preload("Coupon.Products").Preload("Coupon.Categories").
+		Where("user_id = ?", userID).First(&cart).Error
+	switch {
+	case err == nil:
+		if export.Cart, err = s.cartService.convertToCartResponse(&cart); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	wishlists, err := s.wishlistService.ListWishlists(userID)
+	if err != nil {
+		return nil, err
+	}
+	export.Wishlists = wishlists
+
+	var sessions []models.RefreshToken
+	if err := s.db.Where("user_id = ?", userID).Order("created_at").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	export.Sessions = make([]dto.SessionResponse, len(sessions))
+	for i := range sessions {
+		export.Sessions[i] = dto.SessionResponse{
+			ID:           sessions[i].ID,
+			UserAgent:    sessions[i].UserAgent,
+			IPAddress:    sessions[i].IPAddress,
+			Impersonated: sessions[i].ImpersonatorID != nil,
+			CreatedAt:    sessions[i].CreatedAt,
+			LastUsedAt:   sessions[i].LastUsedAt,
+			ExpiresAt:    sessions[i].ExpiresAt,
+		}
+	}
+
+	var identities []models.LinkedIdentity
+	if err := s.db.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	export.LinkedIdentities = make([]dto.LinkedIdentityResponse, len(identities))
+	for i := range identities {
+		export.LinkedIdentities[i] = dto.LinkedIdentityResponse{
+			ID:        identities[i].ID,
+			Provider:  identities[i].Provider,
+			Email:     identities[i].Email,
+			CreatedAt: identities[i].CreatedAt,
+		}
+	}
+
+	requests, err := s.ListMyErasureRequests(userID)
+	if err != nil {
+		return nil, err
+	}
+	export.ErasureRequests = requests
+
+	return export, nil
+}
+
+// ExportUserDataArchive packs the export into a ZIP archive with one JSON file per section
+func (s *PrivacyService) ExportUserDataArchive(userID uint) ([]byte, error) {
+	export, err := s.ExportUserData(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"addresses.json", export.Addresses},
+		{"orders.json", export.Orders},
+		{"cart.json", export.Cart},
+		{"wishlists.json", export.Wishlists},
+		{"sessions.json", export.Sessions},
+		{"linked_identities.json", export.LinkedIdentities},
+		{"erasure_requests.json", export.ErasureRequests},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, section := range sections {
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     section.name,
+			Method:   zip.Deflate,
+			Modified: export.GeneratedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(section.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RequestErasure queues an erasure request for staff review
+func (s *PrivacyService) RequestErasure(userID uint, req *dto.CreateErasureRequest) (*dto.ErasureRequestResponse, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var pending int64
+	if err := s.db.Model(&models.ErasureRequest{}).
+		Where("user_id = ? AND status = ?", userID, models.ErasureRequestStatusPending).
+		Count(&pending).Error; err != nil {
+		return nil, err
+	}
+	if pending > 0 {
+		return nil, ErrErasureRequestPending
+	}
+
+	request := models.ErasureRequest{
+		UserID: userID,
+		Status: models.ErasureRequestStatusPending,
+		Reason: req.Reason,
+	}
+	if err := s.db.Create(&request).Error; err != nil {
+		return nil, errors.New("failed to create erasure request")
+	}
+
+	_ = s.notifications.Notify(models.NotificationErasureReceived, &user.ID, user.Email, erasureEmail{
+		Name: user.FirstName,
+	})
+
+	response := convertToErasureRequestResponse(&request)
+	return &response, nil
+}
+
+// ListMyErasureRequests returns a user's own erasure requests
+func (s *PrivacyService) ListMyErasureRequests(userID uint) ([]dto.ErasureRequestResponse, error) {
+	var requests []models.ErasureRequest
+	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.ErasureRequestResponse, len(requests))
+	for i := range requests {
+		response[i] = convertToErasureRequestResponse(&requests[i])
+	}
+	return response, nil
+}
+
+// CancelErasure withdraws the user's pending erasure request
+func (s *PrivacyService) CancelErasure(userID uint) error {
+	result := s.db.Model(&models.ErasureRequest{}).
+		Where("user_id = ? AND status = ?", userID, models.ErasureRequestStatusPending).
+		Update("status", models.ErasureRequestStatusCancelled)
+	if result.Error != nil {
+		return errors.New("failed to cancel erasure request")
+	}
+	if result.RowsAffected == 0 {
+		return ErrErasureRequestNotFound
+	}
+	return nil
+}
+
+// ListErasureRequests returns the review queue for staff
+func (s *PrivacyService) ListErasureRequests(req *dto.ListErasureRequestsRequest) ([]dto.ErasureRequestResponse, *utils.PaginationMeta, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	query := s.db.Model(&models.ErasureRequest{})
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var requests []models.ErasureRequest
+	if err := query.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Order("created_at ASC").Offset(offset).Limit(req.Limit).Find(&requests).Error; err != nil {
+		return nil, nil, err
+	}
+
+	response := make([]dto.ErasureRequestResponse, len(requests))
+	for i := range requests {
+		response[i] = convertToErasureRequestResponse(&requests[i])
+		response[i].UserEmail = requests[i].User.Email
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	meta := &utils.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      int(total),
+		TotalPages: totalPages,
+	}
+
+	return response, meta, nil
+}
+
+// ApproveErasure anonymizes the account behind a pending request. Orders are kept
+// for accounting and tax purposes but lose the personal details they carried.
+func (s *PrivacyService) ApproveErasure(reviewerID, requestID uint, req *dto.ReviewErasureRequest) (*dto.ErasureRequestResponse, error) {
+	var request models.ErasureRequest
+	var original models.User
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", requestID, models.ErasureRequestStatusPending).
+			First(&request).Error; err != nil {
+			return ErrErasureRequestNotFound
+		}
+
+		if request.UserID == reviewerID {
+			return errors.New("you cannot approve your own erasure request")
+		}
+
+		var user models.User
+		if err := tx.Unscoped().First(&user, request.UserID).Error; err != nil {
+			return ErrUserNotFound
+		}
+		original = user
+
+		if err := anonymizeUser(tx, &user); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		request.Status = models.ErasureRequestStatusCompleted
+		request.ReviewerID = &reviewerID
+		request.ReviewNote = req.Note
+		request.ReviewedAt = &now
+		request.CompletedAt = &now
+		return tx.Save(&request).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The user is captured before anonymizing so the customer still gets confirmation.
+	// It is sent without the outbox, which would keep the address.
+	_ = s.notifications.NotifyUntracked(models.NotificationErasureCompleted, original.Locale, original.Email, erasureEmail{
+		Name: original.FirstName,
+	})
+
+	response := convertToErasureRequestResponse(&request)
+	return &response, nil
+}
+
+// RejectErasure declines a pending request, for example while an order is still in dispute
+func (s *PrivacyService) RejectErasure(reviewerID, requestID uint, req *dto.ReviewErasureRequest) (*dto.ErasureRequestResponse, error) {
+	var request models.ErasureRequest
+	if err := s.db.Preload("User").
+		Where("id = ? AND status = ?", requestID, models.ErasureRequestStatusPending).
+		First(&request).Error; err != nil {
+		return nil, ErrErasureRequestNotFound
+	}
+
+	now := time.Now()
+	request.Status = models.ErasureRequestStatusRejected
+	request.ReviewerID = &reviewerID
+	request.ReviewNote = req.Note
+	request.ReviewedAt = &now
+	if err := s.db.Save(&request).Error; err != nil {
+		return nil, errors.New("failed to reject erasure request")
+	}
+
+	_ = s.notifications.Notify(models.NotificationErasureRejected, &request.UserID, request.User.Email, erasureEmail{
+		Name: request.User.FirstName,
+		Note: req.Note,
+	})
+
+	response := convertToErasureRequestResponse(&request)
+	return &response, nil
+}
+
+// anonymizeUser strips personal data from a user, their orders and every dependent record
+func anonymizeUser(tx *gorm.DB, user *models.User) error {
+	// A bcrypt hash of a random secret nobody knows makes password login impossible
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+	password, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	email := normalizeEmail(user.Email)
+	if err := tx.Where("scope = ? AND key = ?", models.LoginThrottleScopeAccount, email).
+		Delete(&models.LoginThrottle{}).Error; err != nil {
+		return err
+	}
+
+	// Emails sent to the user are copies of their personal data too
+	if err := tx.Where("LOWER(recipient) = ?", email).Delete(&models.OutboxEmail{}).Error; err != nil {
+		return err
+	}
+
+	// Unused account links sent to the address hold it as well
+	if err := tx.Where("user_id IS NULL AND payload = ?", email).Delete(&models.VerificationToken{}).Error; err != nil {
+		return err
+	}
+
+	// Guest orders placed with the same email belong to the person as well
+	var orderIDs []uint
+	if err := tx.Unscoped().Model(&models.Order{}).
+		Where("user_id = ? OR (user_id IS NULL AND LOWER(email) = ?)", user.ID, email).
+		Pluck("id", &orderIDs).Error; err != nil {
+		return err
+	}
+
+	erasedEmail := fmt.Sprintf("erased-%d@erased.invalid", user.ID)
+	if err := tx.Unscoped().Model(user).Updates(map[string]interface{}{
+		"email":      erasedEmail,
+		"password":   string(password),
+		"first_name": erasedName,
+		"last_name":  erasedName,
+		"phone":      "",
+		"is_active":  false,
+	}).Error; err != nil {
+		return err
+	}
+
+	// Orders stay for bookkeeping. The country, region and postal code are kept
+	// because they determine the tax that was charged.
+	if err := tx.Unscoped().Model(&models.Order{}).Where("id IN ?", orderIDs).Updates(map[string]interface{}{
+		"email":            erasedEmail,
+		"shipping_name":    erasedName,
+		"shipping_company": "",
+		"shipping_line1":   "",
+		"shipping_line2":   "",
+		"shipping_city":    "",
+		"shipping_phone":   "",
+		"billing_name":     erasedName,
+		"billing_company":  "",
+		"billing_line1":    "",
+		"billing_line2":    "",
+		"billing_city":     "",
+		"billing_phone":    "",
+	}).Error; err != nil {
+		return err
+	}
+
+	// Notes on returns are free text written by the customer or about them
+	if err := tx.Model(&models.ReturnRequest{}).Where("order_id IN ?", orderIDs).
+		Update("customer_note", "").Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("UPDATE return_items SET note = '' WHERE return_request_id IN (SELECT id FROM return_requests WHERE order_id IN ?)", orderIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("UPDATE return_events SET note = '' WHERE return_request_id IN (SELECT id FROM return_requests WHERE order_id IN ?)", orderIDs).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Where("user_id = ? OR impersonator_id = ?", user.ID, user.ID).Delete(&models.RefreshToken{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Exec("DELETE FROM cart_items WHERE cart_id IN (SELECT id FROM carts WHERE user_id = ?)", user.ID).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Exec("DELETE FROM wishlist_items WHERE wishlist_id IN (SELECT id FROM wishlists WHERE user_id = ?)", user.ID).Error; err != nil {
+		return err
+	}
+
+	for _, model := range []interface{}{
+		&models.Cart{},
+		&models.Wishlist{},
+		&models.Address{},
+		&models.LinkedIdentity{},
+		&models.VerificationToken{},
+		&models.APIKey{},
+		&models.NotificationPreference{},
+	} {
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Delete(user).Error
+}
+
+func convertToErasureRequestResponse(request *models.ErasureRequest) dto.ErasureRequestResponse {
+	return dto.ErasureRequestResponse{
+		ID:          request.ID,
+		UserID:      request.UserID,
+		Status:      string(request.Status),
+		Reason:      request.Reason,
+		ReviewerID:  request.ReviewerID,
+		ReviewNote:  request.ReviewNote,
+		ReviewedAt:  request.ReviewedAt,
+		CompletedAt: request.CompletedAt,
+		CreatedAt:   request.CreatedAt,
+	}
+}
@@ -1,12 +1,24 @@
 package service
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/programmerjide/ecommerce/internal/dto"
 	"github.com/programmerjide/ecommerce/internal/models"
 	"github.com/programmerjide/ecommerce/internal/utils"
 	"gorm.io/gorm"
 )
 
+// lowestPriceWindow is how far back ProductResponse.LowestPrice30Days looks
+const lowestPriceWindow = 30 * 24 * time.Hour
+
+// saleActiveSQL matches products whose sale window is open right now, like Product.SaleActive
+const saleActiveSQL = "(sale_price IS NOT NULL AND (sale_starts_at IS NULL OR sale_starts_at <= NOW()) AND (sale_ends_at IS NULL OR sale_ends_at > NOW()))"
+
+// currentPriceSQL is the price customers pay, for filtering and sorting in SQL
+const currentPriceSQL = "CASE WHEN " + saleActiveSQL + " THEN sale_price ELSE price END"
+
 type ProductService struct {
 	db *gorm.DB
 }
@@ -91,7 +103,8 @@ func (s *ProductService) DeleteCategory(categoryID uint) error {
 	return nil
 }
 
-func (s *ProductService) CreateProduct(req *dto.CreateProductRequest) (*dto.ProductResponse, error) {
+// CreateProduct adds a product and starts its price history
+func (s *ProductService) CreateProduct(req *dto.CreateProductRequest, actorID uint) (*dto.ProductResponse, error) {
 	// Implementation for creating a product
 	product := &models.Product{
 		CategoryID:  req.CategoryID,
@@ -100,25 +113,34 @@ func (s *ProductService) CreateProduct(req *dto.CreateProductRequest) (*dto.Prod
 		Price:       req.Price,
 		Stock:       req.Stock,
 		SKU:         req.SKU,
+		TaxClass:    req.TaxClass,
+		Weight:      req.Weight,
+		Length:      req.Length,
+		Width:       req.Width,
+		Height:      req.Height,
+	}
+	if product.TaxClass == "" {
+		product.TaxClass = models.TaxClassStandard
+	}
+	if err := applyProductSale(product, &req.ProductSale, time.Now()); err != nil {
+		return nil, err
 	}
 
-	if err := s.db.Create(&product).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+		return recordPriceChange(tx, product, models.PriceChangeCreated, &actorID)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &dto.ProductResponse{
-		ID:          product.ID,
-		CategoryID:  product.CategoryID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		SKU:         product.SKU,
-		IsActive:    product.IsActive,
-	}, nil
+	return s.GetProduct(product.ID)
 }
 
-func (s *ProductService) GetProducts(page, limit int) ([]dto.ProductResponse, *utils.PaginationMeta, error) {
+func (s *ProductService) GetProducts(req *dto.ListProductsRequest) ([]dto.ProductResponse, *utils.PaginationMeta, error) {
+	page, limit := req.Page, req.Limit
 	if page < 1 {
 		page = 1
 	}
@@ -131,10 +153,14 @@ func (s *ProductService) GetProducts(page, limit int) ([]dto.ProductResponse, *u
 	var products []models.Product
 	var total int64
 
-	s.db.Model(&models.Product{}).Where("is_active = ?", true).Count(&total)
+	query := s.db.Model(&models.Product{}).Where("is_active = ?", true)
+	if req.OnSale != nil {
+		query = query.Where(onSaleCondition(*req.OnSale))
+	}
+
+	query.Count(&total)
 
-	if err := s.db.Preload("Category").Preload("Images").
-		Where("is_active = ?", true).
+	if err := query.Preload("Category").Preload("Images").
 		Offset(offset).Limit(limit).
 		Find(&products).Error; err != nil {
 		return nil, nil, err
@@ -144,6 +170,9 @@ func (s *ProductService) GetProducts(page, limit int) ([]dto.ProductResponse, *u
 	for i := range products {
 		response[i] = s.convertToProductResponse(&products[i])
 	}
+	if err := s.fillLowestPrices(response); err != nil {
+		return nil, nil, err
+	}
 
 	totalPages := int((total + int64(limit) - 1) / int64(limit))
 	meta := &utils.PaginationMeta{
@@ -162,25 +191,52 @@ func (s *ProductService) GetProduct(id uint) (*dto.ProductResponse, error) {
 		return nil, err
 	}
 
-	response := s.convertToProductResponse(&product)
-	return &response, nil
+	response := []dto.ProductResponse{s.convertToProductResponse(&product)}
+	if err := s.fillLowestPrices(response); err != nil {
+		return nil, err
+	}
+	return &response[0], nil
 }
 
-func (s *ProductService) UpdateProduct(productID uint, req *dto.UpdateProductRequest) (*dto.ProductResponse, error) {
+// UpdateProduct replaces a product. Changes to what it costs are written to the price
+// history together with the staff member who made them.
+func (s *ProductService) UpdateProduct(productID uint, req *dto.UpdateProductRequest, actorID uint) (*dto.ProductResponse, error) {
 	// Implementation for updating a product
 	var product models.Product
 	if err := s.db.First(&product, productID).Error; err != nil {
 		return nil, err
 	}
+	before := product
 
 	product.CategoryID = req.CategoryID
 	product.Name = req.Name
 	product.Description = req.Description
 	product.Price = req.Price
 	product.Stock = req.Stock
-	product.IsActive = *req.IsActive
+	if req.IsActive != nil {
+		product.IsActive = *req.IsActive
+	}
+	if req.TaxClass != "" {
+		product.TaxClass = req.TaxClass
+	}
+	product.Weight = req.Weight
+	product.Length = req.Length
+	product.Width = req.Width
+	product.Height = req.Height
+	if err := applyProductSale(&product, &req.ProductSale, time.Now()); err != nil {
+		return nil, err
+	}
 
-	if err := s.db.Save(&product).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&product).Error; err != nil {
+			return err
+		}
+		if !pricingChanged(&before, &product) {
+			return nil
+		}
+		return recordPriceChange(tx, &product, models.PriceChangeUpdated, &actorID)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -202,34 +258,11 @@ func (s *ProductService) GetProductsByCategory(categoryID uint) ([]dto.ProductRe
 	}
 
 	response := make([]dto.ProductResponse, len(products))
-	for i, product := range products {
-		images := make([]dto.ProductImageResponse, len(product.Images))
-		for j, img := range product.Images {
-			images[j] = dto.ProductImageResponse{
-				ID:        img.ID,
-				URL:       img.URL,
-				AltText:   img.AltText,
-				IsPrimary: img.IsPrimary,
-			}
-		}
-
-		response[i] = dto.ProductResponse{
-			ID:          product.ID,
-			CategoryID:  product.CategoryID,
-			Name:        product.Name,
-			Description: product.Description,
-			Price:       product.Price,
-			Stock:       product.Stock,
-			SKU:         product.SKU,
-			IsActive:    product.IsActive,
-			Category: dto.CategoryResponse{
-				ID:          product.Category.ID,
-				Name:        product.Category.Name,
-				Description: product.Category.Description,
-				IsActive:    product.Category.IsActive,
-			},
-			Images: images,
-		}
+	for i := range products {
+		response[i] = s.convertToProductResponse(&products[i])
+	}
+	if err := s.fillLowestPrices(response); err != nil {
+		return nil, err
 	}
 	return response, nil
 }
@@ -240,33 +273,8 @@ func (s *ProductService) GetProductByID(productID uint) (*dto.ProductResponse, e
 		return nil, err
 	}
 
-	images := make([]dto.ProductImageResponse, len(product.Images))
-	for j, img := range product.Images {
-		images[j] = dto.ProductImageResponse{
-			ID:        img.ID,
-			URL:       img.URL,
-			AltText:   img.AltText,
-			IsPrimary: img.IsPrimary,
-		}
-	}
-
-	return &dto.ProductResponse{
-		ID:          product.ID,
-		CategoryID:  product.CategoryID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		SKU:         product.SKU,
-		IsActive:    product.IsActive,
-		Category: dto.CategoryResponse{
-			ID:          product.Category.ID,
-			Name:        product.Category.Name,
-			Description: product.Category.Description,
-			IsActive:    product.Category.IsActive,
-		},
-		Images: images,
-	}, nil
+	response := s.convertToProductResponse(&product)
+	return &response, nil
 }
 
 func (s *ProductService) SearchProducts(req *dto.SearchProductsRequest) ([]dto.ProductSearchResult, *utils.PaginationMeta, error) {
@@ -291,12 +299,17 @@ func (s *ProductService) SearchProducts(req *dto.SearchProductsRequest) ([]dto.P
 		query = query.Where("category_id = ?", *req.CategoryID)
 	}
 
+	// Price filters match what customers pay, so sale items are found by their sale price
 	if req.MinPrice != nil {
-		query = query.Where("price >= ?", *req.MinPrice)
+		query = query.Where(currentPriceSQL+" >= ?", *req.MinPrice)
 	}
 
 	if req.MaxPrice != nil {
-		query = query.Where("price <= ?", *req.MaxPrice)
+		query = query.Where(currentPriceSQL+" <= ?", *req.MaxPrice)
+	}
+
+	if req.OnSale != nil {
+		query = query.Where(onSaleCondition(*req.OnSale))
 	}
 
 	// Count total results
@@ -320,10 +333,18 @@ func (s *ProductService) SearchProducts(req *dto.SearchProductsRequest) ([]dto.P
 	}
 
 	// Build output response
+	products := make([]dto.ProductResponse, len(rows))
+	for i := range rows {
+		products[i] = s.convertToProductResponse(&rows[i].Product)
+	}
+	if err := s.fillLowestPrices(products); err != nil {
+		return nil, nil, err
+	}
+
 	results := make([]dto.ProductSearchResult, len(rows))
 	for i := range rows {
 		results[i] = dto.ProductSearchResult{
-			ProductResponse: s.convertToProductResponse(&rows[i].Product),
+			ProductResponse: products[i],
 			Rank:            rows[i].Rank,
 		}
 	}
@@ -353,14 +374,25 @@ func (s *ProductService) convertToProductResponse(product *models.Product) dto.P
 	}
 
 	return dto.ProductResponse{
-		ID:          product.ID,
-		CategoryID:  product.CategoryID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		SKU:         product.SKU,
-		IsActive:    product.IsActive,
+		ID:             product.ID,
+		CategoryID:     product.CategoryID,
+		Name:           product.Name,
+		Description:    product.Description,
+		Price:          product.Price,
+		CurrentPrice:   product.CurrentPrice(),
+		IsOnSale:       product.SaleActive(time.Now()),
+		CompareAtPrice: product.CompareAtPrice,
+		SalePrice:      product.SalePrice,
+		SaleStartsAt:   product.SaleStartsAt,
+		SaleEndsAt:     product.SaleEndsAt,
+		Stock:          product.Stock,
+		SKU:            product.SKU,
+		IsActive:       product.IsActive,
+		TaxClass:       product.TaxClass,
+		Weight:         product.Weight,
+		Length:         product.Length,
+		Width:          product.Width,
+		Height:         product.Height,
 		Category: dto.CategoryResponse{
 			ID:          product.Category.ID,
 			Name:        product.Category.Name,
@@ -374,3 +406,164 @@ func (s *ProductService) convertToProductResponse(product *models.Product) dto.P
 		UpdatedAt: product.UpdatedAt,
 	}
 }
+
+// GetPriceHistory returns the pricing changes of a product, newest first
+func (s *ProductService) GetPriceHistory(productID uint, page, limit int) ([]dto.PriceHistoryResponse, *utils.PaginationMeta, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	// Deleted products keep their history for audits
+	var count int64
+	if err := s.db.Unscoped().Model(&models.Product{}).Where("id = ?", productID).Count(&count).Error; err != nil {
+		return nil, nil, err
+	}
+	if count == 0 {
+		return nil, nil, ErrProductNotFound
+	}
+
+	query := s.db.Model(&models.ProductPriceHistory{}).Where("product_id = ?", productID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var entries []models.ProductPriceHistory
+	if err := query.Preload("ChangedBy", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&entries).Error; err != nil {
+		return nil, nil, err
+	}
+
+	response := make([]dto.PriceHistoryResponse, len(entries))
+	for i := range entries {
+		response[i] = dto.PriceHistoryResponse{
+			ID:           entries[i].ID,
+			Price:        entries[i].Price,
+			SalePrice:    entries[i].SalePrice,
+			CurrentPrice: entries[i].CurrentPrice,
+			Reason:       entries[i].Reason,
+			ChangedByID:  entries[i].ChangedByID,
+			CreatedAt:    entries[i].CreatedAt,
+		}
+		if entries[i].ChangedBy != nil {
+			response[i].ChangedByEmail = entries[i].ChangedBy.Email
+		}
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	meta := &utils.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		Total:      int(total),
+		TotalPages: totalPages,
+	}
+
+	return response, meta, nil
+}
+
+// fillLowestPrices adds the lowest price customers paid over the last 30 days. The entry
+// in force when the window opened counts as well as the changes made since.
+func (s *ProductService) fillLowestPrices(products []dto.ProductResponse) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	productIDs := make([]uint, len(products))
+	for i := range products {
+		productIDs[i] = products[i].ID
+	}
+	since := time.Now().Add(-lowestPriceWindow)
+
+	inForce := s.db.Model(&models.ProductPriceHistory{}).
+		Select("MAX(id)").
+		Where("product_id IN ? AND created_at < ?", productIDs, since).
+		Group("product_id")
+
+	var rows []struct {
+		ProductID   uint
+		LowestPrice float64
+	}
+	if err := s.db.Model(&models.ProductPriceHistory{}).
+		Select("product_id, MIN(current_price) AS lowest_price").
+		Where("product_id IN ?", productIDs).
+		Where("created_at >= ? OR id IN (?)", since, inForce).
+		Group("product_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	lowest := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		lowest[row.ProductID] = row.LowestPrice
+	}
+	for i := range products {
+		if price, ok := lowest[products[i].ID]; ok {
+			products[i].LowestPrice30Days = &price
+		}
+	}
+	return nil
+}
+
+// recordPriceChange appends the product's pricing to its history
+func recordPriceChange(tx *gorm.DB, product *models.Product, reason string, actorID *uint) error {
+	if actorID != nil && *actorID == 0 {
+		actorID = nil
+	}
+	return tx.Create(&models.ProductPriceHistory{
+		ProductID:    product.ID,
+		Price:        product.Price,
+		SalePrice:    product.SalePrice,
+		CurrentPrice: product.CurrentPrice(),
+		Reason:       reason,
+		ChangedByID:  actorID,
+	}).Error
+}
+
+// onSaleCondition filters products by whether their sale window is open right now
+func onSaleCondition(onSale bool) string {
+	if onSale {
+		return saleActiveSQL
+	}
+	return "NOT " + saleActiveSQL
+}
+
+// pricingChanged reports whether an update changed what the product costs
+func pricingChanged(before, after *models.Product) bool {
+	switch {
+	case before.Price != after.Price, before.CurrentPrice() != after.CurrentPrice():
+		return true
+	case before.SalePrice == nil || after.SalePrice == nil:
+		return before.SalePrice != after.SalePrice
+	default:
+		return *before.SalePrice != *after.SalePrice
+	}
+}
+
+// applyProductSale sets the sale of a product and whether it is on sale right now
+func applyProductSale(product *models.Product, sale *dto.ProductSale, now time.Time) error {
+	switch {
+	case sale.SalePrice == nil && (sale.SaleStartsAt != nil || sale.SaleEndsAt != nil):
+		return fmt.Errorf("%w: a sale window needs a sale_price", ErrInvalidSale)
+	case sale.SalePrice != nil && *sale.SalePrice >= product.Price:
+		return fmt.Errorf("%w: sale_price must be below the price", ErrInvalidSale)
+	case sale.CompareAtPrice != nil && *sale.CompareAtPrice < product.Price:
+		return fmt.Errorf("%w: compare_at_price cannot be below the price", ErrInvalidSale)
+	case sale.SaleStartsAt != nil && sale.SaleEndsAt != nil && !sale.SaleEndsAt.After(*sale.SaleStartsAt):
+		return fmt.Errorf("%w: sale_ends_at must be after sale_starts_at", ErrInvalidSale)
+	}
+
+	product.CompareAtPrice = sale.CompareAtPrice
+	product.SalePrice = sale.SalePrice
+	product.SaleStartsAt = sale.SaleStartsAt
+	product.SaleEndsAt = sale.SaleEndsAt
+	product.IsOnSale = product.SaleActive(now)
+	return nil
+}
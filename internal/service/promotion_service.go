@@ -0,0 +1,464 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"gorm.io/gorm"
+)
+
+// promotionLine is a cart or order line as seen by the promotion rules. Discount
+// accumulates what the promotions applied so far took off the line.
+type promotionLine struct {
+	ProductID  uint
+	CategoryID uint
+	UnitPrice  float64
+	Quantity   int
+	Discount   float64
+}
+
+func (l *promotionLine) remaining() float64 {
+	return l.UnitPrice*float64(l.Quantity) - l.Discount
+}
+
+// promotionResult is a promotion that fired, with what it took off and why
+type promotionResult struct {
+	Promotion   *models.Promotion
+	Amount      float64
+	Explanation string
+}
+
+// PromotionService lets staff manage the automatic promotions
+type PromotionService struct {
+	db *gorm.DB
+}
+
+func NewPromotionService(db *gorm.DB) *PromotionService {
+	return &PromotionService{
+		db: db,
+	}
+}
+
+func (s *PromotionService) CreatePromotion(req *dto.PromotionRequest) (*dto.PromotionResponse, error) {
+	promotion := models.Promotion{Stackable: true, IsActive: true}
+	if err := applyPromotionRequest(&promotion, req); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if promotion.Products, promotion.Categories, err = findDiscountTargets(s.db, req.ProductIDs, req.CategoryIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(&promotion).Error; err != nil {
+		return nil, errors.New("failed to create promotion")
+	}
+
+	response := convertToPromotionResponse(&promotion)
+	return &response, nil
+}
+
+// ListPromotions returns promotions in the order they are evaluated
+func (s *PromotionService) ListPromotions(req *dto.ListPromotionsRequest) ([]dto.PromotionResponse, *utils.PaginationMeta, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	query := s.db.Model(&models.Promotion{})
+	if req.Type != "" {
+		query = query.Where("type = ?", req.Type)
+	}
+	if req.IsActive != nil {
+		query = query.Where("is_active = ?", *req.IsActive)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var promotions []models.Promotion
+	if err := preloadPromotion(query).
+		Order("priority DESC, id").Offset(offset).Limit(req.Limit).Find(&promotions).Error; err != nil {
+		return nil, nil, err
+	}
+
+	response := make([]dto.PromotionResponse, len(promotions))
+	for i := range promotions {
+		response[i] = convertToPromotionResponse(&promotions[i])
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	meta := &utils.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      int(total),
+		TotalPages: totalPages,
+	}
+
+	return response, meta, nil
+}
+
+func (s *PromotionService) GetPromotion(promotionID uint) (*dto.PromotionResponse, error) {
+	var promotion models.Promotion
+	if err := preloadPromotion(s.db).First(&promotion, promotionID).Error; err != nil {
+		return nil, ErrPromotionNotFound
+	}
+
+	response := convertToPromotionResponse(&promotion)
+	return &response, nil
+}
+
+// UpdatePromotion replaces a promotion. Orders keep the discount lines they were placed with.
+func (s *PromotionService) UpdatePromotion(promotionID uint, req *dto.PromotionRequest) (*dto.PromotionResponse, error) {
+	var promotion models.Promotion
+	if err := s.db.First(&promotion, promotionID).Error; err != nil {
+		return nil, ErrPromotionNotFound
+	}
+
+	if err := applyPromotionRequest(&promotion, req); err != nil {
+		return nil, err
+	}
+
+	products, categories, err := findDiscountTargets(s.db, req.ProductIDs, req.CategoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	tiers := promotion.Tiers
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Tiers", "Products", "Categories").Save(&promotion).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("promotion_id = ?", promotion.ID).Delete(&models.PromotionTier{}).Error; err != nil {
+			return err
+		}
+		for i := range tiers {
+			tiers[i].PromotionID = promotion.ID
+			if err := tx.Create(&tiers[i]).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&promotion).Association("Products").Replace(products); err != nil {
+			return err
+		}
+		return tx.Model(&promotion).Association("Categories").Replace(categories)
+	})
+	if err != nil {
+		return nil, errors.New("failed to update promotion")
+	}
+
+	return s.GetPromotion(promotion.ID)
+}
+
+func (s *PromotionService) DeletePromotion(promotionID uint) error {
+	result := s.db.Delete(&models.Promotion{}, promotionID)
+	if result.Error != nil {
+		return errors.New("failed to delete promotion")
+	}
+	if result.RowsAffected == 0 {
+		return ErrPromotionNotFound
+	}
+	return nil
+}
+
+func applyPromotionRequest(promotion *models.Promotion, req *dto.PromotionRequest) error {
+	promotionType := models.PromotionType(req.Type)
+	switch {
+	case promotionType == models.PromotionTypeBuyXGetY && (req.BuyQuantity < 1 || req.GetQuantity < 1):
+		return errors.New("a buy_x_get_y promotion needs buy_quantity and get_quantity of at least 1")
+	case promotionType == models.PromotionTypeQuantityTier && len(req.Tiers) == 0:
+		return errors.New("a quantity_tier promotion needs at least one tier")
+	case promotionType == models.PromotionTypeCategoryPercentage && (req.Percentage <= 0 || len(req.CategoryIDs) == 0):
+		return errors.New("a category_percentage promotion needs a percentage and category_ids")
+	case promotionType == models.PromotionTypeBundle && (len(uniqueIDs(req.ProductIDs)) < 2 || len(req.CategoryIDs) > 0):
+		return errors.New("a bundle needs at least two product_ids and no category_ids")
+	case promotionType == models.PromotionTypeBundle && req.BundlePrice <= 0:
+		return errors.New("a bundle needs a bundle_price above 0")
+	case req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt):
+		return errors.New("ends_at must be after starts_at")
+	}
+
+	promotion.Tiers = make([]models.PromotionTier, len(req.Tiers))
+	seen := make(map[int]bool, len(req.Tiers))
+	for i, tier := range req.Tiers {
+		if seen[tier.MinQuantity] {
+			return errors.New("tiers must have different min_quantity values")
+		}
+		seen[tier.MinQuantity] = true
+		promotion.Tiers[i] = models.PromotionTier{MinQuantity: tier.MinQuantity, Percentage: tier.Percentage}
+	}
+
+	promotion.Name = strings.TrimSpace(req.Name)
+	promotion.Description = strings.TrimSpace(req.Description)
+	promotion.Type = promotionType
+	promotion.Priority = req.Priority
+	promotion.StartsAt = req.StartsAt
+	promotion.EndsAt = req.EndsAt
+	promotion.BuyQuantity = req.BuyQuantity
+	promotion.GetQuantity = req.GetQuantity
+	promotion.Percentage = req.Percentage
+	promotion.BundlePrice = req.BundlePrice
+	if req.Stackable != nil {
+		promotion.Stackable = *req.Stackable
+	}
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+	// The reward of buy_x_get_y defaults to free items
+	if promotion.Type == models.PromotionTypeBuyXGetY && promotion.Percentage == 0 {
+		promotion.Percentage = 100
+	}
+	return nil
+}
+
+func preloadPromotion(query *gorm.DB) *gorm.DB {
+	return query.Preload("Tiers", func(db *gorm.DB) *gorm.DB {
+		return db.Order("min_quantity")
+	}).Preload("Products").Preload("Categories")
+}
+
+// loadActivePromotions returns the promotions running at the given time, in evaluation order
+func loadActivePromotions(db *gorm.DB, now time.Time) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := preloadPromotion(db).
+		Where("is_active = ? AND (starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?)", true, now, now).
+		Order("priority DESC, id").Find(&promotions).Error
+	return promotions, err
+}
+
+// applyPromotions runs the promotions over the lines in order and records on each line
+// what was taken off it. Every promotion works on what earlier ones left of a line, so
+// stacked promotions never discount a line below zero.
+func applyPromotions(promotions []models.Promotion, lines []promotionLine) []promotionResult {
+	var results []promotionResult
+	for i := range promotions {
+		promotion := &promotions[i]
+		if !promotion.Stackable && len(results) > 0 {
+			continue
+		}
+
+		discounts, explanation := evaluatePromotion(promotion, lines)
+		var amount float64
+		for j, discount := range discounts {
+			discount = roundMoney(min(discount, lines[j].remaining()))
+			if discount <= 0 {
+				continue
+			}
+			lines[j].Discount += discount
+			amount += discount
+		}
+		if amount <= 0 {
+			continue
+		}
+
+		results = append(results, promotionResult{
+			Promotion:   promotion,
+			Amount:      roundMoney(amount),
+			Explanation: explanation,
+		})
+		if !promotion.Stackable {
+			break
+		}
+	}
+	return results
+}
+
+// evaluatePromotion works out what a promotion takes off each line, or nothing when
+// the cart does not qualify
+func evaluatePromotion(promotion *models.Promotion, lines []promotionLine) ([]float64, string) {
+	discounts := make([]float64, len(lines))
+
+	var eligible []int
+	units := 0
+	for i := range lines {
+		if promotionCovers(promotion, &lines[i]) {
+			eligible = append(eligible, i)
+			units += lines[i].Quantity
+		}
+	}
+	if len(eligible) == 0 {
+		return nil, ""
+	}
+
+	switch promotion.Type {
+	case models.PromotionTypeBuyXGetY:
+		if promotion.BuyQuantity < 1 || promotion.GetQuantity < 1 {
+			return nil, ""
+		}
+		rewarded := units / (promotion.BuyQuantity + promotion.GetQuantity) * promotion.GetQuantity
+		if rewarded == 0 {
+			return nil, ""
+		}
+
+		// The cheapest eligible items are the ones discounted
+		sort.SliceStable(eligible, func(a, b int) bool {
+			return lines[eligible[a]].UnitPrice < lines[eligible[b]].UnitPrice
+		})
+		left := rewarded
+		for _, i := range eligible {
+			count := min(left, lines[i].Quantity)
+			discounts[i] = float64(count) * lines[i].UnitPrice * promotion.Percentage / 100
+			if left -= count; left == 0 {
+				break
+			}
+		}
+
+		if promotion.Percentage >= 100 {
+			return discounts, fmt.Sprintf("Buy %d get %d free: %d free item(s)",
+				promotion.BuyQuantity, promotion.GetQuantity, rewarded)
+		}
+		return discounts, fmt.Sprintf("Buy %d get %d at %s%% off: %d discounted item(s)",
+			promotion.BuyQuantity, promotion.GetQuantity, formatPercentage(promotion.Percentage), rewarded)
+
+	case models.PromotionTypeQuantityTier:
+		// Tiers are loaded in ascending order, the last one reached wins
+		var tier *models.PromotionTier
+		for i := range promotion.Tiers {
+			if units >= promotion.Tiers[i].MinQuantity {
+				tier = &promotion.Tiers[i]
+			}
+		}
+		if tier == nil {
+			return nil, ""
+		}
+
+		for _, i := range eligible {
+			discounts[i] = lines[i].remaining() * tier.Percentage / 100
+		}
+		return discounts, fmt.Sprintf("%s%% off for buying %d or more", formatPercentage(tier.Percentage), tier.MinQuantity)
+
+	case models.PromotionTypeCategoryPercentage:
+		for _, i := range eligible {
+			discounts[i] = lines[i].remaining() * promotion.Percentage / 100
+		}
+		return discounts, fmt.Sprintf("%s%% off selected categories", formatPercentage(promotion.Percentage))
+
+	case models.PromotionTypeBundle:
+		return bundleDiscounts(promotion, lines, discounts)
+	}
+	return nil, ""
+}
+
+// bundleDiscounts prices every complete set of the bundle's products at the bundle price.
+// The saving is spread over the products in proportion to their price.
+func bundleDiscounts(promotion *models.Promotion, lines []promotionLine, discounts []float64) ([]float64, string) {
+	if len(promotion.Products) < 2 {
+		return nil, ""
+	}
+
+	lineByProduct := make(map[uint]int, len(lines))
+	for i := range lines {
+		lineByProduct[lines[i].ProductID] = i
+	}
+
+	sets := -1
+	var regular float64
+	for i := range promotion.Products {
+		line, ok := lineByProduct[promotion.Products[i].ID]
+		if !ok {
+			return nil, ""
+		}
+		if sets < 0 || lines[line].Quantity < sets {
+			sets = lines[line].Quantity
+		}
+		regular += lines[line].UnitPrice
+	}
+
+	saving := regular - promotion.BundlePrice
+	if sets < 1 || saving <= 0 {
+		return nil, ""
+	}
+
+	for i := range promotion.Products {
+		line := lineByProduct[promotion.Products[i].ID]
+		discounts[line] = saving * float64(sets) * lines[line].UnitPrice / regular
+	}
+	return discounts, fmt.Sprintf("Bundle of %d products for %.2f, applied %d time(s)",
+		len(promotion.Products), promotion.BundlePrice, sets)
+}
+
+// promotionCovers reports whether a line is eligible. Promotions without products or
+// categories cover every line.
+func promotionCovers(promotion *models.Promotion, line *promotionLine) bool {
+	if len(promotion.Products) == 0 && len(promotion.Categories) == 0 {
+		return true
+	}
+	return targetsCover(promotion.Products, promotion.Categories, line.ProductID, line.CategoryID)
+}
+
+// couponLines hands the lines to the coupon rules, which work on what the promotions left
+func couponLines(lines []promotionLine) []couponLine {
+	result := make([]couponLine, len(lines))
+	for i := range lines {
+		result[i] = couponLine{
+			ProductID:  lines[i].ProductID,
+			CategoryID: lines[i].CategoryID,
+			Amount:     roundMoney(lines[i].remaining()),
+		}
+	}
+	return result
+}
+
+func formatPercentage(percentage float64) string {
+	return strconv.FormatFloat(percentage, 'f', -1, 64)
+}
+
+func convertToAppliedPromotionResponse(result *promotionResult) dto.AppliedPromotionResponse {
+	return dto.AppliedPromotionResponse{
+		ID:          result.Promotion.ID,
+		Name:        result.Promotion.Name,
+		Type:        string(result.Promotion.Type),
+		Amount:      result.Amount,
+		Explanation: result.Explanation,
+	}
+}
+
+func convertToPromotionResponse(promotion *models.Promotion) dto.PromotionResponse {
+	response := dto.PromotionResponse{
+		ID:          promotion.ID,
+		Name:        promotion.Name,
+		Description: promotion.Description,
+		Type:        string(promotion.Type),
+		Priority:    promotion.Priority,
+		Stackable:   promotion.Stackable,
+		IsActive:    promotion.IsActive,
+		StartsAt:    promotion.StartsAt,
+		EndsAt:      promotion.EndsAt,
+		BuyQuantity: promotion.BuyQuantity,
+		GetQuantity: promotion.GetQuantity,
+		Percentage:  promotion.Percentage,
+		BundlePrice: promotion.BundlePrice,
+		Tiers:       make([]dto.PromotionTierResponse, len(promotion.Tiers)),
+		ProductIDs:  make([]uint, len(promotion.Products)),
+		CategoryIDs: make([]uint, len(promotion.Categories)),
+		CreatedAt:   promotion.CreatedAt,
+		UpdatedAt:   promotion.UpdatedAt,
+	}
+	for i := range promotion.Tiers {
+		response.Tiers[i] = dto.PromotionTierResponse{
+			MinQuantity: promotion.Tiers[i].MinQuantity,
+			Percentage:  promotion.Tiers[i].Percentage,
+		}
+	}
+	for i := range promotion.Products {
+		response.ProductIDs[i] = promotion.Products[i].ID
+	}
+	for i := range promotion.Categories {
+		response.CategoryIDs[i] = promotion.Categories[i].ID
+	}
+	return response
+}
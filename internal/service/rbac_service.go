@@ -0,0 +1,211 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"gorm.io/gorm"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// RBACService manages roles, their permissions and which role each user has
+type RBACService struct {
+	db *gorm.DB
+}
+
+func NewRBACService(db *gorm.DB) *RBACService {
+	return &RBACService{
+		db: db,
+	}
+}
+
+func (s *RBACService) ListRoles() ([]dto.RoleResponse, error) {
+	var roles []models.Role
+	if err := s.db.Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.RoleResponse, len(roles))
+	for i := range roles {
+		response[i] = convertToRoleResponse(&roles[i])
+	}
+	return response, nil
+}
+
+func (s *RBACService) ListPermissions() ([]dto.PermissionResponse, error) {
+	var permissions []models.Permission
+	if err := s.db.Order("name").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.PermissionResponse, len(permissions))
+	for i := range permissions {
+		response[i] = dto.PermissionResponse{
+			ID:          permissions[i].ID,
+			Name:        permissions[i].Name,
+			Description: permissions[i].Description,
+		}
+	}
+	return response, nil
+}
+
+func (s *RBACService) CreateRole(req *dto.CreateRoleRequest) (*dto.RoleResponse, error) {
+	if !roleNamePattern.MatchString(req.Name) {
+		return nil, errors.New("role name must be lower-case letters, digits and underscores")
+	}
+
+	permissions, err := s.findPermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: permissions,
+	}
+	if err := s.db.Create(role).Error; err != nil {
+		return nil, errors.New("failed to create role, the name may already be taken")
+	}
+
+	response := convertToRoleResponse(role)
+	return &response, nil
+}
+
+// UpdateRolePermissions replaces the permission set of a role. Users holding the
+// role pick up the change the next time their tokens are issued.
+func (s *RBACService) UpdateRolePermissions(roleID uint, req *dto.UpdateRolePermissionsRequest) (*dto.RoleResponse, error) {
+	var role models.Role
+	if err := s.db.First(&role, roleID).Error; err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	// Admins must always be able to manage roles, otherwise nobody could undo a mistake
+	if role.Name == string(models.UserRoleAdmin) {
+		return nil, errors.New("the admin role always has every permission")
+	}
+
+	permissions, err := s.findPermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(&role).Association("Permissions").Replace(permissions); err != nil {
+		return nil, errors.New("failed to update role permissions")
+	}
+
+	role.Permissions = permissions
+	response := convertToRoleResponse(&role)
+	return &response, nil
+}
+
+func (s *RBACService) DeleteRole(roleID uint) error {
+	var role models.Role
+	if err := s.db.First(&role, roleID).Error; err != nil {
+		return ErrRoleNotFound
+	}
+	if role.IsSystem {
+		return errors.New("built-in roles cannot be deleted")
+	}
+
+	var assigned int64
+	s.db.Unscoped().Model(&models.User{}).Where("role = ?", role.Name).Count(&assigned)
+	if assigned > 0 {
+		return errors.New("role is still assigned to users")
+	}
+
+	return s.db.Select("Permissions").Delete(&role).Error
+}
+
+// AssignRole changes the role of a user and signs them out everywhere so the
+// new permissions take effect immediately
+func (s *RBACService) AssignRole(actorID, userID uint, req *dto.AssignRoleRequest) (*dto.UserResponse, error) {
+	if actorID == userID {
+		return nil, errors.New("you cannot change your own role")
+	}
+
+	var role models.Role
+	if err := s.db.Where("name = ?", req.Role).First(&role).Error; err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("role", role.Name).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? OR impersonator_id = ?", user.ID, user.ID).Delete(&models.RefreshToken{}).Error
+	})
+	if err != nil {
+		return nil, errors.New("failed to assign role")
+	}
+
+	return &dto.UserResponse{
+		ID:        user.ID,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Phone:     user.Phone,
+		Role:      string(user.Role),
+		IsActive:  user.IsActive,
+	}, nil
+}
+
+func (s *RBACService) findPermissions(names []string) ([]models.Permission, error) {
+	permissions := []models.Permission{}
+	if len(names) == 0 {
+		return permissions, nil
+	}
+
+	if err := s.db.Where("name IN ?", names).Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(permissions))
+	for i := range permissions {
+		found[permissions[i].Name] = true
+	}
+	for _, name := range names {
+		if !found[name] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, name)
+		}
+	}
+	return permissions, nil
+}
+
+// permissionsForRole returns the permission names granted by a role
+func permissionsForRole(db *gorm.DB, role string) ([]string, error) {
+	var names []string
+	err := db.Model(&models.Permission{}).
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.name = ?", role).
+		Order("permissions.name").
+		Pluck("permissions.name", &names).Error
+	return names, err
+}
+
+func convertToRoleResponse(role *models.Role) dto.RoleResponse {
+	permissions := make([]string, len(role.Permissions))
+	for i := range role.Permissions {
+		permissions[i] = role.Permissions[i].Name
+	}
+
+	return dto.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		IsSystem:    role.IsSystem,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
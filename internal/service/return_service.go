@@ -0,0 +1,519 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// returnableStatuses are the order statuses from which shipped items can be sent back
+var returnableStatuses = []models.OrderStatus{
+	models.OrderStatusPartiallyShipped,
+	models.OrderStatusShipped,
+	models.OrderStatusDelivered,
+}
+
+// ReturnService runs the returns (RMA) workflow: customers request returns of shipped
+// items, staff approve or reject them, receive the goods and refund them
+type ReturnService struct {
+	db             *gorm.DB
+	notifications  *NotificationService
+	invoiceService *InvoiceService
+}
+
+func NewReturnService(db *gorm.DB, notifications *NotificationService, invoiceService *InvoiceService) *ReturnService {
+	return &ReturnService{
+		db:             db,
+		notifications:  notifications,
+		invoiceService: invoiceService,
+	}
+}
+
+// CreateReturn asks to return shipped items of one of the user's orders. Units already
+// in another open or completed return cannot be requested again.
+func (s *ReturnService) CreateReturn(userID, orderID uint, req *dto.CreateReturnRequest) (*dto.ReturnResponse, error) {
+	returnRequest := models.ReturnRequest{
+		OrderID:      orderID,
+		UserID:       &userID,
+		Status:       models.ReturnStatusRequested,
+		CustomerNote: req.Note,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the order so two requests cannot claim the same units
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", orderID, userID).First(&order).Error; err != nil {
+			return ErrOrderNotFound
+		}
+		if !orderReturnable(order.Status) {
+			return ErrOrderNotReturnable
+		}
+
+		shipped, err := shippedQuantities(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		returned, err := returnedQuantities(tx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		var orderItems []models.OrderItem
+		if err := tx.Where("order_id = ?", order.ID).Find(&orderItems).Error; err != nil {
+			return err
+		}
+		available := make(map[uint]int, len(orderItems))
+		for _, item := range orderItems {
+			available[item.ID] = shipped[item.ID] - returned[item.ID]
+		}
+
+		for _, itemReq := range req.Items {
+			left, ok := available[itemReq.OrderItemID]
+			if !ok {
+				return fmt.Errorf("%w: item %d is not part of the order", ErrInvalidReturnItem, itemReq.OrderItemID)
+			}
+			if itemReq.Quantity > left {
+				return fmt.Errorf("%w: only %d of item %d can be returned", ErrInvalidReturnItem, left, itemReq.OrderItemID)
+			}
+			available[itemReq.OrderItemID] -= itemReq.Quantity
+
+			returnRequest.Items = append(returnRequest.Items, models.ReturnItem{
+				OrderItemID: itemReq.OrderItemID,
+				Quantity:    itemReq.Quantity,
+				Reason:      models.ReturnReason(itemReq.Reason),
+				Note:        itemReq.Note,
+			})
+		}
+
+		returnRequest.Events = []models.ReturnEvent{{Status: models.ReturnStatusRequested, Note: req.Note}}
+		if err := tx.Create(&returnRequest).Error; err != nil {
+			return errors.New("failed to create return")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getReturn(s.db.Where("id = ?", returnRequest.ID))
+}
+
+// ListUserReturns returns a user's returns, newest first
+func (s *ReturnService) ListUserReturns(userID uint, page, limit int) ([]dto.ReturnResponse, *utils.PaginationMeta, error) {
+	return s.listReturns(s.db.Where("user_id = ?", userID), page, limit)
+}
+
+func (s *ReturnService) GetUserReturn(userID, returnID uint) (*dto.ReturnResponse, error) {
+	return s.getReturn(s.db.Where("id = ? AND user_id = ?", returnID, userID))
+}
+
+// ListReturns returns every return, optionally in one status, newest first
+func (s *ReturnService) ListReturns(req *dto.ListReturnsRequest) ([]dto.ReturnResponse, *utils.PaginationMeta, error) {
+	query := s.db
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	return s.listReturns(query, req.Page, req.Limit)
+}
+
+func (s *ReturnService) GetReturn(returnID uint) (*dto.ReturnResponse, error) {
+	return s.getReturn(s.db.Where("id = ?", returnID))
+}
+
+// ApproveReturn lets the customer send the goods back
+func (s *ReturnService) ApproveReturn(returnID, actorID uint, note string) (*dto.ReturnResponse, error) {
+	response, err := s.transition(returnID, actorID, note, models.ReturnStatusApproved, nil,
+		models.ReturnStatusRequested)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyReviewed(models.NotificationReturnApproved, response, note)
+	return response, nil
+}
+
+// RejectReturn closes a return without a refund
+func (s *ReturnService) RejectReturn(returnID, actorID uint, note string) (*dto.ReturnResponse, error) {
+	response, err := s.transition(returnID, actorID, note, models.ReturnStatusRejected, nil,
+		models.ReturnStatusRequested, models.ReturnStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyReviewed(models.NotificationReturnRejected, response, note)
+	return response, nil
+}
+
+// ReceiveReturn records that the goods arrived back and optionally puts them back in stock
+func (s *ReturnService) ReceiveReturn(returnID, actorID uint, req *dto.ReceiveReturnRequest) (*dto.ReturnResponse, error) {
+	return s.transition(returnID, actorID, req.Note, models.ReturnStatusReceived,
+		func(tx *gorm.DB, returnRequest *models.ReturnRequest) error {
+			if !req.Restock {
+				return nil
+			}
+			for _, item := range returnRequest.Items {
+				if err := adjustStock(tx, &models.StockMovement{
+					ProductID:       item.OrderItem.ProductID,
+					Quantity:        item.Quantity,
+					Reason:          models.StockMovementReturn,
+					OrderID:         &returnRequest.OrderID,
+					ReturnRequestID: &returnRequest.ID,
+					ActorID:         optionalID(actorID),
+				}); err != nil {
+					return err
+				}
+			}
+			returnRequest.Restocked = true
+			return tx.Model(returnRequest).Update("restocked", true).Error
+		}, models.ReturnStatusApproved)
+}
+
+// RefundReturn pays back the received items. Each unit is refunded at its price less its
+// share of the order's discounts, plus the tax charged on it. The order becomes refunded
+// once every item has been refunded, otherwise partially refunded.
+func (s *ReturnService) RefundReturn(returnID, actorID uint, note string) (*dto.ReturnResponse, error) {
+	response, err := s.transition(returnID, actorID, note, models.ReturnStatusRefunded,
+		func(tx *gorm.DB, returnRequest *models.ReturnRequest) error {
+			var order models.Order
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, returnRequest.OrderID).Error; err != nil {
+				return ErrOrderNotFound
+			}
+			if err := tx.Where("order_id = ?", order.ID).Find(&order.OrderItems).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("order_id = ?", order.ID).Find(&order.Discounts).Error; err != nil {
+				return err
+			}
+
+			amount := returnRefund(&order, returnRequest.Items)
+			amount = roundMoney(min(amount, order.TotalAmount-order.RefundedTotal))
+			now := time.Now()
+			returnRequest.RefundAmount = amount
+			returnRequest.RefundedAt = &now
+			if err := tx.Model(returnRequest).Updates(map[string]interface{}{
+				"refund_amount": amount,
+				"refunded_at":   now,
+			}).Error; err != nil {
+				return err
+			}
+
+			// Count this return's units as refunded along with the earlier ones
+			refunded, err := refundedQuantities(tx, order.ID)
+			if err != nil {
+				return err
+			}
+			for _, item := range returnRequest.Items {
+				refunded[item.OrderItemID] += item.Quantity
+			}
+			// A partial refund is recorded in refunded_total only, so the order keeps its
+			// fulfilment status and the units still to ship can ship
+			updates := map[string]interface{}{
+				"refunded_total": roundMoney(order.RefundedTotal + amount),
+			}
+			fullyRefunded := true
+			for _, item := range order.OrderItems {
+				if refunded[item.ID] < item.Quantity {
+					fullyRefunded = false
+					break
+				}
+			}
+			if fullyRefunded {
+				updates["status"] = models.OrderStatusRefunded
+			}
+			return tx.Model(&order).Updates(updates).Error
+		}, models.ReturnStatusReceived)
+	if err != nil {
+		return nil, err
+	}
+
+	// A credit note that cannot be issued now is issued with the order's next invoice request
+	_ = s.invoiceService.IssueCreditNote(response.ID)
+
+	s.notifyRefunded(response, note)
+	return response, nil
+}
+
+// transition moves a return from one of the allowed statuses to the next, runs the work
+// that goes with it and appends the change to the audit trail, all in one transaction
+func (s *ReturnService) transition(returnID, actorID uint, note string, to models.ReturnStatus,
+	apply func(tx *gorm.DB, returnRequest *models.ReturnRequest) error, from ...models.ReturnStatus) (*dto.ReturnResponse, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var returnRequest models.ReturnRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&returnRequest, returnID).Error; err != nil {
+			return ErrReturnNotFound
+		}
+		if err := tx.Preload("OrderItem").Where("return_request_id = ?", returnRequest.ID).
+			Find(&returnRequest.Items).Error; err != nil {
+			return err
+		}
+
+		allowed := false
+		for _, status := range from {
+			if returnRequest.Status == status {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: return is %s", ErrInvalidReturnTransition, returnRequest.Status)
+		}
+
+		if apply != nil {
+			if err := apply(tx, &returnRequest); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&returnRequest).Update("status", to).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ReturnEvent{
+			ReturnRequestID: returnRequest.ID,
+			Status:          to,
+			ActorID:         optionalID(actorID),
+			Note:            note,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getReturn(s.db.Where("id = ?", returnID))
+}
+
+func (s *ReturnService) listReturns(query *gorm.DB, page, limit int) ([]dto.ReturnResponse, *utils.PaginationMeta, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	var total int64
+	if err := query.Model(&models.ReturnRequest{}).Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var returns []models.ReturnRequest
+	if err := preloadReturn(query).Order("created_at DESC").
+		Offset(offset).Limit(limit).Find(&returns).Error; err != nil {
+		return nil, nil, err
+	}
+
+	response := make([]dto.ReturnResponse, len(returns))
+	for i := range returns {
+		response[i] = convertToReturnResponse(&returns[i])
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	meta := &utils.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		Total:      int(total),
+		TotalPages: totalPages,
+	}
+
+	return response, meta, nil
+}
+
+func (s *ReturnService) getReturn(query *gorm.DB) (*dto.ReturnResponse, error) {
+	var returnRequest models.ReturnRequest
+	if err := preloadReturn(query).First(&returnRequest).Error; err != nil {
+		return nil, ErrReturnNotFound
+	}
+
+	response := convertToReturnResponse(&returnRequest)
+	return &response, nil
+}
+
+func (s *ReturnService) notifyReviewed(notificationType models.NotificationType, returnResponse *dto.ReturnResponse, note string) {
+	var order models.Order
+	if err := s.db.First(&order, returnResponse.OrderID).Error; err != nil {
+		return
+	}
+
+	_ = s.notifications.Notify(notificationType, order.UserID, order.Email, returnReviewedEmail{
+		Name:        recipientName(s.db, &order),
+		OrderNumber: order.OrderNumber,
+		Note:        note,
+	})
+}
+
+func (s *ReturnService) notifyRefunded(returnResponse *dto.ReturnResponse, note string) {
+	var order models.Order
+	if err := s.db.First(&order, returnResponse.OrderID).Error; err != nil {
+		return
+	}
+
+	data := refundIssuedEmail{
+		Name:        recipientName(s.db, &order),
+		OrderNumber: order.OrderNumber,
+		Amount:      returnResponse.RefundAmount,
+		Note:        note,
+	}
+	for _, item := range returnResponse.Items {
+		data.Items = append(data.Items, notificationItem{Name: item.ProductName, Quantity: item.Quantity})
+	}
+
+	_ = s.notifications.Notify(models.NotificationRefundIssued, order.UserID, order.Email, data)
+}
+
+func preloadReturn(query *gorm.DB) *gorm.DB {
+	return query.Preload("Order.OrderItems").Preload("Order.Discounts").
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).Preload("Items.OrderItem.Product").
+		Preload("Events", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at, id")
+		})
+}
+
+// returnedQuantities sums the units of each item of an order claimed by returns that
+// were not rejected
+func returnedQuantities(db *gorm.DB, orderID uint) (map[uint]int, error) {
+	return returnItemQuantities(db.Where("return_requests.status <> ?", models.ReturnStatusRejected), orderID)
+}
+
+// refundedQuantities sums the units of each item of an order already refunded
+func refundedQuantities(db *gorm.DB, orderID uint) (map[uint]int, error) {
+	return returnItemQuantities(db.Where("return_requests.status = ?", models.ReturnStatusRefunded), orderID)
+}
+
+func returnItemQuantities(query *gorm.DB, orderID uint) (map[uint]int, error) {
+	var rows []struct {
+		OrderItemID uint
+		Quantity    int
+	}
+	if err := query.Model(&models.ReturnItem{}).
+		Select("return_items.order_item_id, SUM(return_items.quantity) AS quantity").
+		Joins("JOIN return_requests ON return_requests.id = return_items.return_request_id").
+		Where("return_requests.order_id = ?", orderID).
+		Group("return_items.order_item_id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	quantities := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		quantities[row.OrderItemID] = row.Quantity
+	}
+	return quantities, nil
+}
+
+// unitRefunds works out what one unit of each order item is refunded at: its price less
+// its share of the item discounts, spread over the items in proportion to their value,
+// plus the tax charged on it when tax was added on top of the price. Waived shipping is
+// not an item discount and shipping itself is not refunded.
+func unitRefunds(order *models.Order) map[uint]float64 {
+	itemDiscount := order.DiscountTotal
+	for _, discount := range order.Discounts {
+		if discount.Type == string(models.CouponTypeFreeShipping) {
+			itemDiscount -= discount.Amount
+		}
+	}
+
+	var gross float64
+	for _, item := range order.OrderItems {
+		gross += item.Price * float64(item.Quantity)
+	}
+
+	refunds := make(map[uint]float64, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		if item.Quantity == 0 {
+			continue
+		}
+		lineGross := item.Price * float64(item.Quantity)
+		net := lineGross
+		if gross > 0 {
+			net -= itemDiscount * lineGross / gross
+		}
+		if !order.PricesIncludeTax {
+			net += item.TaxAmount
+		}
+		refunds[item.ID] = net / float64(item.Quantity)
+	}
+	return refunds
+}
+
+// returnRefund is the refund due for the returned items of an order
+func returnRefund(order *models.Order, items []models.ReturnItem) float64 {
+	refunds := unitRefunds(order)
+	var amount float64
+	for _, item := range items {
+		amount += refunds[item.OrderItemID] * float64(item.Quantity)
+	}
+	return roundMoney(amount)
+}
+
+func orderReturnable(status models.OrderStatus) bool {
+	for _, returnable := range returnableStatuses {
+		if status == returnable {
+			return true
+		}
+	}
+	return false
+}
+
+// optionalID stores a zero ID, meaning nobody, as NULL
+func optionalID(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+func convertToReturnResponse(returnRequest *models.ReturnRequest) dto.ReturnResponse {
+	refunds := unitRefunds(&returnRequest.Order)
+
+	items := make([]dto.ReturnItemResponse, len(returnRequest.Items))
+	for i := range returnRequest.Items {
+		item := &returnRequest.Items[i]
+		items[i] = dto.ReturnItemResponse{
+			OrderItemID: item.OrderItemID,
+			ProductID:   item.OrderItem.ProductID,
+			ProductName: item.OrderItem.Product.Name,
+			Quantity:    item.Quantity,
+			Reason:      string(item.Reason),
+			Note:        item.Note,
+			UnitRefund:  roundMoney(refunds[item.OrderItemID]),
+		}
+	}
+
+	events := make([]dto.ReturnEventResponse, len(returnRequest.Events))
+	for i := range returnRequest.Events {
+		events[i] = dto.ReturnEventResponse{
+			Status:    string(returnRequest.Events[i].Status),
+			ActorID:   returnRequest.Events[i].ActorID,
+			Note:      returnRequest.Events[i].Note,
+			CreatedAt: returnRequest.Events[i].CreatedAt,
+		}
+	}
+
+	refundAmount := returnRequest.RefundAmount
+	if returnRequest.Status != models.ReturnStatusRefunded {
+		refundAmount = returnRefund(&returnRequest.Order, returnRequest.Items)
+	}
+
+	return dto.ReturnResponse{
+		ID:           returnRequest.ID,
+		OrderID:      returnRequest.OrderID,
+		OrderNumber:  returnRequest.Order.OrderNumber,
+		Status:       string(returnRequest.Status),
+		CustomerNote: returnRequest.CustomerNote,
+		Restocked:    returnRequest.Restocked,
+		RefundAmount: refundAmount,
+		RefundedAt:   returnRequest.RefundedAt,
+		Items:        items,
+		Events:       events,
+		CreatedAt:    returnRequest.CreatedAt,
+		UpdatedAt:    returnRequest.UpdatedAt,
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/programmerjide/ecommerce/internal/models"
+)
+
+func TestUnitRefunds(t *testing.T) {
+	// Two units at 10 and one at 30: a line worth 20 and one worth 30
+	items := []models.OrderItem{
+		{ID: 1, Quantity: 2, Price: 10, TaxAmount: 4},
+		{ID: 2, Quantity: 1, Price: 30, TaxAmount: 6},
+	}
+
+	tests := []struct {
+		name  string
+		order models.Order
+		want  map[uint]float64
+	}{
+		{
+			name:  "tax added on top is refunded",
+			order: models.Order{OrderItems: items},
+			want:  map[uint]float64{1: 12, 2: 36},
+		},
+		{
+			name:  "tax contained in the price is not added again",
+			order: models.Order{OrderItems: items, PricesIncludeTax: true},
+			want:  map[uint]float64{1: 10, 2: 30},
+		},
+		{
+			name: "discounts are shared in proportion to line value",
+			order: models.Order{
+				OrderItems:       items,
+				PricesIncludeTax: true,
+				DiscountTotal:    10,
+				Discounts:        []models.OrderDiscount{{Type: string(models.CouponTypePercentage), Amount: 10}},
+			},
+			want: map[uint]float64{1: 8, 2: 24},
+		},
+		{
+			name: "waived shipping is not an item discount",
+			order: models.Order{
+				OrderItems:       items,
+				PricesIncludeTax: true,
+				DiscountTotal:    15,
+				Discounts: []models.OrderDiscount{
+					{Type: string(models.CouponTypePercentage), Amount: 10},
+					{Type: string(models.CouponTypeFreeShipping), Amount: 5},
+				},
+			},
+			want: map[uint]float64{1: 8, 2: 24},
+		},
+		{
+			name: "discounted lines still get their tax back",
+			order: models.Order{
+				OrderItems:    []models.OrderItem{{ID: 1, Quantity: 2, Price: 10, TaxAmount: 3.2}},
+				DiscountTotal: 4,
+				Discounts:     []models.OrderDiscount{{Type: string(models.CouponTypeFixed), Amount: 4}},
+			},
+			want: map[uint]float64{1: 9.6},
+		},
+		{
+			name: "lines without units are skipped",
+			order: models.Order{
+				OrderItems: []models.OrderItem{{ID: 1, Quantity: 0, Price: 10}},
+			},
+			want: map[uint]float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unitRefunds(&tt.order)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got refunds for %d items, want %d", len(got), len(tt.want))
+			}
+			for id, want := range tt.want {
+				if !moneyEqual(got[id], want) {
+					t.Errorf("item %d refunds %v per unit, want %v", id, got[id], want)
+				}
+			}
+		})
+	}
+}
+
+func TestReturnRefund(t *testing.T) {
+	order := models.Order{
+		OrderItems: []models.OrderItem{
+			{ID: 1, Quantity: 3, Price: 10},
+			{ID: 2, Quantity: 1, Price: 30},
+		},
+		PricesIncludeTax: true,
+		DiscountTotal:    10,
+		Discounts:        []models.OrderDiscount{{Type: string(models.CouponTypeFixed), Amount: 10}},
+	}
+
+	tests := []struct {
+		name  string
+		items []models.ReturnItem
+		want  float64
+	}{
+		{"one unit is rounded to the cent", []models.ReturnItem{{OrderItemID: 1, Quantity: 1}}, 8.33},
+		{"several lines add up", []models.ReturnItem{{OrderItemID: 1, Quantity: 3}, {OrderItemID: 2, Quantity: 1}}, 50},
+		{"unknown items refund nothing", []models.ReturnItem{{OrderItemID: 9, Quantity: 1}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := returnRefund(&order, tt.items); !moneyEqual(got, tt.want) {
+				t.Errorf("refund = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
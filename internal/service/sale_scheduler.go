@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// saleWindowSQL matches products whose sale window is open at @now
+const saleWindowSQL = "sale_price IS NOT NULL AND (sale_starts_at IS NULL OR sale_starts_at <= @now) AND (sale_ends_at IS NULL OR sale_ends_at > @now)"
+
+// SaleScheduler keeps the denormalized Product.IsOnSale in step with each product's sale
+// window and writes the price history when a sale starts or ends. Prices themselves
+// follow the window directly, so a late refresh never charges the wrong price.
+type SaleScheduler struct {
+	db     *gorm.DB
+	logger zerolog.Logger
+}
+
+func NewSaleScheduler(db *gorm.DB, logger zerolog.Logger) *SaleScheduler {
+	return &SaleScheduler{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Run refreshes the sales right away and then every interval until the context is cancelled
+func (s *SaleScheduler) Run(ctx context.Context, interval time.Duration) {
+	s.refresh(time.Now())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.refresh(now)
+		}
+	}
+}
+
+// RefreshSales puts products whose sale window has opened on sale and takes those whose
+// window has closed off sale, writing each change to the price history
+func (s *SaleScheduler) RefreshSales(now time.Time) (started, ended int64, err error) {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if started, err = switchSales(tx, "NOT is_on_sale AND "+saleWindowSQL, true, now); err != nil {
+			return err
+		}
+		ended, err = switchSales(tx, "is_on_sale AND NOT ("+saleWindowSQL+")", false, now)
+		return err
+	})
+	return started, ended, err
+}
+
+// switchSales puts the products matching the condition on or off sale
+func switchSales(tx *gorm.DB, condition string, onSale bool, now time.Time) (int64, error) {
+	var products []models.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(condition, map[string]interface{}{"now": now}).Find(&products).Error; err != nil {
+		return 0, err
+	}
+	if len(products) == 0 {
+		return 0, nil
+	}
+
+	productIDs := make([]uint, len(products))
+	for i := range products {
+		productIDs[i] = products[i].ID
+	}
+	if err := tx.Model(&models.Product{}).Where("id IN ?", productIDs).Update("is_on_sale", onSale).Error; err != nil {
+		return 0, err
+	}
+
+	reason := models.PriceChangeSaleEnded
+	if onSale {
+		reason = models.PriceChangeSaleStarted
+	}
+	for i := range products {
+		products[i].IsOnSale = onSale
+		if err := recordPriceChange(tx, &products[i], reason, nil); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(products)), nil
+}
+
+func (s *SaleScheduler) refresh(now time.Time) {
+	started, ended, err := s.RefreshSales(now)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to refresh scheduled sales")
+		return
+	}
+	if started > 0 || ended > 0 {
+		s.logger.Info().Int64("started", started).Int64("ended", ended).Msg("Scheduled sales refreshed")
+	}
+}
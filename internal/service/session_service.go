@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"gorm.io/gorm"
+)
+
+type SessionService struct {
+	db *gorm.DB
+}
+
+func NewSessionService(db *gorm.DB) *SessionService {
+	return &SessionService{
+		db: db,
+	}
+}
+
+// ListSessions returns the active sessions of a user, flagging the one the request came from
+func (s *SessionService) ListSessions(userID, currentSessionID uint) ([]dto.SessionResponse, error) {
+	var sessions []models.RefreshToken
+	if err := s.db.Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.SessionResponse, len(sessions))
+	for i := range sessions {
+		response[i] = dto.SessionResponse{
+			ID:           sessions[i].ID,
+			UserAgent:    sessions[i].UserAgent,
+			IPAddress:    sessions[i].IPAddress,
+			Current:      sessions[i].ID == currentSessionID,
+			Impersonated: sessions[i].ImpersonatorID != nil,
+			CreatedAt:    sessions[i].CreatedAt,
+			LastUsedAt:   sessions[i].LastUsedAt,
+			ExpiresAt:    sessions[i].ExpiresAt,
+		}
+	}
+	return response, nil
+}
+
+// RevokeSession signs a single device out
+func (s *SessionService) RevokeSession(userID, sessionID uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", sessionID, userID).Delete(&models.RefreshToken{})
+	if result.Error != nil {
+		return errors.New("failed to revoke session")
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeOtherSessions signs the user out everywhere except the current session
+func (s *SessionService) RevokeOtherSessions(userID, currentSessionID uint) (int64, error) {
+	result := s.db.Where("user_id = ? AND id <> ?", userID, currentSessionID).Delete(&models.RefreshToken{})
+	if result.Error != nil {
+		return 0, errors.New("failed to revoke sessions")
+	}
+	return result.RowsAffected, nil
+}
+
+// RevokeAllSessions signs the user out of every device
+func (s *SessionService) RevokeAllSessions(userID uint) (int64, error) {
+	return s.RevokeOtherSessions(userID, 0)
+}
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// carrierTrackingURLs builds tracking links for well-known carriers when staff do not give one
+var carrierTrackingURLs = map[string]string{
+	"ups":   "https://www.ups.com/track?tracknum=%s",
+	"fedex": "https://www.fedex.com/fedextrack/?trknbr=%s",
+	"usps":  "https://tools.usps.com/go/TrackConfirmAction?tLabels=%s",
+	"dhl":   "https://www.dhl.com/en/express/tracking.html?AWB=%s",
+}
+
+// shippableStatuses are the order statuses from which items can still be shipped; a
+// pending order has not been paid yet
+var shippableStatuses = []models.OrderStatus{
+	models.OrderStatusConfirmed,
+	models.OrderStatusPaid,
+	models.OrderStatusPartiallyShipped,
+}
+
+// ShipmentService records the parcels orders are sent in and moves orders along as
+// they ship and arrive
+type ShipmentService struct {
+	db            *gorm.DB
+	notifications *NotificationService
+}
+
+func NewShipmentService(db *gorm.DB, notifications *NotificationService) *ShipmentService {
+	return &ShipmentService{
+		db:            db,
+		notifications: notifications,
+	}
+}
+
+// CreateShipment records a shipment of some or all of the unshipped quantities of an
+// order. The order becomes partially shipped, or shipped once nothing is left.
+func (s *ShipmentService) CreateShipment(orderID uint, req *dto.CreateShipmentRequest, actorID uint) (*dto.ShipmentResponse, error) {
+	var order models.Order
+	shipment := models.Shipment{
+		OrderID:        orderID,
+		Carrier:        strings.TrimSpace(req.Carrier),
+		TrackingNumber: strings.TrimSpace(req.TrackingNumber),
+		TrackingURL:    req.TrackingURL,
+		Status:         models.ShipmentStatusShipped,
+		ShippedAt:      time.Now(),
+	}
+	if actorID != 0 {
+		shipment.CreatedByID = &actorID
+	}
+	if shipment.TrackingURL == "" {
+		shipment.TrackingURL = trackingURL(shipment.Carrier, shipment.TrackingNumber)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the order so concurrent shipments cannot ship the same units twice
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; err != nil {
+			return ErrOrderNotFound
+		}
+		if err := tx.Where("order_id = ?", order.ID).Order("id").Find(&order.OrderItems).Error; err != nil {
+			return err
+		}
+		if !orderShippable(order.Status) {
+			return ErrOrderNotShippable
+		}
+
+		shipped, err := shippedQuantities(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		if shipment.Items, err = shipmentItems(&order, shipped, req.Items); err != nil {
+			return err
+		}
+		if err := tx.Create(&shipment).Error; err != nil {
+			return errors.New("failed to create shipment")
+		}
+
+		for _, item := range shipment.Items {
+			shipped[item.OrderItemID] += item.Quantity
+		}
+		status := models.OrderStatusShipped
+		for _, item := range order.OrderItems {
+			if shipped[item.ID] < item.Quantity {
+				status = models.OrderStatusPartiallyShipped
+				break
+			}
+		}
+		order.Status = status
+		return tx.Model(&order).Update("status", status).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.getShipment(shipment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyShipped(&order, response)
+	return response, nil
+}
+
+// ListShipments returns the shipments of an order, oldest first
+func (s *ShipmentService) ListShipments(orderID uint) ([]dto.ShipmentResponse, error) {
+	var count int64
+	if err := s.db.Model(&models.Order{}).Where("id = ?", orderID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrOrderNotFound
+	}
+
+	var shipments []models.Shipment
+	if err := preloadShipment(s.db).Where("order_id = ?", orderID).
+		Order("shipped_at, id").Find(&shipments).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.ShipmentResponse, len(shipments))
+	for i := range shipments {
+		response[i] = convertToShipmentResponse(&shipments[i])
+	}
+	return response, nil
+}
+
+// UpdateShipment corrects the carrier and tracking details of a shipment
+func (s *ShipmentService) UpdateShipment(shipmentID uint, req *dto.UpdateShipmentRequest) (*dto.ShipmentResponse, error) {
+	var shipment models.Shipment
+	if err := s.db.First(&shipment, shipmentID).Error; err != nil {
+		return nil, ErrShipmentNotFound
+	}
+
+	shipment.Carrier = strings.TrimSpace(req.Carrier)
+	shipment.TrackingNumber = strings.TrimSpace(req.TrackingNumber)
+	shipment.TrackingURL = req.TrackingURL
+	if shipment.TrackingURL == "" {
+		shipment.TrackingURL = trackingURL(shipment.Carrier, shipment.TrackingNumber)
+	}
+
+	if err := s.db.Omit("Items").Save(&shipment).Error; err != nil {
+		return nil, errors.New("failed to update shipment")
+	}
+
+	return s.getShipment(shipment.ID)
+}
+
+// MarkDelivered records that a shipment arrived. The order becomes delivered once every
+// item has shipped and every shipment has arrived.
+func (s *ShipmentService) MarkDelivered(shipmentID uint) (*dto.ShipmentResponse, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var shipment models.Shipment
+		if err := tx.First(&shipment, shipmentID).Error; err != nil {
+			return ErrShipmentNotFound
+		}
+		if shipment.Status == models.ShipmentStatusDelivered {
+			return nil
+		}
+
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, shipment.OrderID).Error; err != nil {
+			return ErrOrderNotFound
+		}
+
+		now := time.Now()
+		if err := tx.Model(&shipment).Updates(map[string]interface{}{
+			"status":       models.ShipmentStatusDelivered,
+			"delivered_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		if order.Status != models.OrderStatusShipped {
+			return nil
+		}
+		var inTransit int64
+		if err := tx.Model(&models.Shipment{}).
+			Where("order_id = ? AND status <> ?", order.ID, models.ShipmentStatusDelivered).
+			Count(&inTransit).Error; err != nil {
+			return err
+		}
+		if inTransit > 0 {
+			return nil
+		}
+		return tx.Model(&order).Update("status", models.OrderStatusDelivered).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getShipment(shipmentID)
+}
+
+// GetTracking returns the shipments of a signed-in user's order
+func (s *ShipmentService) GetTracking(userID, orderID uint) (*dto.OrderTrackingResponse, error) {
+	return s.tracking(s.db.Where("id = ? AND user_id = ?", orderID, userID))
+}
+
+// LookupTracking lets guests track an order with its number and the email it was placed with
+func (s *ShipmentService) LookupTracking(req *dto.OrderLookupRequest) (*dto.OrderTrackingResponse, error) {
+	return s.tracking(s.db.Where("order_number = ? AND email = ?",
+		strings.ToUpper(strings.TrimSpace(req.OrderNumber)), normalizeEmail(req.Email)))
+}
+
+func (s *ShipmentService) tracking(query *gorm.DB) (*dto.OrderTrackingResponse, error) {
+	var order models.Order
+	if err := query.Preload("OrderItems.Product").Preload("Shipments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("shipped_at, id")
+	}).Preload("Shipments.Items.OrderItem.Product").First(&order).Error; err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	response := &dto.OrderTrackingResponse{
+		OrderNumber: order.OrderNumber,
+		Status:      string(order.Status),
+		Shipments:   make([]dto.ShipmentResponse, len(order.Shipments)),
+		Items:       make([]dto.TrackingItemResponse, len(order.OrderItems)),
+	}
+
+	shipped := make(map[uint]int)
+	for i := range order.Shipments {
+		response.Shipments[i] = convertToShipmentResponse(&order.Shipments[i])
+		for _, item := range order.Shipments[i].Items {
+			shipped[item.OrderItemID] += item.Quantity
+		}
+	}
+	for i, item := range order.OrderItems {
+		response.Items[i] = dto.TrackingItemResponse{
+			OrderItemID:     item.ID,
+			ProductID:       item.ProductID,
+			ProductName:     item.Product.Name,
+			Quantity:        item.Quantity,
+			ShippedQuantity: shipped[item.ID],
+		}
+	}
+	return response, nil
+}
+
+func (s *ShipmentService) getShipment(shipmentID uint) (*dto.ShipmentResponse, error) {
+	var shipment models.Shipment
+	if err := preloadShipment(s.db).First(&shipment, shipmentID).Error; err != nil {
+		return nil, ErrShipmentNotFound
+	}
+
+	response := convertToShipmentResponse(&shipment)
+	return &response, nil
+}
+
+func (s *ShipmentService) notifyShipped(order *models.Order, shipment *dto.ShipmentResponse) {
+	data := orderShippedEmail{
+		Name:           recipientName(s.db, order),
+		OrderNumber:    order.OrderNumber,
+		Partial:        order.Status == models.OrderStatusPartiallyShipped,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		TrackingURL:    shipment.TrackingURL,
+	}
+	for _, item := range shipment.Items {
+		data.Items = append(data.Items, notificationItem{Name: item.ProductName, Quantity: item.Quantity})
+	}
+
+	_ = s.notifications.Notify(models.NotificationOrderShipped, order.UserID, order.Email, data)
+}
+
+func preloadShipment(query *gorm.DB) *gorm.DB {
+	return query.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Items.OrderItem.Product")
+}
+
+// shippedQuantities sums what has shipped of each item of an order
+func shippedQuantities(db *gorm.DB, orderID uint) (map[uint]int, error) {
+	var rows []struct {
+		OrderItemID uint
+		Quantity    int
+	}
+	if err := db.Model(&models.ShipmentItem{}).
+		Select("shipment_items.order_item_id, SUM(shipment_items.quantity) AS quantity").
+		Joins("JOIN shipments ON shipments.id = shipment_items.shipment_id").
+		Where("shipments.order_id = ?", orderID).
+		Group("shipment_items.order_item_id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	shipped := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		shipped[row.OrderItemID] = row.Quantity
+	}
+	return shipped, nil
+}
+
+// shipmentItems checks the requested items against what is left to ship. Without
+// requested items everything left is shipped.
+func shipmentItems(order *models.Order, shipped map[uint]int, requests []dto.ShipmentItemRequest) ([]models.ShipmentItem, error) {
+	remaining := make(map[uint]int, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		remaining[item.ID] = item.Quantity - shipped[item.ID]
+	}
+
+	var items []models.ShipmentItem
+	if len(requests) == 0 {
+		for _, item := range order.OrderItems {
+			if remaining[item.ID] > 0 {
+				items = append(items, models.ShipmentItem{OrderItemID: item.ID, Quantity: remaining[item.ID]})
+			}
+		}
+		if len(items) == 0 {
+			return nil, ErrNothingToShip
+		}
+		return items, nil
+	}
+
+	for _, req := range requests {
+		left, ok := remaining[req.OrderItemID]
+		if !ok {
+			return nil, fmt.Errorf("%w: item %d is not part of the order", ErrInvalidShipmentItem, req.OrderItemID)
+		}
+		if req.Quantity > left {
+			return nil, fmt.Errorf("%w: only %d of item %d left to ship", ErrInvalidShipmentItem, left, req.OrderItemID)
+		}
+		remaining[req.OrderItemID] -= req.Quantity
+		items = append(items, models.ShipmentItem{OrderItemID: req.OrderItemID, Quantity: req.Quantity})
+	}
+	return items, nil
+}
+
+func orderShippable(status models.OrderStatus) bool {
+	for _, shippable := range shippableStatuses {
+		if status == shippable {
+			return true
+		}
+	}
+	return false
+}
+
+func trackingURL(carrier, trackingNumber string) string {
+	format, ok := carrierTrackingURLs[strings.ToLower(carrier)]
+	if !ok || trackingNumber == "" {
+		return ""
+	}
+	return fmt.Sprintf(format, url.QueryEscape(trackingNumber))
+}
+
+func convertToShipmentResponse(shipment *models.Shipment) dto.ShipmentResponse {
+	items := make([]dto.ShipmentItemResponse, len(shipment.Items))
+	for i := range shipment.Items {
+		items[i] = dto.ShipmentItemResponse{
+			OrderItemID: shipment.Items[i].OrderItemID,
+			ProductID:   shipment.Items[i].OrderItem.ProductID,
+			ProductName: shipment.Items[i].OrderItem.Product.Name,
+			Quantity:    shipment.Items[i].Quantity,
+		}
+	}
+
+	return dto.ShipmentResponse{
+		ID:             shipment.ID,
+		OrderID:        shipment.OrderID,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		TrackingURL:    shipment.TrackingURL,
+		Status:         string(shipment.Status),
+		ShippedAt:      shipment.ShippedAt,
+		DeliveredAt:    shipment.DeliveredAt,
+		Items:          items,
+	}
+}
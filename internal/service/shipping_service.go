@@ -0,0 +1,434 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"gorm.io/gorm"
+)
+
+// shippingParcel is what shipping rates are worked out from
+type shippingParcel struct {
+	Subtotal float64 // after discounts
+	Weight   float64 // chargeable weight in kg
+}
+
+// shippingQuote is an available shipping method and what it charges
+type shippingQuote struct {
+	Zone   *models.ShippingZone
+	Method *models.ShippingMethod
+	Cost   float64
+}
+
+// ShippingService lets staff manage shipping zones and methods, and quotes shipping for carts
+type ShippingService struct {
+	db          *gorm.DB
+	cartService *CartService
+}
+
+func NewShippingService(db *gorm.DB, cartService *CartService) *ShippingService {
+	return &ShippingService{
+		db:          db,
+		cartService: cartService,
+	}
+}
+
+// ListZones returns every shipping zone with its locations and methods
+func (s *ShippingService) ListZones() ([]dto.ShippingZoneResponse, error) {
+	var zones []models.ShippingZone
+	if err := s.preloadZone(s.db).Order("name, id").Find(&zones).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.ShippingZoneResponse, len(zones))
+	for i := range zones {
+		response[i] = convertToShippingZoneResponse(&zones[i])
+	}
+	return response, nil
+}
+
+func (s *ShippingService) GetZone(zoneID uint) (*dto.ShippingZoneResponse, error) {
+	var zone models.ShippingZone
+	if err := s.preloadZone(s.db).First(&zone, zoneID).Error; err != nil {
+		return nil, ErrShippingZoneNotFound
+	}
+
+	response := convertToShippingZoneResponse(&zone)
+	return &response, nil
+}
+
+func (s *ShippingService) CreateZone(req *dto.ShippingZoneRequest) (*dto.ShippingZoneResponse, error) {
+	zone := models.ShippingZone{Name: strings.TrimSpace(req.Name)}
+	var err error
+	if zone.Locations, err = zoneLocations(req.Locations); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(&zone).Error; err != nil {
+		return nil, errors.New("failed to create shipping zone")
+	}
+
+	return s.GetZone(zone.ID)
+}
+
+// UpdateZone renames a zone and replaces its locations. Its methods are kept.
+func (s *ShippingService) UpdateZone(zoneID uint, req *dto.ShippingZoneRequest) (*dto.ShippingZoneResponse, error) {
+	var zone models.ShippingZone
+	if err := s.db.First(&zone, zoneID).Error; err != nil {
+		return nil, ErrShippingZoneNotFound
+	}
+
+	locations, err := zoneLocations(req.Locations)
+	if err != nil {
+		return nil, err
+	}
+	zone.Name = strings.TrimSpace(req.Name)
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Locations", "Methods").Save(&zone).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("zone_id = ?", zone.ID).Delete(&models.ShippingZoneLocation{}).Error; err != nil {
+			return err
+		}
+		for i := range locations {
+			locations[i].ZoneID = zone.ID
+		}
+		return tx.Create(&locations).Error
+	})
+	if err != nil {
+		return nil, errors.New("failed to update shipping zone")
+	}
+
+	return s.GetZone(zone.ID)
+}
+
+// DeleteZone removes a zone together with its methods. Orders keep the method name and
+// cost they were placed with.
+func (s *ShippingService) DeleteZone(zoneID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.ShippingZone{}, zoneID)
+		if result.Error != nil {
+			return errors.New("failed to delete shipping zone")
+		}
+		if result.RowsAffected == 0 {
+			return ErrShippingZoneNotFound
+		}
+
+		return tx.Where("zone_id = ?", zoneID).Delete(&models.ShippingMethod{}).Error
+	})
+}
+
+func (s *ShippingService) CreateMethod(req *dto.ShippingMethodRequest) (*dto.ShippingMethodResponse, error) {
+	method := models.ShippingMethod{IsActive: true}
+	if err := s.applyMethodRequest(&method, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(&method).Error; err != nil {
+		return nil, errors.New("failed to create shipping method")
+	}
+
+	response := convertToShippingMethodResponse(&method)
+	return &response, nil
+}
+
+func (s *ShippingService) UpdateMethod(methodID uint, req *dto.ShippingMethodRequest) (*dto.ShippingMethodResponse, error) {
+	var method models.ShippingMethod
+	if err := s.db.First(&method, methodID).Error; err != nil {
+		return nil, ErrShippingMethodNotFound
+	}
+
+	if err := s.applyMethodRequest(&method, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Save(&method).Error; err != nil {
+		return nil, errors.New("failed to update shipping method")
+	}
+
+	response := convertToShippingMethodResponse(&method)
+	return &response, nil
+}
+
+func (s *ShippingService) DeleteMethod(methodID uint) error {
+	result := s.db.Delete(&models.ShippingMethod{}, methodID)
+	if result.Error != nil {
+		return errors.New("failed to delete shipping method")
+	}
+	if result.RowsAffected == 0 {
+		return ErrShippingMethodNotFound
+	}
+	return nil
+}
+
+// GetShippingRates quotes the shipping methods available for the owner's cart, priced
+// after its discounts, to the requested address
+func (s *ShippingService) GetShippingRates(owner CartOwner, req *dto.ShippingRatesRequest) ([]dto.ShippingRateResponse, error) {
+	cart, err := s.cartService.findCart(owner)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return nil, ErrEmptyCart
+	}
+
+	countryCode, region, err := s.quoteDestination(owner, req)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err = s.cartService.loadCart(cart.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.CartItems) == 0 {
+		return nil, ErrEmptyCart
+	}
+	priced, err := s.cartService.convertToCartResponse(cart)
+	if err != nil {
+		return nil, err
+	}
+
+	parcel := shippingParcel{Subtotal: roundMoney(priced.Subtotal - priced.Discount)}
+	for i := range cart.CartItems {
+		parcel.Weight += cart.CartItems[i].Product.ShippingWeight() * float64(cart.CartItems[i].Quantity)
+	}
+
+	quotes, err := quoteShipping(s.db, countryCode, region, parcel)
+	if err != nil {
+		return nil, err
+	}
+
+	waived := priced.Coupon != nil && priced.Coupon.Error == "" &&
+		priced.Coupon.Type == string(models.CouponTypeFreeShipping)
+	response := make([]dto.ShippingRateResponse, len(quotes))
+	for i, quote := range quotes {
+		response[i] = dto.ShippingRateResponse{
+			MethodID: quote.Method.ID,
+			Name:     quote.Method.Name,
+			Type:     string(quote.Method.Type),
+			Zone:     quote.Zone.Name,
+			Cost:     quote.Cost,
+		}
+		if waived && quote.Cost > 0 {
+			response[i].Cost = 0
+			response[i].Waived = true
+		}
+	}
+	return response, nil
+}
+
+// quoteDestination picks where to quote shipping to: a saved address of the user, the
+// given country, or the user's default shipping address
+func (s *ShippingService) quoteDestination(owner CartOwner, req *dto.ShippingRatesRequest) (string, string, error) {
+	if req.AddressID != 0 {
+		if owner.UserID == 0 {
+			return "", "", ErrAddressNotFound
+		}
+		var address models.Address
+		if err := s.db.Where("id = ? AND user_id = ?", req.AddressID, owner.UserID).First(&address).Error; err != nil {
+			return "", "", ErrAddressNotFound
+		}
+		return address.CountryCode, address.Region, nil
+	}
+
+	if req.CountryCode != "" {
+		return req.CountryCode, req.Region, nil
+	}
+
+	if owner.UserID != 0 {
+		var address models.Address
+		if err := s.db.Where("user_id = ? AND is_default_shipping = ?", owner.UserID, true).
+			First(&address).Error; err == nil {
+			return address.CountryCode, address.Region, nil
+		}
+	}
+	return "", "", ErrShippingDestinationRequired
+}
+
+func (s *ShippingService) preloadZone(query *gorm.DB) *gorm.DB {
+	return query.Preload("Locations", func(db *gorm.DB) *gorm.DB {
+		return db.Order("country_code, region")
+	}).Preload("Methods", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	})
+}
+
+func (s *ShippingService) applyMethodRequest(method *models.ShippingMethod, req *dto.ShippingMethodRequest) error {
+	var count int64
+	if err := s.db.Model(&models.ShippingZone{}).Where("id = ?", req.ZoneID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrShippingZoneNotFound
+	}
+
+	methodType := models.ShippingMethodType(req.Type)
+	if methodType == models.ShippingMethodFreeOverTotal && req.FreeThreshold == nil {
+		return errors.New("free_threshold is required for free_over_total methods")
+	}
+
+	method.ZoneID = req.ZoneID
+	method.Name = strings.TrimSpace(req.Name)
+	method.Type = methodType
+	method.Rate = req.Rate
+	method.RatePerKg = 0
+	if methodType == models.ShippingMethodWeightBased {
+		method.RatePerKg = req.RatePerKg
+	}
+	method.FreeThreshold = nil
+	if methodType == models.ShippingMethodFreeOverTotal {
+		method.FreeThreshold = req.FreeThreshold
+	}
+	method.MaxWeight = req.MaxWeight
+	if req.IsActive != nil {
+		method.IsActive = *req.IsActive
+	}
+	return nil
+}
+
+// zoneLocations checks the locations of a zone request for duplicates
+func zoneLocations(requests []dto.ShippingZoneLocationRequest) ([]models.ShippingZoneLocation, error) {
+	seen := make(map[string]bool, len(requests))
+	locations := make([]models.ShippingZoneLocation, len(requests))
+	for i, req := range requests {
+		locations[i] = models.ShippingZoneLocation{
+			CountryCode: strings.ToUpper(req.CountryCode),
+			Region:      strings.TrimSpace(req.Region),
+		}
+
+		key := locations[i].CountryCode + "/" + strings.ToLower(locations[i].Region)
+		if seen[key] {
+			return nil, errors.New("locations contains a location more than once")
+		}
+		seen[key] = true
+	}
+	return locations, nil
+}
+
+// quoteShipping finds the zone an address belongs to and prices its active methods.
+// A zone naming the address's region wins over one covering the whole country; among
+// equals the oldest zone wins. Methods the parcel is too heavy for are left out.
+func quoteShipping(db *gorm.DB, countryCode, region string, parcel shippingParcel) ([]shippingQuote, error) {
+	var location models.ShippingZoneLocation
+	err := db.Joins("JOIN shipping_zones ON shipping_zones.id = shipping_zone_locations.zone_id AND shipping_zones.deleted_at IS NULL").
+		Where("shipping_zone_locations.country_code = ?", strings.ToUpper(strings.TrimSpace(countryCode))).
+		Where("shipping_zone_locations.region = '' OR LOWER(shipping_zone_locations.region) = LOWER(?)", strings.TrimSpace(region)).
+		Order("shipping_zone_locations.region DESC, shipping_zone_locations.zone_id").
+		First(&location).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var zone models.ShippingZone
+	if err := db.Preload("Methods", func(db *gorm.DB) *gorm.DB {
+		return db.Where("is_active = ?", true).Order("rate, id")
+	}).First(&zone, location.ZoneID).Error; err != nil {
+		return nil, err
+	}
+
+	var quotes []shippingQuote
+	for i := range zone.Methods {
+		if cost, ok := shippingCost(&zone.Methods[i], parcel); ok {
+			quotes = append(quotes, shippingQuote{Zone: &zone, Method: &zone.Methods[i], Cost: cost})
+		}
+	}
+	return quotes, nil
+}
+
+// shippingCost prices a method for a parcel, or reports that the parcel cannot use it
+func shippingCost(method *models.ShippingMethod, parcel shippingParcel) (float64, bool) {
+	if method.MaxWeight != nil && parcel.Weight > *method.MaxWeight {
+		return 0, false
+	}
+
+	switch method.Type {
+	case models.ShippingMethodWeightBased:
+		return roundMoney(method.Rate + method.RatePerKg*parcel.Weight), true
+	case models.ShippingMethodFreeOverTotal:
+		if method.FreeThreshold != nil && parcel.Subtotal >= *method.FreeThreshold {
+			return 0, true
+		}
+	}
+	return method.Rate, true
+}
+
+// applyShipping charges the chosen shipping method on an order being placed. Stores
+// that have not set up any shipping zone take orders without a shipping charge.
+func applyShipping(db *gorm.DB, order *models.Order, parcel shippingParcel, methodID uint) error {
+	var zones int64
+	if err := db.Model(&models.ShippingZone{}).Count(&zones).Error; err != nil {
+		return err
+	}
+	if zones == 0 {
+		return nil
+	}
+
+	quotes, err := quoteShipping(db, order.ShippingAddress.CountryCode, order.ShippingAddress.Region, parcel)
+	if err != nil {
+		return err
+	}
+	if len(quotes) == 0 {
+		return ErrShippingUnavailable
+	}
+	if methodID == 0 {
+		return ErrShippingMethodRequired
+	}
+
+	for _, quote := range quotes {
+		if quote.Method.ID == methodID {
+			order.ShippingMethodID = &quote.Method.ID
+			order.ShippingMethodName = quote.Method.Name
+			order.ShippingCost = quote.Cost
+			order.TotalAmount = roundMoney(order.TotalAmount + quote.Cost)
+			return nil
+		}
+	}
+	return ErrShippingUnavailable
+}
+
+func convertToShippingZoneResponse(zone *models.ShippingZone) dto.ShippingZoneResponse {
+	locations := make([]dto.ShippingZoneLocationResponse, len(zone.Locations))
+	for i := range zone.Locations {
+		locations[i] = dto.ShippingZoneLocationResponse{
+			CountryCode: zone.Locations[i].CountryCode,
+			Region:      zone.Locations[i].Region,
+		}
+	}
+
+	methods := make([]dto.ShippingMethodResponse, len(zone.Methods))
+	for i := range zone.Methods {
+		methods[i] = convertToShippingMethodResponse(&zone.Methods[i])
+	}
+
+	return dto.ShippingZoneResponse{
+		ID:        zone.ID,
+		Name:      zone.Name,
+		Locations: locations,
+		Methods:   methods,
+		CreatedAt: zone.CreatedAt,
+		UpdatedAt: zone.UpdatedAt,
+	}
+}
+
+func convertToShippingMethodResponse(method *models.ShippingMethod) dto.ShippingMethodResponse {
+	return dto.ShippingMethodResponse{
+		ID:            method.ID,
+		ZoneID:        method.ZoneID,
+		Name:          method.Name,
+		Type:          string(method.Type),
+		Rate:          method.Rate,
+		RatePerKg:     method.RatePerKg,
+		FreeThreshold: method.FreeThreshold,
+		MaxWeight:     method.MaxWeight,
+		IsActive:      method.IsActive,
+		CreatedAt:     method.CreatedAt,
+		UpdatedAt:     method.UpdatedAt,
+	}
+}
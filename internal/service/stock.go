@@ -0,0 +1,17 @@
+package service
+
+import (
+	"github.com/programmerjide/ecommerce/internal/models"
+	"gorm.io/gorm"
+)
+
+// adjustStock changes a product's stock by the movement's quantity and records the
+// movement in the stock ledger. Deleted products are adjusted too, so the ledger and
+// the stock level never disagree.
+func adjustStock(tx *gorm.DB, movement *models.StockMovement) error {
+	if err := tx.Unscoped().Model(&models.Product{}).Where("id = ?", movement.ProductID).
+		Update("stock", gorm.Expr("stock + ?", movement.Quantity)).Error; err != nil {
+		return err
+	}
+	return tx.Create(movement).Error
+}
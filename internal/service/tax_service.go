@@ -0,0 +1,327 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/programmerjide/ecommerce/internal/config"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"gorm.io/gorm"
+)
+
+// TaxLocation is where goods are taxed, normally the shipping address
+type TaxLocation struct {
+	CountryCode string
+	Region      string
+}
+
+// TaxableLine is an amount to tax, already net of discounts
+type TaxableLine struct {
+	TaxClass string
+	Amount   float64
+}
+
+// TaxLine is the tax charged at one rate
+type TaxLine struct {
+	Name        string
+	CountryCode string
+	Region      string
+	Rate        float64
+	Amount      float64
+}
+
+// TaxResult holds the tax of every line, in the order the lines were given
+type TaxResult struct {
+	Lines      [][]TaxLine
+	LineTotals []float64
+	Total      float64
+	// Inclusive means the line amounts already contain the tax
+	Inclusive bool
+}
+
+// TaxCalculator works out the tax due on lines shipped to a location
+type TaxCalculator interface {
+	Calculate(location TaxLocation, lines []TaxableLine) (*TaxResult, error)
+}
+
+// TableTaxCalculator charges the rates kept in the tax_rates table. Rates of the whole
+// country and of the location's region all apply to a line, so a federal plus state
+// system is configured as two rates and a VAT country as one.
+type TableTaxCalculator struct {
+	db     *gorm.DB
+	config *config.TaxConfig
+}
+
+func NewTableTaxCalculator(db *gorm.DB, cfg *config.TaxConfig) *TableTaxCalculator {
+	return &TableTaxCalculator{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// Calculate taxes each line at the rates of its tax class. Without a country the
+// configured default is used; without either nothing is taxed.
+func (c *TableTaxCalculator) Calculate(location TaxLocation, lines []TaxableLine) (*TaxResult, error) {
+	country := strings.ToUpper(strings.TrimSpace(location.CountryCode))
+	region := strings.TrimSpace(location.Region)
+	if country == "" {
+		country, region = c.config.DefaultCountry, c.config.DefaultRegion
+	}
+	if country == "" || len(lines) == 0 {
+		return applyTaxRates(nil, c.config.PricesIncludeTax, lines), nil
+	}
+
+	var rates []models.TaxRate
+	if err := c.db.Where("country_code = ? AND (region = '' OR LOWER(region) = LOWER(?))", country, region).
+		Order("region, id").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+	return applyTaxRates(rates, c.config.PricesIncludeTax, lines), nil
+}
+
+// applyTaxRates taxes each line at the given rates of its tax class. Tax-inclusive
+// amounts are split into the net amount and the tax it contains.
+func applyTaxRates(rates []models.TaxRate, inclusive bool, lines []TaxableLine) *TaxResult {
+	result := &TaxResult{
+		Lines:      make([][]TaxLine, len(lines)),
+		LineTotals: make([]float64, len(lines)),
+		Inclusive:  inclusive,
+	}
+
+	ratesByClass := make(map[string][]models.TaxRate)
+	for _, rate := range rates {
+		ratesByClass[rate.TaxClass] = append(ratesByClass[rate.TaxClass], rate)
+	}
+
+	for i, line := range lines {
+		class := line.TaxClass
+		if class == "" {
+			class = models.TaxClassStandard
+		}
+		classRates := ratesByClass[class]
+		if len(classRates) == 0 || line.Amount <= 0 {
+			continue
+		}
+
+		net := line.Amount
+		if result.Inclusive {
+			var combined float64
+			for _, rate := range classRates {
+				combined += rate.Rate
+			}
+			net = line.Amount / (1 + combined/100)
+		}
+
+		for _, rate := range classRates {
+			amount := roundMoney(net * rate.Rate / 100)
+			result.Lines[i] = append(result.Lines[i], TaxLine{
+				Name:        rate.Name,
+				CountryCode: rate.CountryCode,
+				Region:      rate.Region,
+				Rate:        rate.Rate,
+				Amount:      amount,
+			})
+			result.LineTotals[i] += amount
+		}
+		result.LineTotals[i] = roundMoney(result.LineTotals[i])
+		result.Total += result.LineTotals[i]
+	}
+	result.Total = roundMoney(result.Total)
+	return result
+}
+
+// summarizeTaxLines adds up the tax charged at each rate across lines
+func summarizeTaxLines(lines [][]TaxLine) []dto.TaxLineResponse {
+	type rateKey struct {
+		Name, CountryCode, Region string
+		Rate                      float64
+	}
+	totals := make(map[rateKey]float64)
+	var keys []rateKey
+	for _, taxLines := range lines {
+		for _, line := range taxLines {
+			key := rateKey{line.Name, line.CountryCode, line.Region, line.Rate}
+			if _, ok := totals[key]; !ok {
+				keys = append(keys, key)
+			}
+			totals[key] += line.Amount
+		}
+	}
+
+	// Country-wide rates come before regional ones
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].Region == "" && keys[j].Region != ""
+	})
+
+	summary := make([]dto.TaxLineResponse, len(keys))
+	for i, key := range keys {
+		summary[i] = dto.TaxLineResponse{
+			Name:        key.Name,
+			CountryCode: key.CountryCode,
+			Region:      key.Region,
+			Rate:        key.Rate,
+			Amount:      roundMoney(totals[key]),
+		}
+	}
+	return summary
+}
+
+// orderTaxLines reads back the tax lines charged on an order
+func orderTaxLines(order *models.Order) []TaxLine {
+	lines := make([]TaxLine, len(order.TaxLines))
+	for i, line := range order.TaxLines {
+		lines[i] = TaxLine{
+			Name:        line.Name,
+			CountryCode: line.CountryCode,
+			Region:      line.Region,
+			Rate:        line.Rate,
+			Amount:      line.Amount,
+		}
+	}
+	return lines
+}
+
+// TaxService lets staff manage the tax rate table
+type TaxService struct {
+	db *gorm.DB
+}
+
+func NewTaxService(db *gorm.DB) *TaxService {
+	return &TaxService{
+		db: db,
+	}
+}
+
+// ListTaxRates returns tax rates grouped by country and region
+func (s *TaxService) ListTaxRates(req *dto.ListTaxRatesRequest) ([]dto.TaxRateResponse, *utils.PaginationMeta, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	query := s.db.Model(&models.TaxRate{})
+	if req.CountryCode != "" {
+		query = query.Where("country_code = ?", strings.ToUpper(req.CountryCode))
+	}
+	if req.TaxClass != "" {
+		query = query.Where("tax_class = ?", req.TaxClass)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var rates []models.TaxRate
+	if err := query.Order("country_code, region, tax_class, id").
+		Offset(offset).Limit(req.Limit).Find(&rates).Error; err != nil {
+		return nil, nil, err
+	}
+
+	response := make([]dto.TaxRateResponse, len(rates))
+	for i := range rates {
+		response[i] = convertToTaxRateResponse(&rates[i])
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	meta := &utils.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      int(total),
+		TotalPages: totalPages,
+	}
+
+	return response, meta, nil
+}
+
+func (s *TaxService) CreateTaxRate(req *dto.TaxRateRequest) (*dto.TaxRateResponse, error) {
+	var rate models.TaxRate
+	applyTaxRateRequest(&rate, req)
+	if err := s.checkRateAvailable(&rate); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(&rate).Error; err != nil {
+		return nil, errors.New("failed to create tax rate")
+	}
+
+	response := convertToTaxRateResponse(&rate)
+	return &response, nil
+}
+
+// UpdateTaxRate replaces a tax rate. Placed orders keep the tax lines they were charged.
+func (s *TaxService) UpdateTaxRate(rateID uint, req *dto.TaxRateRequest) (*dto.TaxRateResponse, error) {
+	var rate models.TaxRate
+	if err := s.db.First(&rate, rateID).Error; err != nil {
+		return nil, ErrTaxRateNotFound
+	}
+
+	applyTaxRateRequest(&rate, req)
+	if err := s.checkRateAvailable(&rate); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Save(&rate).Error; err != nil {
+		return nil, errors.New("failed to update tax rate")
+	}
+
+	response := convertToTaxRateResponse(&rate)
+	return &response, nil
+}
+
+func (s *TaxService) DeleteTaxRate(rateID uint) error {
+	result := s.db.Delete(&models.TaxRate{}, rateID)
+	if result.Error != nil {
+		return errors.New("failed to delete tax rate")
+	}
+	if result.RowsAffected == 0 {
+		return ErrTaxRateNotFound
+	}
+	return nil
+}
+
+func (s *TaxService) checkRateAvailable(rate *models.TaxRate) error {
+	var count int64
+	if err := s.db.Model(&models.TaxRate{}).
+		Where("country_code = ? AND LOWER(region) = LOWER(?) AND tax_class = ? AND name = ? AND id <> ?",
+			rate.CountryCode, rate.Region, rate.TaxClass, rate.Name, rate.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("a tax rate with this name already exists for the location and tax class")
+	}
+	return nil
+}
+
+func applyTaxRateRequest(rate *models.TaxRate, req *dto.TaxRateRequest) {
+	rate.CountryCode = strings.ToUpper(req.CountryCode)
+	rate.Region = strings.TrimSpace(req.Region)
+	rate.TaxClass = req.TaxClass
+	if rate.TaxClass == "" {
+		rate.TaxClass = models.TaxClassStandard
+	}
+	rate.Name = strings.TrimSpace(req.Name)
+	rate.Rate = req.Rate
+}
+
+func convertToTaxRateResponse(rate *models.TaxRate) dto.TaxRateResponse {
+	return dto.TaxRateResponse{
+		ID:          rate.ID,
+		CountryCode: rate.CountryCode,
+		Region:      rate.Region,
+		TaxClass:    rate.TaxClass,
+		Name:        rate.Name,
+		Rate:        rate.Rate,
+		CreatedAt:   rate.CreatedAt,
+		UpdatedAt:   rate.UpdatedAt,
+	}
+}
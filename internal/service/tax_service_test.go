@@ -0,0 +1,134 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/programmerjide/ecommerce/internal/models"
+)
+
+func TestApplyTaxRates(t *testing.T) {
+	vat := []models.TaxRate{
+		{CountryCode: "GB", TaxClass: models.TaxClassStandard, Name: "VAT", Rate: 20},
+		{CountryCode: "GB", TaxClass: "reduced", Name: "VAT", Rate: 5},
+	}
+	// Country plus region rates, as a federal and a provincial tax
+	stacked := []models.TaxRate{
+		{CountryCode: "CA", TaxClass: models.TaxClassStandard, Name: "GST", Rate: 5},
+		{CountryCode: "CA", Region: "ON", TaxClass: models.TaxClassStandard, Name: "PST", Rate: 8},
+	}
+
+	tests := []struct {
+		name       string
+		rates      []models.TaxRate
+		inclusive  bool
+		lines      []TaxableLine
+		lineTaxes  [][]float64
+		lineTotals []float64
+		total      float64
+	}{
+		{
+			name:       "tax added on top of the price",
+			rates:      vat,
+			lines:      []TaxableLine{{TaxClass: models.TaxClassStandard, Amount: 100}},
+			lineTaxes:  [][]float64{{20}},
+			lineTotals: []float64{20},
+			total:      20,
+		},
+		{
+			name:       "tax contained in the price",
+			rates:      vat,
+			inclusive:  true,
+			lines:      []TaxableLine{{TaxClass: models.TaxClassStandard, Amount: 120}},
+			lineTaxes:  [][]float64{{20}},
+			lineTotals: []float64{20},
+			total:      20,
+		},
+		{
+			name:       "country and region rates stack",
+			rates:      stacked,
+			lines:      []TaxableLine{{TaxClass: models.TaxClassStandard, Amount: 100}},
+			lineTaxes:  [][]float64{{5, 8}},
+			lineTotals: []float64{13},
+			total:      13,
+		},
+		{
+			name:       "stacked rates are taken out of inclusive prices together",
+			rates:      stacked,
+			inclusive:  true,
+			lines:      []TaxableLine{{TaxClass: models.TaxClassStandard, Amount: 113}},
+			lineTaxes:  [][]float64{{5, 8}},
+			lineTotals: []float64{13},
+			total:      13,
+		},
+		{
+			name:  "each line is taxed at the rates of its class",
+			rates: vat,
+			lines: []TaxableLine{
+				{TaxClass: "", Amount: 50},
+				{TaxClass: "reduced", Amount: 50},
+				{TaxClass: "zero", Amount: 50},
+			},
+			lineTaxes:  [][]float64{{10}, {2.5}, nil},
+			lineTotals: []float64{10, 2.5, 0},
+			total:      12.5,
+		},
+		{
+			name:       "lines discounted to nothing are not taxed",
+			rates:      vat,
+			lines:      []TaxableLine{{TaxClass: models.TaxClassStandard, Amount: 0}},
+			lineTaxes:  [][]float64{nil},
+			lineTotals: []float64{0},
+			total:      0,
+		},
+		{
+			name:  "tax is rounded per line",
+			rates: vat,
+			lines: []TaxableLine{
+				{TaxClass: models.TaxClassStandard, Amount: 0.99},
+				{TaxClass: models.TaxClassStandard, Amount: 0.99},
+				{TaxClass: models.TaxClassStandard, Amount: 0.99},
+			},
+			lineTaxes:  [][]float64{{0.2}, {0.2}, {0.2}},
+			lineTotals: []float64{0.2, 0.2, 0.2},
+			total:      0.6,
+		},
+		{
+			name:       "no rates",
+			lines:      []TaxableLine{{TaxClass: models.TaxClassStandard, Amount: 100}},
+			lineTaxes:  [][]float64{nil},
+			lineTotals: []float64{0},
+			total:      0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := applyTaxRates(tt.rates, tt.inclusive, tt.lines)
+
+			if result.Inclusive != tt.inclusive {
+				t.Errorf("Inclusive = %v, want %v", result.Inclusive, tt.inclusive)
+			}
+			for i, want := range tt.lineTaxes {
+				if len(result.Lines[i]) != len(want) {
+					t.Fatalf("line %d has %d tax lines, want %d", i, len(result.Lines[i]), len(want))
+				}
+				for j, amount := range want {
+					if !moneyEqual(result.Lines[i][j].Amount, amount) {
+						t.Errorf("line %d tax %d = %v, want %v", i, j, result.Lines[i][j].Amount, amount)
+					}
+				}
+				if !moneyEqual(result.LineTotals[i], tt.lineTotals[i]) {
+					t.Errorf("line %d total = %v, want %v", i, result.LineTotals[i], tt.lineTotals[i])
+				}
+			}
+			if !moneyEqual(result.Total, tt.total) {
+				t.Errorf("Total = %v, want %v", result.Total, tt.total)
+			}
+		})
+	}
+}
+
+func moneyEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
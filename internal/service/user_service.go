@@ -1,18 +1,33 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/config"
 	"github.com/programmerjide/ecommerce/internal/dto"
 	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/utils"
 	"gorm.io/gorm"
 )
 
 type UserService struct {
-	db *gorm.DB
+	db             *gorm.DB
+	config         *config.Config
+	notifications  *NotificationService
+	passwordPolicy *PasswordPolicy
+	loginGuard     *LoginGuard
 }
 
-func NewUserService(db *gorm.DB) *UserService {
+func NewUserService(db *gorm.DB, cfg *config.Config, notifications *NotificationService, passwordPolicy *PasswordPolicy) *UserService {
 	return &UserService{
-		db: db,
+		db:             db,
+		config:         cfg,
+		notifications:  notifications,
+		passwordPolicy: passwordPolicy,
+		loginGuard:     NewLoginGuard(db, &cfg.Login),
 	}
 }
 
@@ -51,3 +66,346 @@ func (s *UserService) UpdateProfile(userID uint, req *dto.UpdateProfileRequest)
 
 	return s.GetProfile(userID) // Return the updated profile
 }
+
+// ListUsers returns a filtered page of accounts for staff
+func (s *UserService) ListUsers(req *dto.ListUsersRequest) ([]dto.AdminUserResponse, *utils.PaginationMeta, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	query := s.db.Model(&models.User{})
+	switch req.Deleted {
+	case "only":
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	case "include":
+		query = query.Unscoped()
+	}
+
+	if req.Role != "" {
+		query = query.Where("role = ?", req.Role)
+	}
+
+	if req.IsActive != nil {
+		query = query.Where("is_active = ?", *req.IsActive)
+	}
+
+	if req.Email != "" {
+		query = query.Where("email ILIKE ?", "%"+escapeLike(req.Email)+"%")
+	}
+
+	if req.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *req.CreatedFrom)
+	}
+
+	if req.CreatedTo != nil {
+		// Dates are inclusive, so include the whole final day
+		query = query.Where("created_at < ?", req.CreatedTo.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var users []models.User
+	if err := query.Order("created_at DESC").Offset(offset).Limit(req.Limit).Find(&users).Error; err != nil {
+		return nil, nil, err
+	}
+
+	response := make([]dto.AdminUserResponse, len(users))
+	for i := range users {
+		response[i] = convertToAdminUserResponse(&users[i])
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	meta := &utils.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      int(total),
+		TotalPages: totalPages,
+	}
+
+	return response, meta, nil
+}
+
+// GetUser returns any account, including soft-deleted ones
+func (s *UserService) GetUser(userID uint) (*dto.AdminUserResponse, error) {
+	var user models.User
+	if err := s.db.Unscoped().First(&user, userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	response := convertToAdminUserResponse(&user)
+	return &response, nil
+}
+
+// SetUserActive deactivates or reactivates an account. Deactivation signs the user out everywhere.
+func (s *UserService) SetUserActive(actorID, userID uint, active bool) (*dto.AdminUserResponse, error) {
+	if actorID == userID && !active {
+		return nil, errors.New("you cannot deactivate your own account")
+	}
+
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("is_active", active).Error; err != nil {
+			return err
+		}
+		if active {
+			return nil
+		}
+		return tx.Where("user_id = ? OR impersonator_id = ?", user.ID, user.ID).Delete(&models.RefreshToken{}).Error
+	})
+	if err != nil {
+		return nil, errors.New("failed to update account status")
+	}
+
+	return s.GetUser(userID)
+}
+
+// DeleteUser soft-deletes an account and signs it out everywhere
+func (s *UserService) DeleteUser(actorID, userID uint) error {
+	if actorID == userID {
+		return errors.New("you cannot delete your own account")
+	}
+
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return ErrUserNotFound
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? OR impersonator_id = ?", user.ID, user.ID).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&user).Error
+	})
+}
+
+// RestoreUser brings back a soft-deleted account by clearing DeletedAt
+func (s *UserService) RestoreUser(userID uint) (*dto.AdminUserResponse, error) {
+	result := s.db.Unscoped().Model(&models.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", userID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return nil, errors.New("failed to restore user")
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	return s.GetUser(userID)
+}
+
+// ChangePassword replaces the password after re-checking the current one and
+// signs out every session except the one making the request
+func (s *UserService) ChangePassword(userID, currentSessionID uint, req *dto.ChangePasswordRequest, client dto.ClientInfo) error {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return ErrUserNotFound
+	}
+
+	if err := s.verifyCurrentPassword(&user, req.CurrentPassword, client); err != nil {
+		return err
+	}
+
+	if err := s.passwordPolicy.Validate(req.NewPassword, PasswordContext{
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+	}); err != nil {
+		return err
+	}
+
+	hashPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password", hashPassword).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND id <> ?", user.ID, currentSessionID).Delete(&models.RefreshToken{}).Error
+	})
+	if err != nil {
+		return errors.New("failed to change password")
+	}
+
+	s.notify(models.NotificationPasswordChanged, &user, user.Email, "")
+	return nil
+}
+
+// RequestEmailChange sends a confirmation link to the new address; the email
+// is only switched once that link is used
+func (s *UserService) RequestEmailChange(userID uint, req *dto.ChangeEmailRequest, client dto.ClientInfo) error {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return ErrUserNotFound
+	}
+
+	if err := s.verifyCurrentPassword(&user, req.CurrentPassword, client); err != nil {
+		return err
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return errors.New("new email must be different from the current email")
+	}
+	if s.emailInUse(s.db, newEmail) {
+		return ErrDuplicateEmail
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return errors.New("failed to generate confirmation token")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Only the most recent request can be confirmed
+		if err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.ID, models.VerificationPurposeEmailChange).
+			Delete(&models.VerificationToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.VerificationToken{
+			UserID:    &user.ID,
+			Purpose:   models.VerificationPurposeEmailChange,
+			TokenHash: utils.HashToken(token),
+			Payload:   newEmail,
+			ExpiresAt: time.Now().Add(s.config.Password.EmailChangeExpires),
+		}).Error
+	})
+	if err != nil {
+		return errors.New("failed to save confirmation token")
+	}
+
+	if err := s.notifications.Notify(models.NotificationConfirmEmail, &user.ID, newEmail, confirmEmailEmail{
+		Name:           user.FirstName,
+		ConfirmURL:     fmt.Sprintf("%s/confirm-email?token=%s", s.config.Server.PublicURL, token),
+		ExpiresInHours: int(s.config.Password.EmailChangeExpires.Hours()),
+	}); err != nil {
+		return errors.New("failed to send confirmation email")
+	}
+
+	s.notify(models.NotificationEmailChangeRequested, &user, user.Email, newEmail)
+	return nil
+}
+
+// verifyCurrentPassword re-checks the password of a signed-in user with the same
+// throttling as logins, so a stolen access token cannot be used to guess it
+func (s *UserService) verifyCurrentPassword(user *models.User, password string, client dto.ClientInfo) error {
+	if err := s.loginGuard.Check(user.Email, client.IPAddress); err != nil {
+		var lockedErr *LockedError
+		if errors.As(err, &lockedErr) {
+			return err
+		}
+		return errors.New("failed to check login attempts")
+	}
+
+	if err := utils.VerifyPassword(user.Password, password); err != nil {
+		if _, err := s.loginGuard.RecordFailure(user.Email, client.IPAddress); err != nil {
+			return errors.New("failed to record failed attempt")
+		}
+		return ErrInvalidCredentials
+	}
+
+	if err := s.loginGuard.RecordSuccess(user.Email); err != nil {
+		return errors.New("failed to reset login attempts")
+	}
+	return nil
+}
+
+// ConfirmEmailChange switches the email once the new address has been verified
+func (s *UserService) ConfirmEmailChange(req *dto.ConfirmEmailChangeRequest) (*dto.UserResponse, error) {
+	var changeToken models.VerificationToken
+	if err := s.db.Preload("User").
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?",
+			utils.HashToken(req.Token), models.VerificationPurposeEmailChange, time.Now()).
+		First(&changeToken).Error; err != nil {
+		return nil, ErrInvalidEmailToken
+	}
+
+	user := changeToken.User
+	oldEmail := user.Email
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// The address may have been registered by someone else since the request
+		if s.emailInUse(tx, changeToken.Payload) {
+			return ErrDuplicateEmail
+		}
+		// Claim the token first so that concurrent requests cannot both use it
+		result := tx.Model(&models.VerificationToken{}).
+			Where("id = ? AND used_at IS NULL", changeToken.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidEmailToken
+		}
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"email":             changeToken.Payload,
+			"email_verified_at": time.Now(),
+		}).Error
+	})
+	if errors.Is(err, ErrDuplicateEmail) || errors.Is(err, ErrInvalidEmailToken) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to change email")
+	}
+
+	s.notify(models.NotificationEmailChanged, &user, oldEmail, changeToken.Payload)
+
+	return s.GetProfile(user.ID)
+}
+
+func (s *UserService) emailInUse(db *gorm.DB, email string) bool {
+	var count int64
+	db.Unscoped().Model(&models.User{}).Where("LOWER(email) = LOWER(?)", email).Count(&count)
+	return count > 0
+}
+
+func convertToAdminUserResponse(user *models.User) dto.AdminUserResponse {
+	response := dto.AdminUserResponse{
+		UserResponse: dto.UserResponse{
+			ID:        user.ID,
+			Email:     user.Email,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Phone:     user.Phone,
+			Role:      string(user.Role),
+			IsActive:  user.IsActive,
+		},
+		IsServiceAccount: user.IsServiceAccount,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+	}
+	if user.DeletedAt.Valid {
+		response.DeletedAt = &user.DeletedAt.Time
+	}
+	return response
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// notify sends a security notification; delivery failures do not undo the change
+func (s *UserService) notify(notificationType models.NotificationType, user *models.User, to, newEmail string) {
+	_ = s.notifications.Notify(notificationType, &user.ID, to, accountChangeEmail{
+		Name:     user.FirstName,
+		NewEmail: newEmail,
+	})
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/programmerjide/ecommerce/internal/config"
+	"github.com/programmerjide/ecommerce/internal/dto"
+	"github.com/programmerjide/ecommerce/internal/models"
+	"github.com/programmerjide/ecommerce/internal/utils"
+	"gorm.io/gorm"
+)
+
+type WishlistService struct {
+	db             *gorm.DB
+	config         *config.Config
+	cartService    *CartService
+	productService *ProductService
+}
+
+func NewWishlistService(db *gorm.DB, cfg *config.Config, cartService *CartService, productService *ProductService) *WishlistService {
+	return &WishlistService{
+		db:             db,
+		config:         cfg,
+		cartService:    cartService,
+		productService: productService,
+	}
+}
+
+// ListWishlists returns every wishlist of a user with its products
+func (s *WishlistService) ListWishlists(userID uint) ([]dto.WishlistResponse, error) {
+	var wishlists []models.Wishlist
+	if err := s.preloadItems(s.db).Where("user_id = ?", userID).Order("created_at").Find(&wishlists).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.WishlistResponse, len(wishlists))
+	for i := range wishlists {
+		response[i] = s.convertToWishlistResponse(&wishlists[i])
+	}
+	return response, nil
+}
+
+func (s *WishlistService) GetWishlist(userID, wishlistID uint) (*dto.WishlistResponse, error) {
+	wishlist, err := s.loadWishlist(userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := s.convertToWishlistResponse(wishlist)
+	return &response, nil
+}
+
+func (s *WishlistService) CreateWishlist(userID uint, req *dto.WishlistRequest) (*dto.WishlistResponse, error) {
+	wishlist := models.Wishlist{
+		UserID: userID,
+		Name:   strings.TrimSpace(req.Name),
+	}
+	if err := s.db.Create(&wishlist).Error; err != nil {
+		return nil, errors.New("failed to create wishlist")
+	}
+
+	response := s.convertToWishlistResponse(&wishlist)
+	return &response, nil
+}
+
+func (s *WishlistService) RenameWishlist(userID, wishlistID uint, req *dto.WishlistRequest) (*dto.WishlistResponse, error) {
+	result := s.db.Model(&models.Wishlist{}).
+		Where("id = ? AND user_id = ?", wishlistID, userID).
+		Update("name", strings.TrimSpace(req.Name))
+	if result.Error != nil {
+		return nil, errors.New("failed to rename wishlist")
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrWishlistNotFound
+	}
+
+	return s.GetWishlist(userID, wishlistID)
+}
+
+// DeleteWishlist removes a wishlist, which also takes down its share link
+func (s *WishlistService) DeleteWishlist(userID, wishlistID uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", wishlistID, userID).Delete(&models.Wishlist{})
+	if result.Error != nil {
+		return errors.New("failed to delete wishlist")
+	}
+	if result.RowsAffected == 0 {
+		return ErrWishlistNotFound
+	}
+	return nil
+}
+
+// AddItem saves a product on a wishlist. Saving a product that is already there is a no-op.
+func (s *WishlistService) AddItem(userID, wishlistID uint, req *dto.AddWishlistItemRequest) (*dto.WishlistResponse, error) {
+	if _, err := s.loadWishlist(userID, wishlistID); err != nil {
+		return nil, err
+	}
+
+	var product models.Product
+	if err := s.db.First(&product, req.ProductID).Error; err != nil || !product.IsActive {
+		return nil, ErrProductUnavailable
+	}
+
+	item := models.WishlistItem{WishlistID: wishlistID, ProductID: product.ID}
+	if err := s.db.Where("wishlist_id = ? AND product_id = ?", wishlistID, product.ID).
+		FirstOrCreate(&item).Error; err != nil {
+		return nil, errors.New("failed to add item to wishlist")
+	}
+
+	return s.GetWishlist(userID, wishlistID)
+}
+
+func (s *WishlistService) RemoveItem(userID, wishlistID, itemID uint) (*dto.WishlistResponse, error) {
+	if _, err := s.loadWishlist(userID, wishlistID); err != nil {
+		return nil, err
+	}
+
+	result := s.db.Where("id = ? AND wishlist_id = ?", itemID, wishlistID).Delete(&models.WishlistItem{})
+	if result.Error != nil {
+		return nil, errors.New("failed to remove wishlist item")
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrWishlistItemNotFound
+	}
+
+	return s.GetWishlist(userID, wishlistID)
+}
+
+// MoveToCart adds a wishlist item to the user's cart and takes it off the wishlist
+func (s *WishlistService) MoveToCart(userID, wishlistID, itemID uint, req *dto.MoveToCartRequest) (*dto.CartResponse, error) {
+	if _, err := s.loadWishlist(userID, wishlistID); err != nil {
+		return nil, err
+	}
+
+	var item models.WishlistItem
+	if err := s.db.Where("id = ? AND wishlist_id = ?", itemID, wishlistID).First(&item).Error; err != nil {
+		return nil, ErrWishlistItemNotFound
+	}
+
+	quantity := req.Quantity
+	if quantity < 1 {
+		quantity = 1
+	}
+
+	cart, err := s.cartService.AddToCart(CartOwner{UserID: userID}, &dto.AddToCartRequest{
+		ProductID: item.ProductID,
+		Quantity:  quantity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Delete(&item).Error; err != nil {
+		return nil, errors.New("failed to remove wishlist item")
+	}
+	return cart, nil
+}
+
+// ShareWishlist publishes a wishlist under an unguessable link. Sharing again keeps the link.
+func (s *WishlistService) ShareWishlist(userID, wishlistID uint) (*dto.WishlistResponse, error) {
+	wishlist, err := s.loadWishlist(userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if wishlist.ShareSlug == nil {
+		slug, err := utils.GenerateRandomToken(16)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.db.Model(wishlist).Update("share_slug", slug).Error; err != nil {
+			return nil, errors.New("failed to share wishlist")
+		}
+		wishlist.ShareSlug = &slug
+	}
+
+	response := s.convertToWishlistResponse(wishlist)
+	return &response, nil
+}
+
+// UnshareWishlist makes a wishlist private again. A later share gets a new link.
+func (s *WishlistService) UnshareWishlist(userID, wishlistID uint) (*dto.WishlistResponse, error) {
+	result := s.db.Model(&models.Wishlist{}).
+		Where("id = ? AND user_id = ?", wishlistID, userID).
+		Update("share_slug", nil)
+	if result.Error != nil {
+		return nil, errors.New("failed to unshare wishlist")
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrWishlistNotFound
+	}
+
+	return s.GetWishlist(userID, wishlistID)
+}
+
+// GetSharedWishlist returns the public view of a shared wishlist. Products that are no
+// longer sold are left out.
+func (s *WishlistService) GetSharedWishlist(slug string) (*dto.SharedWishlistResponse, error) {
+	var wishlist models.Wishlist
+	if err := s.preloadItems(s.db).Preload("User").
+		Where("share_slug = ?", slug).First(&wishlist).Error; err != nil {
+		return nil, ErrWishlistNotFound
+	}
+	// The owner may have been deleted since the list was shared
+	if wishlist.User.ID == 0 || !wishlist.User.IsActive {
+		return nil, ErrWishlistNotFound
+	}
+
+	items := make([]dto.WishlistItemResponse, 0, len(wishlist.Items))
+	for i := range wishlist.Items {
+		if wishlist.Items[i].Product.ID == 0 || !wishlist.Items[i].Product.IsActive {
+			continue
+		}
+		items = append(items, s.convertToWishlistItemResponse(&wishlist.Items[i]))
+	}
+
+	return &dto.SharedWishlistResponse{
+		Name:      wishlist.Name,
+		OwnerName: wishlist.User.FirstName,
+		Items:     items,
+		UpdatedAt: wishlist.UpdatedAt,
+	}, nil
+}
+
+func (s *WishlistService) loadWishlist(userID, wishlistID uint) (*models.Wishlist, error) {
+	var wishlist models.Wishlist
+	if err := s.preloadItems(s.db).
+		Where("id = ? AND user_id = ?", wishlistID, userID).First(&wishlist).Error; err != nil {
+		return nil, ErrWishlistNotFound
+	}
+	return &wishlist, nil
+}
+
+func (s *WishlistService) preloadItems(query *gorm.DB) *gorm.DB {
+	return query.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at DESC")
+	}).Preload("Items.Product.Category").Preload("Items.Product.Images")
+}
+
+func (s *WishlistService) convertToWishlistResponse(wishlist *models.Wishlist) dto.WishlistResponse {
+	response := dto.WishlistResponse{
+		ID:        wishlist.ID,
+		Name:      wishlist.Name,
+		Shared:    wishlist.ShareSlug != nil,
+		Items:     make([]dto.WishlistItemResponse, len(wishlist.Items)),
+		CreatedAt: wishlist.CreatedAt,
+		UpdatedAt: wishlist.UpdatedAt,
+	}
+	if wishlist.ShareSlug != nil {
+		response.ShareURL = fmt.Sprintf("%s/wishlists/%s", s.config.Server.PublicURL, *wishlist.ShareSlug)
+	}
+
+	for i := range wishlist.Items {
+		response.Items[i] = s.convertToWishlistItemResponse(&wishlist.Items[i])
+	}
+	return response
+}
+
+func (s *WishlistService) convertToWishlistItemResponse(item *models.WishlistItem) dto.WishlistItemResponse {
+	return dto.WishlistItemResponse{
+		ID:      item.ID,
+		Product: s.productService.convertToProductResponse(&item.Product),
+		InStock: item.Product.IsActive && item.Product.Stock > 0,
+		AddedAt: item.CreatedAt,
+	}
+}
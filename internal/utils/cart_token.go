@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// cartTokenAudience keeps cart tokens from being accepted as access tokens and vice versa
+const cartTokenAudience = "cart"
+
+// CartTokenClaims identifies the anonymous cart a guest is shopping with
+type CartTokenClaims struct {
+	CartID uint `json:"cid"`
+	jwt.RegisteredClaims
+}
+
+// GenerateCartToken signs a token that gives its holder access to a guest cart
+func GenerateCartToken(keys *KeySet, cartID uint, expiresIn time.Duration) (string, error) {
+	return keys.Sign(&CartTokenClaims{
+		CartID: cartID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{cartTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	})
+}
+
+// ValidateCartToken checks a cart token and returns the cart it belongs to
+func ValidateCartToken(tokenString string, keys *KeySet) (uint, error) {
+	claims := &CartTokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, keys.keyFunc,
+		jwt.WithAudience(cartTokenAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if !token.Valid || claims.CartID == 0 {
+		return 0, errors.New("invalid cart token")
+	}
+	return claims.CartID, nil
+}
@@ -7,62 +7,93 @@ import (
 	"time"
 )
 
+// Token audiences keep refresh tokens from being accepted as access tokens and vice versa
+const (
+	accessTokenAudience  = "access"
+	refreshTokenAudience = "refresh"
+)
+
 // JWTClaims represents the JWT claims
 type JWTClaims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID         uint     `json:"user_id"`
+	Email          string   `json:"email"`
+	Role           string   `json:"role"`
+	Permissions    []string `json:"perms,omitempty"`
+	SessionID      uint     `json:"sid,omitempty"`
+	ImpersonatorID uint     `json:"impersonator_id,omitempty"` // staff member acting as UserID
 	jwt.RegisteredClaims
 }
 
-// GenerateJWTToken generates access and refresh JWT tokens
-func GenerateJWTToken(cfg *config.JWTConfig, userID uint, email string, role string) (accessToken, refreshToken string, err error) {
+// TokenSubject describes who a token pair is issued for
+type TokenSubject struct {
+	SessionID   uint
+	UserID      uint
+	Email       string
+	Role        string
+	Permissions []string
+	// ImpersonatorID is set for tokens issued to staff acting as the user
+	ImpersonatorID uint
+}
+
+// GenerateJWTToken generates access and refresh JWT tokens signed with the active key.
+// Both tokens carry the session they belong to so a revoked session stops working.
+func GenerateJWTToken(keys *KeySet, cfg *config.JWTConfig, subject *TokenSubject) (accessToken, refreshToken string, err error) {
 	// Create access token
-	accessClaims := &JWTClaims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.ExpiresIn)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+	accessClaims := subject.claims(accessTokenAudience, cfg.ExpiresIn)
+	accessToken, err = keys.Sign(accessClaims)
+	if err != nil {
+		return "", "", err // Return empty strings on error
 	}
 
-	at := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessToken, err = at.SignedString([]byte(cfg.Secret))
+	// Create refresh token
+	refreshClaims := subject.claims(refreshTokenAudience, cfg.RefreshTokenExpires)
+	refreshToken, err = keys.Sign(refreshClaims)
 	if err != nil {
 		return "", "", err // Return empty strings on error
 	}
 
-	// Create refresh token
-	refreshClaims := &JWTClaims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+	return accessToken, refreshToken, nil
+}
+
+// GenerateImpersonationToken generates a short-lived access token that carries both the
+// impersonated user and the staff member acting as them. No refresh token is issued.
+func GenerateImpersonationToken(keys *KeySet, subject *TokenSubject, expiresIn time.Duration) (string, error) {
+	return keys.Sign(subject.claims(accessTokenAudience, expiresIn))
+}
+
+func (s *TokenSubject) claims(audience string, expiresIn time.Duration) *JWTClaims {
+	return &JWTClaims{
+		UserID:         s.UserID,
+		Email:          s.Email,
+		Role:           s.Role,
+		Permissions:    s.Permissions,
+		SessionID:      s.SessionID,
+		ImpersonatorID: s.ImpersonatorID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.RefreshTokenExpires)),
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
+}
 
-	rt := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshToken, err = rt.SignedString([]byte(cfg.Secret))
-	if err != nil {
-		return "", "", err // Return empty strings on error
-	}
+// ValidateAccessToken validates an access token against the key set and returns the
+// claims if valid
+func ValidateAccessToken(tokenString string, keys *KeySet) (*JWTClaims, error) {
+	return validateToken(tokenString, keys, accessTokenAudience)
+}
 
-	return accessToken, refreshToken, nil
+// ValidateRefreshToken validates a refresh token against the key set and returns the
+// claims if valid
+func ValidateRefreshToken(tokenString string, keys *KeySet) (*JWTClaims, error) {
+	return validateToken(tokenString, keys, refreshTokenAudience)
 }
 
-// ValidateToken validates a JWT token and returns the claims if valid
-func ValidateToken(tokenString, secret string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method to prevent algorithm confusion attacks
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(secret), nil
-	})
+func validateToken(tokenString string, keys *KeySet, audience string) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, keys.keyFunc,
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/programmerjide/ecommerce/internal/config"
+)
+
+func TestTokensAreOnlyAcceptedForTheirUse(t *testing.T) {
+	cfg := &config.JWTConfig{
+		Secret:              "test-secret",
+		ExpiresIn:           time.Minute,
+		RefreshTokenExpires: time.Hour,
+		Algorithm:           "HS256",
+	}
+	keys, err := LoadKeySet(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accessToken, refreshToken, err := GenerateJWTToken(keys, cfg, &TokenSubject{SessionID: 1, UserID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cartToken, err := GenerateCartToken(keys, 3, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		validate func(string, *KeySet) (*JWTClaims, error)
+		token    string
+		valid    bool
+	}{
+		{"access token as access token", ValidateAccessToken, accessToken, true},
+		{"refresh token as refresh token", ValidateRefreshToken, refreshToken, true},
+		{"refresh token as access token", ValidateAccessToken, refreshToken, false},
+		{"access token as refresh token", ValidateRefreshToken, accessToken, false},
+		{"cart token as access token", ValidateAccessToken, cartToken, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.validate(tt.token, keys)
+			if tt.valid && (err != nil || claims.UserID != 2) {
+				t.Fatalf("expected token to be accepted, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatal("expected token to be rejected")
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/programmerjide/ecommerce/internal/config"
+)
+
+// KeySet holds the key used to sign new tokens and every key that is still
+// accepted when verifying tokens, indexed by their kid header
+type KeySet struct {
+	signingMethod jwt.SigningMethod
+	signingKID    string
+	signingKey    interface{}
+	verifyKeys    map[string]verificationKey
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    interface{}
+}
+
+// JWK represents a single public key in a JSON Web Key Set
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS represents the document served from /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// LoadKeySet builds the key set described by the JWT configuration.
+// HS256 keeps using the shared secret; RS256 and EdDSA load PEM key pairs.
+func LoadKeySet(cfg *config.JWTConfig) (*KeySet, error) {
+	keys := &KeySet{
+		signingKID: cfg.SigningKeyID,
+		verifyKeys: make(map[string]verificationKey),
+	}
+
+	switch strings.ToUpper(cfg.Algorithm) {
+	case "", "HS256":
+		keys.signingMethod = jwt.SigningMethodHS256
+		keys.signingKey = []byte(cfg.Secret)
+		keys.verifyKeys[cfg.SigningKeyID] = verificationKey{method: jwt.SigningMethodHS256, key: keys.signingKey}
+		return keys, nil
+	case "RS256", "EDDSA":
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.Algorithm)
+	}
+
+	if cfg.SigningKeyID == "" {
+		return nil, errors.New("JWT_SIGNING_KEY_ID is required for asymmetric signing")
+	}
+	if cfg.PrivateKeyPath == "" {
+		return nil, errors.New("JWT_PRIVATE_KEY_PATH is required for asymmetric signing")
+	}
+
+	privateKey, err := loadPrivateKey(cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	method, publicKey, err := publicKeyFor(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(method.Alg(), cfg.Algorithm) {
+		return nil, fmt.Errorf("private key does not match JWT algorithm %s", cfg.Algorithm)
+	}
+
+	keys.signingMethod = method
+	keys.signingKey = privateKey
+	keys.verifyKeys[cfg.SigningKeyID] = verificationKey{method: method, key: publicKey}
+
+	// Previous keys stay valid for verification until every token they signed has expired
+	for _, entry := range strings.Split(cfg.VerificationKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, path, found := strings.Cut(entry, "=")
+		if !found || kid == "" || path == "" {
+			return nil, fmt.Errorf("invalid verification key entry %q, expected kid=path", entry)
+		}
+		if _, exists := keys.verifyKeys[kid]; exists {
+			return nil, fmt.Errorf("duplicate verification key id %q", kid)
+		}
+
+		verifyMethod, verifyKey, err := loadPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		keys.verifyKeys[kid] = verificationKey{method: verifyMethod, key: verifyKey}
+	}
+
+	return keys, nil
+}
+
+// Sign signs the claims with the active signing key and stamps its kid header
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signingMethod, claims)
+	if k.signingKID != "" {
+		token.Header["kid"] = k.signingKID
+	}
+	return token.SignedString(k.signingKey)
+}
+
+// keyFunc resolves the verification key for a token from its kid header
+func (k *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	vk, ok := k.verifyKeys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+
+	// Verify signing method to prevent algorithm confusion attacks
+	if token.Method.Alg() != vk.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return vk.key, nil
+}
+
+// JWKS returns the public verification keys. Shared HMAC secrets are never published.
+func (k *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for kid, vk := range k.verifyKeys {
+		switch key := vk.key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Use: "sig",
+				Alg: vk.method.Alg(),
+				Kid: kid,
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Use: "sig",
+				Alg: vk.method.Alg(),
+				Kid: kid,
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(key),
+			})
+		}
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+func loadPrivateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported private key in %s", path)
+}
+
+func loadPublicKey(path string) (jwt.SigningMethod, interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read verification key: %w", err)
+	}
+
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return jwt.SigningMethodRS256, key, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return jwt.SigningMethodEdDSA, key, nil
+	}
+	return nil, nil, fmt.Errorf("unsupported verification key in %s", path)
+}
+
+func publicKeyFor(key crypto.Signer) (jwt.SigningMethod, interface{}, error) {
+	switch pub := key.Public().(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, pub, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, pub, nil
+	default:
+		return nil, nil, errors.New("unsupported private key type")
+	}
+}
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -58,6 +60,15 @@ func BadRequestResponse(c *gin.Context, message string, err error) {
 	ErrorResponse(c, http.StatusBadRequest, message, err)
 }
 
+// ValidationErrorResponse returns a bad request carrying structured validation details
+func ValidationErrorResponse(c *gin.Context, message string, details interface{}) {
+	c.JSON(http.StatusBadRequest, Response{
+		Success: false,
+		Message: message,
+		Error:   details,
+	})
+}
+
 func UnauthorizedResponse(c *gin.Context, message string) {
 	ErrorResponse(c, http.StatusUnauthorized, message, nil)
 }
@@ -70,6 +81,21 @@ func NotFoundResponse(c *gin.Context, message string) {
 	ErrorResponse(c, http.StatusNotFound, message, nil)
 }
 
+// TooManyRequestsResponse tells the client to retry after the given delay
+func TooManyRequestsResponse(c *gin.Context, message string, retryAfter time.Duration) {
+	seconds := int(retryAfter.Seconds() + 0.5)
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, Response{
+		Success: false,
+		Message: message,
+		Data:    gin.H{"retry_after": seconds},
+	})
+}
+
 func InternalServerErrorResponse(c *gin.Context, message string, err error) {
 	ErrorResponse(c, http.StatusInternalServerError, message, err)
 }